@@ -0,0 +1,416 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ikafly144/sabalauncher/v2/pkg/core"
+	"github.com/ikafly144/sabalauncher/v2/pkg/resource"
+)
+
+// resolveInstance finds an instance by UID, unique UID prefix or exact name.
+func resolveInstance(instances []*resource.Instance, ref string) (*resource.Instance, error) {
+	if id, err := uuid.Parse(ref); err == nil {
+		for _, inst := range instances {
+			if inst.UID == id {
+				return inst, nil
+			}
+		}
+		return nil, fmt.Errorf("instance not found: %s", ref)
+	}
+
+	var matches []*resource.Instance
+	for _, inst := range instances {
+		if inst.Name == ref {
+			return inst, nil
+		}
+		if strings.HasPrefix(inst.UID.String(), strings.ToLower(ref)) {
+			matches = append(matches, inst)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("instance not found: %s", ref)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("instance reference %q is ambiguous (%d matches)", ref, len(matches))
+	}
+}
+
+func lookupInstance(im core.InstanceManager, ref string) (*resource.Instance, error) {
+	instances, err := im.GetInstances()
+	if err != nil {
+		return nil, err
+	}
+	return resolveInstance(instances, ref)
+}
+
+func runList(args []string) error {
+	im, err := newInstanceManager()
+	if err != nil {
+		return err
+	}
+	instances, err := im.GetInstances()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "UID\tNAME\tVERSION\tSOURCE")
+	for _, inst := range instances {
+		version, source := "-", "local"
+		if inst.Upstream != nil {
+			if inst.Upstream.Version != "" {
+				version = inst.Upstream.Version
+			}
+			if inst.Upstream.ManifestURL != "" {
+				source = inst.Upstream.ManifestURL
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", inst.UID, inst.Name, version, source)
+	}
+	return w.Flush()
+}
+
+func runImport(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: sabactl import <file.sbpack|file.mrpack|file.zip>")
+	}
+	im, err := newInstanceManager()
+	if err != nil {
+		return err
+	}
+	stop := streamProgress(os.Stdout, im.SubscribeProgress())
+	err = im.ImportInstance(ctx, args[0])
+	stop()
+	var manualErr *resource.ManualDownloadError
+	if errors.As(err, &manualErr) {
+		// The instance exists, but is incomplete until the user fetches these files
+		fmt.Printf("Imported %s\n", args[0])
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Imported %s\n", args[0])
+	return nil
+}
+
+func runAddRemote(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: sabactl add-remote <manifest_url>")
+	}
+	im, err := newInstanceManager()
+	if err != nil {
+		return err
+	}
+	stop := streamProgress(os.Stdout, im.SubscribeProgress())
+	err = im.AddRemoteInstance(ctx, args[0])
+	stop()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Registered %s\n", args[0])
+	return nil
+}
+
+func runUpdate(ctx context.Context, args []string) error {
+	const usage = "usage: sabactl update <instance> [file.sbpatch|file.sbpack] [--to <version>]"
+	fs := flag.NewFlagSet("update", flag.ExitOnError)
+	to := fs.String("to", "", "update or roll back to this version of the remote repository")
+	var ref string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		ref, args = args[0], args[1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf(usage)
+	}
+	rest := fs.Args()
+	if ref == "" && len(rest) > 0 {
+		ref, rest = rest[0], rest[1:]
+	}
+	if ref == "" || len(rest) > 1 || (len(rest) == 1 && *to != "") {
+		return fmt.Errorf(usage)
+	}
+
+	im, err := newInstanceManager()
+	if err != nil {
+		return err
+	}
+	inst, err := lookupInstance(im, ref)
+	if err != nil {
+		return err
+	}
+
+	stop := streamProgress(os.Stdout, im.SubscribeProgress())
+	switch {
+	case *to != "":
+		err = im.UpdateInstanceTo(ctx, inst.UID, *to)
+	case len(rest) == 1:
+		err = im.UpdateInstance(ctx, inst.UID, rest[0])
+	default:
+		err = im.UpdateInstance(ctx, inst.UID, "")
+	}
+	stop()
+	if err != nil {
+		return err
+	}
+	im.Wait()
+	if inst.Upstream != nil {
+		fmt.Printf("Updated %s to %s\n", inst.Name, inst.Upstream.Version)
+		if inst.Upstream.Pinned && *to == "" && len(rest) == 0 {
+			fmt.Printf("%s is pinned; run 'sabactl unpin' to receive updates\n", inst.Name)
+		}
+	} else {
+		fmt.Printf("Updated %s\n", inst.Name)
+	}
+	return nil
+}
+
+func runVersions(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: sabactl versions <instance>")
+	}
+	im, err := newInstanceManager()
+	if err != nil {
+		return err
+	}
+	inst, err := lookupInstance(im, args[0])
+	if err != nil {
+		return err
+	}
+	versions, err := im.ListVersions(ctx, inst.UID)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "\tVERSION\tTYPE\tCHANNEL\tPUBLISHED")
+	for _, v := range versions {
+		mark := ""
+		if v.Installed {
+			mark = "*"
+			if inst.Upstream.Pinned {
+				mark = "*pinned"
+			}
+		}
+		published := "-"
+		if v.Timestamp > 0 {
+			published = time.Unix(v.Timestamp, 0).Format(time.DateTime)
+		}
+		channel := v.Channel
+		if channel == "" {
+			channel = resource.ChannelStable
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", mark, v.ID, v.Type, channel, published)
+	}
+	return w.Flush()
+}
+
+func runChannel(args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return fmt.Errorf("usage: sabactl channel <instance> [%s]", strings.Join(resource.Channels, "|"))
+	}
+	im, err := newInstanceManager()
+	if err != nil {
+		return err
+	}
+	inst, err := lookupInstance(im, args[0])
+	if err != nil {
+		return err
+	}
+	if inst.Upstream == nil || inst.Upstream.ManifestURL == "" {
+		return fmt.Errorf("%s is not a remote instance", inst.Name)
+	}
+	if len(args) == 1 {
+		fmt.Println(inst.Upstream.ChannelName())
+		return nil
+	}
+	if err := im.SetChannel(inst.UID, args[1]); err != nil {
+		return err
+	}
+	fmt.Printf("%s now follows the %s channel; run 'sabactl update' to switch to its latest version\n", inst.Name, inst.Upstream.ChannelName())
+	return nil
+}
+
+func runPin(args []string, pinned bool) error {
+	if len(args) != 1 {
+		if pinned {
+			return fmt.Errorf("usage: sabactl pin <instance>")
+		}
+		return fmt.Errorf("usage: sabactl unpin <instance>")
+	}
+	im, err := newInstanceManager()
+	if err != nil {
+		return err
+	}
+	inst, err := lookupInstance(im, args[0])
+	if err != nil {
+		return err
+	}
+	if err := im.SetPinned(inst.UID, pinned); err != nil {
+		return err
+	}
+	if pinned {
+		fmt.Printf("Pinned %s to %s\n", inst.Name, inst.Upstream.Version)
+	} else {
+		fmt.Printf("Unpinned %s\n", inst.Name)
+	}
+	return nil
+}
+
+func runCheckUpdate(ctx context.Context, args []string) error {
+	im, err := newInstanceManager()
+	if err != nil {
+		return err
+	}
+	instances, err := im.GetInstances()
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		inst, err := resolveInstance(instances, args[0])
+		if err != nil {
+			return err
+		}
+		instances = []*resource.Instance{inst}
+	}
+
+	failed := false
+	for _, inst := range instances {
+		if inst.Upstream == nil || inst.Upstream.ManifestURL == "" {
+			if len(args) > 0 {
+				fmt.Printf("%s: not a remote instance\n", inst.Name)
+			}
+			continue
+		}
+		available, err := im.CheckUpdate(ctx, inst.UID)
+		switch {
+		case inst.Upstream.Pinned:
+			fmt.Printf("%s: pinned to %s\n", inst.Name, inst.Upstream.Version)
+		case err != nil:
+			failed = true
+			fmt.Printf("%s: check failed: %v\n", inst.Name, err)
+		case available:
+			fmt.Printf("%s: update available\n", inst.Name)
+		default:
+			fmt.Printf("%s: up to date\n", inst.Name)
+		}
+	}
+	if failed {
+		return fmt.Errorf("some update checks failed")
+	}
+	return nil
+}
+
+func runRepair(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: sabactl repair <instance>")
+	}
+	im, err := newInstanceManager()
+	if err != nil {
+		return err
+	}
+	inst, err := lookupInstance(im, args[0])
+	if err != nil {
+		return err
+	}
+
+	stop := streamProgress(os.Stdout, im.SubscribeProgress())
+	err = im.RepairInstance(ctx, inst.UID)
+	stop()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Repaired %s\n", inst.Name)
+	return nil
+}
+
+func runVerify(ctx context.Context) error {
+	im, err := newInstanceManager()
+	if err != nil {
+		return err
+	}
+
+	stop := streamProgress(os.Stdout, im.SubscribeProgress())
+	report, err := im.VerifyGameFiles(ctx)
+	stop()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Checked %d files, repaired %d\n", report.Checked, len(report.Repaired))
+	for _, path := range report.Repaired {
+		fmt.Printf("  repaired %s\n", path)
+	}
+	if len(report.Unrepairable) > 0 {
+		for _, path := range report.Unrepairable {
+			fmt.Printf("  corrupt %s\n", path)
+		}
+		return fmt.Errorf("%d files could not be repaired; reinstall the mod loader of the affected instances", len(report.Unrepairable))
+	}
+	return nil
+}
+
+func runDelete(args []string) error {
+	fs := flag.NewFlagSet("delete", flag.ExitOnError)
+	yes := fs.Bool("yes", false, "do not ask for confirmation")
+	ref, err := parseInstanceArgs(fs, args)
+	if err != nil {
+		return fmt.Errorf("usage: sabactl delete <instance> [--yes]")
+	}
+
+	im, err := newInstanceManager()
+	if err != nil {
+		return err
+	}
+	inst, err := lookupInstance(im, ref)
+	if err != nil {
+		return err
+	}
+
+	if !*yes {
+		fmt.Printf("Delete %s (%s) and all of its files? [y/N]: ", inst.Name, inst.UID)
+		var answer string
+		_, _ = fmt.Scanln(&answer)
+		if !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+			fmt.Println("Aborted")
+			return nil
+		}
+	}
+
+	if err := im.DeleteInstance(inst.UID); err != nil {
+		return err
+	}
+	// DeleteInstance removes files in the background; let it finish before the process exits.
+	im.Wait()
+	fmt.Printf("Deleted %s\n", inst.Name)
+	return nil
+}
+
+// parseInstanceArgs parses a subcommand taking one instance reference followed or preceded by flags.
+func parseInstanceArgs(fs *flag.FlagSet, args []string) (string, error) {
+	var ref string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		ref, args = args[0], args[1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		return "", err
+	}
+	if ref == "" && fs.NArg() > 0 {
+		ref = fs.Arg(0)
+	} else if fs.NArg() > 0 {
+		return "", fmt.Errorf("unexpected argument: %s", fs.Arg(0))
+	}
+	if ref == "" {
+		return "", fmt.Errorf("missing instance")
+	}
+	return ref, nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ikafly144/sabalauncher/v2/pkg/core"
+	"github.com/ikafly144/sabalauncher/v2/pkg/resource"
+)
+
+func runLaunch(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("launch", flag.ExitOnError)
+	server := fs.String("server", "", "join a multiplayer server on startup (quick play)")
+	world := fs.String("world", "", "open a singleplayer world on startup (quick play)")
+	memory := fs.Uint64("memory", 0, "maximum memory in MB (overrides the launcher setting)")
+	offline := fs.Bool("offline", false, "launch with the cached profile and installed files only")
+	ref, err := parseInstanceArgs(fs, args)
+	if err != nil {
+		return fmt.Errorf("usage: sabactl launch <instance> [--server <address>] [--world <name>] [--memory <MB>] [--offline]")
+	}
+
+	auth, err := newAuthenticator()
+	if err != nil {
+		return err
+	}
+	// Offline launches skip signing in, which would go to the network
+	if !*offline {
+		if err := auth.TrySilentLogin(ctx); err != nil {
+			return fmt.Errorf("not logged in, run 'sabactl login' first: %w", err)
+		}
+	}
+
+	im, err := newInstanceManager()
+	if err != nil {
+		return err
+	}
+	inst, err := lookupInstance(im, ref)
+	if err != nil {
+		return err
+	}
+
+	user := auth.GetUserDisplay()
+	if *offline {
+		creds, err := auth.LaunchCredentials(inst.Account, true)
+		if errors.Is(err, core.ErrAccountNotFound) {
+			creds, err = auth.LaunchCredentials("", true)
+		}
+		if err != nil {
+			return fmt.Errorf("cannot launch offline: %w", err)
+		}
+		user = creds.Profile.Username
+	}
+
+	config, err := core.LoadConfig(resource.DataDir)
+	if err != nil {
+		return err
+	}
+	runner := core.NewGameRunner(auth, im, resource.DataDir, config)
+
+	stopProgress := streamProgress(os.Stdout, runner.SubscribeProgress())
+	defer stopProgress()
+	go func() {
+		for n := range runner.SubscribeNotifications() {
+			fmt.Printf("%s: %s\n", n.Title, n.Message)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		_ = runner.Stop()
+	}()
+
+	fmt.Printf("Launching %s as %s\n", inst.Name, user)
+	err = runner.Launch(inst.UID, &core.LaunchOptions{
+		QuickPlayMultiplayer:  *server,
+		QuickPlaySingleplayer: *world,
+		MemoryMB:              *memory,
+		Offline:               *offline,
+	})
+
+	if r, logErr := runner.GetLogReader(); logErr == nil {
+		if f, ok := r.(*os.File); ok {
+			fmt.Printf("Game log: %s\n", f.Name())
+		}
+		r.Close()
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Println("Game exited")
+	return nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ikafly144/sabalauncher/v2/pkg/msa"
+)
+
+func runLogin(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("login", flag.ExitOnError)
+	_ = fs.Bool("device-code", true, "sign in with the device code flow (default)")
+	browser := fs.Bool("browser", false, "sign in through a local browser instead of a device code")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	auth, err := newAuthenticator()
+	if err != nil {
+		return err
+	}
+
+	method := msa.LoginMethodDeviceCode
+	if *browser {
+		method = msa.LoginMethodBrowser
+	}
+	if err := auth.Login(ctx, method); err != nil {
+		return fmt.Errorf("failed to start login: %w", err)
+	}
+
+	if method == msa.LoginMethodDeviceCode {
+		url, code := auth.DeviceCode()
+		fmt.Printf("To sign in, open %s and enter the code %s\n", url, code)
+	} else {
+		fmt.Println("Complete the sign-in in your browser")
+	}
+
+	if err := auth.WaitLogin(ctx); err != nil {
+		if lastErr := auth.GetLastError(); lastErr != nil && lastErr != err {
+			return fmt.Errorf("%v: %w", lastErr, err)
+		}
+		return err
+	}
+	fmt.Printf("Logged in as %s\n", auth.GetUserDisplay())
+	return nil
+}
+
+func runLogout(args []string) error {
+	auth, err := newAuthenticator()
+	if err != nil {
+		return err
+	}
+	if err := auth.Logout(); err != nil {
+		return err
+	}
+	fmt.Println("Logged out")
+	return nil
+}
+
+// resolveAccount finds a signed-in account by ID or username.
+func resolveAccount(accounts []msa.StoredAccount, ref string) (msa.StoredAccount, error) {
+	for _, a := range accounts {
+		if a.ID == ref || strings.EqualFold(a.Username, ref) {
+			return a, nil
+		}
+	}
+	return msa.StoredAccount{}, fmt.Errorf("account not found: %s", ref)
+}
+
+func runAccounts(ctx context.Context, args []string) error {
+	auth, err := newAuthenticator()
+	if err != nil {
+		return err
+	}
+	accounts := auth.Accounts()
+
+	if len(args) == 0 || args[0] == "list" {
+		_ = auth.TrySilentLogin(ctx)
+		active := auth.ActiveAccountID()
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ACTIVE\tUSERNAME\tUUID\tID")
+		for _, a := range accounts {
+			mark := ""
+			if a.ID == active {
+				mark = "*"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", mark, a.Username, a.UUID, a.ID)
+		}
+		return w.Flush()
+	}
+
+	switch args[0] {
+	case "switch":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: sabactl accounts switch <account>")
+		}
+		account, err := resolveAccount(accounts, args[1])
+		if err != nil {
+			return err
+		}
+		if err := auth.SwitchAccount(ctx, account.ID); err != nil {
+			return err
+		}
+		fmt.Printf("Switched to %s\n", auth.GetUserDisplay())
+	case "remove":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: sabactl accounts remove <account>")
+		}
+		account, err := resolveAccount(accounts, args[1])
+		if err != nil {
+			return err
+		}
+		if err := auth.RemoveAccount(account.ID); err != nil {
+			return err
+		}
+		fmt.Printf("Removed %s\n", account.Username)
+	case "use":
+		if len(args) < 2 || len(args) > 3 {
+			return fmt.Errorf("usage: sabactl accounts use <instance> [account]")
+		}
+		im, err := newInstanceManager()
+		if err != nil {
+			return err
+		}
+		inst, err := lookupInstance(im, args[1])
+		if err != nil {
+			return err
+		}
+		inst.Account = ""
+		name := "the active account"
+		if len(args) == 3 {
+			account, err := resolveAccount(accounts, args[2])
+			if err != nil {
+				return err
+			}
+			inst.Account, name = account.ID, account.Username
+		}
+		if err := im.SaveInstance(inst); err != nil {
+			return err
+		}
+		fmt.Printf("%s will launch with %s\n", inst.Name, name)
+	default:
+		return fmt.Errorf("unknown accounts command: %s", args[0])
+	}
+	return nil
+}
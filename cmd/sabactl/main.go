@@ -0,0 +1,154 @@
+// Command sabactl is a headless front-end to the launcher core for scripting instance setup.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	"github.com/ikafly144/sabalauncher/v2/pkg/core"
+	"github.com/ikafly144/sabalauncher/v2/pkg/msa"
+	"github.com/ikafly144/sabalauncher/v2/pkg/redact"
+	"github.com/ikafly144/sabalauncher/v2/pkg/resource"
+	"github.com/ikafly144/sabalauncher/v2/secret"
+)
+
+func main() {
+	verbose := flag.Bool("v", false, "print launcher logs to stderr")
+	flag.Usage = printUsage
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	level := slog.LevelWarn
+	if *verbose {
+		level = slog.LevelDebug
+	}
+	slog.SetDefault(slog.New(redact.NewHandler(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))))
+
+	msa.ClientID = secret.GetSecret("MSA_CLIENT_ID")
+	resource.CurseForgeAPIKey = secret.GetSecret("CURSEFORGE_API_KEY")
+	redact.Register(resource.CurseForgeAPIKey)
+
+	if err := resource.CheckDataDir(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if config, err := core.LoadConfig(resource.DataDir); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to load config: %v\n", err)
+		os.Exit(1)
+	} else if err := config.ApplyNetwork(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	command := flag.Arg(0)
+	args := flag.Args()[1:]
+
+	var err error
+	switch command {
+	case "list":
+		err = runList(args)
+	case "import":
+		err = runImport(ctx, args)
+	case "add-remote":
+		err = runAddRemote(ctx, args)
+	case "update":
+		err = runUpdate(ctx, args)
+	case "versions":
+		err = runVersions(ctx, args)
+	case "channel":
+		err = runChannel(args)
+	case "pin":
+		err = runPin(args, true)
+	case "unpin":
+		err = runPin(args, false)
+	case "check-update":
+		err = runCheckUpdate(ctx, args)
+	case "repair":
+		err = runRepair(ctx, args)
+	case "verify":
+		err = runVerify(ctx)
+	case "delete":
+		err = runDelete(args)
+	case "launch":
+		err = runLaunch(ctx, args)
+	case "login":
+		err = runLogin(ctx, args)
+	case "logout":
+		err = runLogout(args)
+	case "accounts":
+		err = runAccounts(ctx, args)
+	default:
+		fmt.Printf("Unknown command: %s\n", command)
+		printUsage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	fmt.Println("Usage: sabactl [-v] <command> [arguments]")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  list")
+	fmt.Println("      List installed instances")
+	fmt.Println("  import <file.sbpack|file.mrpack|file.zip>")
+	fmt.Println("      Import an instance from an .sbpack, Modrinth .mrpack or CurseForge .zip file")
+	fmt.Println("  add-remote <manifest_url>")
+	fmt.Println("      Register an instance from a remote repository manifest")
+	fmt.Println("  update <instance> [file.sbpatch|file.sbpack] [--to <version>]")
+	fmt.Println("      Update an instance from its remote repository or from a local file")
+	fmt.Println("      (--to updates or rolls back to a version of the remote repository)")
+	fmt.Println("  versions <instance>")
+	fmt.Println("      List the versions of the remote repository of an instance")
+	fmt.Println("  channel <instance> [stable|beta|dev]")
+	fmt.Println("      Show or change the release channel a remote instance follows")
+	fmt.Println("  pin|unpin <instance>")
+	fmt.Println("      Keep a remote instance at its installed version, or let it update again")
+	fmt.Println("  check-update [instance]")
+	fmt.Println("      Check remote instances for updates (all instances if omitted)")
+	fmt.Println("  repair <instance>")
+	fmt.Println("      Verify instance files and re-download anything missing or corrupted")
+	fmt.Println("  verify")
+	fmt.Println("      Verify the assets, libraries, client jars and Java runtimes shared by all instances")
+	fmt.Println("  delete <instance>")
+	fmt.Println("      Delete an instance and its files")
+	fmt.Println("  launch <instance> [--server <address>] [--world <name>] [--memory <MB>] [--offline]")
+	fmt.Println("      Set up and launch the game, waiting until it exits")
+	fmt.Println("  login [--device-code|--browser]")
+	fmt.Println("      Sign in with a Microsoft account (device code flow by default)")
+	fmt.Println("  logout")
+	fmt.Println("      Sign out of the current Microsoft account")
+	fmt.Println("  accounts [list]")
+	fmt.Println("      List signed-in accounts")
+	fmt.Println("  accounts switch|remove <account>")
+	fmt.Println("      Make an account active or sign out of it")
+	fmt.Println("  accounts use <instance> [account]")
+	fmt.Println("      Launch an instance with an account (the active account if omitted)")
+	fmt.Println()
+	fmt.Println("<instance> is an instance UID, a unique UID prefix or an instance name.")
+	fmt.Println("<account> is an account username or ID.")
+}
+
+func newInstanceManager() (core.InstanceManager, error) {
+	return core.NewInstanceManager(resource.DataDir)
+}
+
+func newAuthenticator() (core.Authenticator, error) {
+	return core.NewAuthenticator(filepath.Join(resource.DataDir, "msa_cache"))
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ikafly144/sabalauncher/v2/pkg/core"
+	"github.com/ikafly144/sabalauncher/v2/pkg/msa"
+	"github.com/ikafly144/sabalauncher/v2/pkg/resource"
+)
+
+func TestResolveInstance(t *testing.T) {
+	a := &resource.Instance{Name: "Alpha", UID: uuid.MustParse("aaaaaaaa-0000-4000-8000-000000000001")}
+	b := &resource.Instance{Name: "Beta", UID: uuid.MustParse("aaaabbbb-0000-4000-8000-000000000002")}
+	instances := []*resource.Instance{a, b}
+
+	tests := []struct {
+		ref     string
+		want    *resource.Instance
+		wantErr bool
+	}{
+		{ref: a.UID.String(), want: a},
+		{ref: "Beta", want: b},
+		{ref: "aaaabb", want: b},
+		{ref: "AAAAAAAA", want: a},
+		{ref: "aaaa", wantErr: true},
+		{ref: "gamma", wantErr: true},
+		{ref: uuid.New().String(), wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := resolveInstance(instances, tt.ref)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("resolveInstance(%q): expected error, got %s", tt.ref, got.Name)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("resolveInstance(%q) = %v, %v; want %s", tt.ref, got, err, tt.want.Name)
+		}
+	}
+}
+
+func TestResolveAccount(t *testing.T) {
+	accounts := []msa.StoredAccount{
+		{ID: "id-1.tenant", Username: "Alice"},
+		{ID: "id-2.tenant", Username: "Bob"},
+	}
+	for ref, want := range map[string]string{"alice": "id-1.tenant", "id-2.tenant": "id-2.tenant"} {
+		got, err := resolveAccount(accounts, ref)
+		if err != nil || got.ID != want {
+			t.Errorf("resolveAccount(%q) = %v, %v; want %s", ref, got.ID, err, want)
+		}
+	}
+	if _, err := resolveAccount(accounts, "carol"); err == nil {
+		t.Error("expected an error for an unknown account")
+	}
+}
+
+func TestParseInstanceArgs(t *testing.T) {
+	for _, args := range [][]string{
+		{"Alpha", "--server", "example.com", "--memory", "4096"},
+		{"--server", "example.com", "--memory", "4096", "Alpha"},
+	} {
+		fs := flag.NewFlagSet("launch", flag.ContinueOnError)
+		server := fs.String("server", "", "")
+		memory := fs.Uint64("memory", 0, "")
+		ref, err := parseInstanceArgs(fs, args)
+		if err != nil {
+			t.Fatalf("parseInstanceArgs(%v) failed: %v", args, err)
+		}
+		if ref != "Alpha" || *server != "example.com" || *memory != 4096 {
+			t.Errorf("parseInstanceArgs(%v) = %q, server=%q, memory=%d", args, ref, *server, *memory)
+		}
+	}
+
+	fs := flag.NewFlagSet("launch", flag.ContinueOnError)
+	if _, err := parseInstanceArgs(fs, nil); err == nil {
+		t.Errorf("expected error for missing instance")
+	}
+}
+
+func TestStreamProgressThrottles(t *testing.T) {
+	ch := make(chan core.ProgressEvent, 100)
+	var out bytes.Buffer
+	stop := streamProgress(&out, ch)
+	for i := 0; i <= 100; i++ {
+		ch <- core.ProgressEvent{TaskName: "Downloading", Percentage: float64(i)}
+	}
+	ch <- core.ProgressEvent{TaskName: "Downloading", Percentage: 100, IsFinished: true}
+	stop()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 11 {
+		t.Errorf("expected 11 lines (every 10%%), got %d:\n%s", len(lines), out.String())
+	}
+	if !strings.Contains(lines[len(lines)-1], "100.0%") {
+		t.Errorf("last line should report completion, got %q", lines[len(lines)-1])
+	}
+}
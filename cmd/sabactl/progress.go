@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sync"
+
+	"github.com/ikafly144/sabalauncher/v2/pkg/core"
+)
+
+// progressStep is the minimum change in percentage for which a running task is printed again.
+const progressStep = 10.0
+
+// progressPrinter writes ProgressEvents to a terminal, one line per noteworthy change.
+type progressPrinter struct {
+	out  io.Writer
+	last map[string]float64
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// streamProgress prints events from ch until stop is called.
+// The core blocks once a progress channel is full, so it must be drained for the whole operation.
+func streamProgress(out io.Writer, ch <-chan core.ProgressEvent) (stop func()) {
+	p := &progressPrinter{
+		out:  out,
+		last: make(map[string]float64),
+		done: make(chan struct{}),
+	}
+	p.wg.Go(func() {
+		for {
+			select {
+			case ev := <-ch:
+				p.print(ev)
+			case <-p.done:
+				// Flush whatever was queued before the operation returned
+				for {
+					select {
+					case ev := <-ch:
+						p.print(ev)
+					default:
+						return
+					}
+				}
+			}
+		}
+	})
+	return func() {
+		close(p.done)
+		p.wg.Wait()
+	}
+}
+
+func (p *progressPrinter) print(ev core.ProgressEvent) {
+	last, seen := p.last[ev.TaskName]
+	finished := ev.IsFinished || ev.Percentage >= 100
+	if seen && !finished && math.Abs(ev.Percentage-last) < progressStep {
+		return
+	}
+	if seen && finished && last >= 100 {
+		return
+	}
+	p.last[ev.TaskName] = ev.Percentage
+	if finished {
+		p.last[ev.TaskName] = 100
+	}
+
+	line := fmt.Sprintf("[%5.1f%%] %s", ev.Percentage, ev.TaskName)
+	if ev.Status != "" {
+		line += " - " + ev.Status
+	}
+	fmt.Fprintln(p.out, line)
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"crypto/sha1"
 	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -21,11 +22,12 @@ type downloadedFileMetadata struct {
 	Filename string
 	SHA1     string
 	SHA256   string
+	SHA512   string
 	Size     int64
 }
 
 func fetchFileMetadata(downloadURL string) (downloadedFileMetadata, error) {
-	resp, err := http.Get(downloadURL)
+	resp, err := resource.HTTPClient().Get(downloadURL)
 	if err != nil {
 		return downloadedFileMetadata{}, err
 	}
@@ -54,7 +56,8 @@ func fetchFileMetadata(downloadURL string) (downloadedFileMetadata, error) {
 
 	h1 := sha1.New()
 	h256 := sha256.New()
-	size, err := io.Copy(io.MultiWriter(h1, h256), resp.Body)
+	h512 := sha512.New()
+	size, err := io.Copy(io.MultiWriter(h1, h256, h512), resp.Body)
 	if err != nil {
 		return downloadedFileMetadata{}, err
 	}
@@ -63,6 +66,7 @@ func fetchFileMetadata(downloadURL string) (downloadedFileMetadata, error) {
 		Filename: filename,
 		SHA1:     hex.EncodeToString(h1.Sum(nil)),
 		SHA256:   hex.EncodeToString(h256.Sum(nil)),
+		SHA512:   hex.EncodeToString(h512.Sum(nil)),
 		Size:     size,
 	}, nil
 }
@@ -109,6 +113,7 @@ func runAdd(args []string) {
 			index.Files[i].Hashes = map[string]string{
 				"sha1":   meta.SHA1,
 				"sha256": meta.SHA256,
+				"sha512": meta.SHA512,
 			}
 			index.Files[i].Downloads = []string{downloadURL}
 			index.Files[i].FileSize = meta.Size
@@ -124,6 +129,7 @@ func runAdd(args []string) {
 			Hashes: map[string]string{
 				"sha1":   meta.SHA1,
 				"sha256": meta.SHA256,
+				"sha512": meta.SHA512,
 			},
 			Downloads: []string{downloadURL},
 			FileSize:  meta.Size,
@@ -6,9 +6,12 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
+	"maps"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/ikafly144/sabalauncher/v2/pkg/resource"
@@ -16,26 +19,40 @@ import (
 )
 
 func runDiff(args []string) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	var changelogFiles stringListFlag
+	fs.Var(&changelogFiles, "changelog", "markdown release notes, optionally localized as <lang>=<file> (repeatable)")
+	draft := fs.Bool("draft-changelog", false, "write a draft of the release notes listing the changed files to <output.sbpatch>.md")
+	_ = fs.Parse(args)
+	args = fs.Args()
 	if len(args) < 3 {
-		fmt.Println("Usage: sbutils diff <old.sbpack> <new.sbpack> <output.sbpatch>")
+		fmt.Println("Usage: sbutils diff [--changelog [<lang>=]<notes.md>]... [--draft-changelog] <old.sbpack> <new.sbpack> <output.sbpatch>")
+		os.Exit(1)
+	}
+	changelog, err := readChangelogFiles(changelogFiles)
+	if err != nil {
+		fmt.Printf("Failed to read changelog: %v\n", err)
 		os.Exit(1)
 	}
 
-	oldPackPath := args[0]
-	newPackPath := args[1]
-	outPatch := args[2]
+	if err := createPatch(args[0], args[1], args[2], changelog, *draft); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
 
+// createPatch writes a patch from the pack at oldPackPath to the one at newPackPath. Without changelog, the patch
+// carries the notes of the new index, or the draft if draft is set.
+func createPatch(oldPackPath, newPackPath, outPatch string, changelog resource.Changelog, draft bool) error {
 	oldZip, err := zip.OpenReader(oldPackPath)
 	if err != nil {
-		fmt.Printf("Failed to open old pack: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to open old pack: %w", err)
 	}
 	defer oldZip.Close()
 
 	newZip, err := zip.OpenReader(newPackPath)
 	if err != nil {
-		fmt.Printf("Failed to open new pack: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to open new pack: %w", err)
 	}
 	defer newZip.Close()
 
@@ -46,30 +63,25 @@ func runDiff(args []string) {
 	if f, ok := oldFiles["sb.index.json"]; ok {
 		rc, _ := f.Open()
 		if err := json.NewDecoder(rc).Decode(&oldIndex); err != nil {
-			fmt.Printf("Failed to decode old index: %v\n", err)
-			os.Exit(1)
+			return fmt.Errorf("failed to decode old index: %w", err)
 		}
 		rc.Close()
 	} else {
-		fmt.Println("Error: old pack missing sb.index.json")
-		os.Exit(1)
+		return fmt.Errorf("old pack missing sb.index.json")
 	}
 
 	if f, ok := newFiles["sb.index.json"]; ok {
 		rc, err := f.Open()
 		if err != nil {
-			fmt.Printf("Failed to open new index: %v\n", err)
-			os.Exit(1)
+			return fmt.Errorf("failed to open new index: %w", err)
 		}
 		if err := json.NewDecoder(rc).Decode(&newIndex); err != nil {
 			rc.Close()
-			fmt.Printf("Failed to decode new index: %v\n", err)
-			os.Exit(1)
+			return fmt.Errorf("failed to decode new index: %w", err)
 		}
 		rc.Close()
 	} else {
-		fmt.Println("Error: new pack missing sb.index.json")
-		os.Exit(1)
+		return fmt.Errorf("new pack missing sb.index.json")
 	}
 
 	removedFiles := []string{}
@@ -107,14 +119,31 @@ func runDiff(args []string) {
 	}
 
 	// Any in oldFiles["overrides/"] NOT in newFiles are removed
+	removedOverrides := []string{}
 	for name := range oldFiles {
 		if !strings.HasPrefix(name, "overrides/") || oldFiles[name].FileInfo().IsDir() {
 			continue
 		}
 		if _, ok := newFiles[name]; !ok {
 			removedFiles = append(removedFiles, name)
+			removedOverrides = append(removedOverrides, strings.TrimPrefix(name, "overrides/"))
+		}
+	}
+
+	if draft {
+		text := draftChangelog(oldIndex, newIndex, addedOverrides, patchedOverrides, removedOverrides)
+		draftPath := outPatch + ".md"
+		if err := os.WriteFile(draftPath, []byte(text), 0644); err != nil {
+			return fmt.Errorf("failed to write changelog draft: %w", err)
+		}
+		fmt.Printf("Wrote changelog draft to %s\n", draftPath)
+		if len(changelog) == 0 {
+			changelog = resource.Changelog{"": text}
 		}
 	}
+	if len(changelog) == 0 {
+		changelog = newIndex.Changelog
+	}
 
 	// Create patch JSON
 	// Collect hashes for the new index
@@ -129,13 +158,11 @@ func runDiff(args []string) {
 		// Let's just calculate it for now.
 		rc, err := f.Open()
 		if err != nil {
-			fmt.Printf("Failed to open file %s: %v\n", name, err)
-			os.Exit(1)
+			return fmt.Errorf("failed to open file %s: %w", name, err)
 		}
 		h := sha256.New()
 		if _, err = io.Copy(h, rc); err != nil {
-			fmt.Printf("Failed to read file %s: %v\n", name, err)
-			os.Exit(1)
+			return fmt.Errorf("failed to read file %s: %w", name, err)
 		}
 		rc.Close()
 		newHashes[rel] = hex.EncodeToString(h.Sum(nil))
@@ -147,13 +174,13 @@ func runDiff(args []string) {
 		BaseID:        oldIndex.ID,
 		Index:         newIndex,
 		RemovedFiles:  removedFiles,
+		Changelog:     changelog,
 	}
 
 	// Create output zip
 	outFile, err := os.Create(outPatch)
 	if err != nil {
-		fmt.Printf("Failed to create patch file: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to create patch file: %w", err)
 	}
 	defer outFile.Close()
 
@@ -163,47 +190,117 @@ func runDiff(args []string) {
 	// Add sb.patch.json
 	patchBytes, _ := json.MarshalIndent(patch, "", "  ")
 	if err := addDataToZip(w, patchBytes, "sb.patch.json"); err != nil {
-		fmt.Printf("Failed to add sb.patch.json: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to add sb.patch.json: %w", err)
 	}
 
 	// Add added overrides
 	for _, rel := range addedOverrides {
-		f := newFiles["overrides/"+rel]
-		rc, err := f.Open()
+		rc, err := newFiles["overrides/"+rel].Open()
 		if err != nil {
-			fmt.Printf("Failed to open added override %s: %v\n", rel, err)
-			continue
+			return fmt.Errorf("failed to open added override %s: %w", rel, err)
 		}
-		data, _ := io.ReadAll(rc)
+		data, err := io.ReadAll(rc)
 		rc.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read added override %s: %w", rel, err)
+		}
 		if err := addDataToZip(w, data, "overrides/"+rel); err != nil {
-			fmt.Printf("Failed to add added override %s: %v\n", rel, err)
+			return fmt.Errorf("failed to add added override %s: %w", rel, err)
 		}
 	}
 
 	// Add patched overrides (binary diff)
 	for _, rel := range patchedOverrides {
-		oldF := oldFiles["overrides/"+rel]
-		newF := newFiles["overrides/"+rel]
+		if err := addFileDiff(w, oldFiles["overrides/"+rel], newFiles["overrides/"+rel], "patches/"+rel); err != nil {
+			return fmt.Errorf("failed to diff %s: %w", rel, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outPatch, err)
+	}
 
-		oldRc, _ := oldF.Open()
-		newRc, _ := newF.Open()
+	fmt.Printf("Successfully created patch %s\n", outPatch)
+	return nil
+}
 
-		var buf bytes.Buffer
-		if err := binarydist.Diff(oldRc, newRc, &buf); err != nil {
-			fmt.Printf("Failed to diff %s: %v\n", rel, err)
-			oldRc.Close()
-			newRc.Close()
-			continue
+// addFileDiff adds the binary diff from oldF to newF to w as zipPath.
+func addFileDiff(w *zip.Writer, oldF, newF *zip.File, zipPath string) error {
+	oldRc, err := oldF.Open()
+	if err != nil {
+		return err
+	}
+	defer oldRc.Close()
+	newRc, err := newF.Open()
+	if err != nil {
+		return err
+	}
+	defer newRc.Close()
+
+	var buf bytes.Buffer
+	if err := binarydist.Diff(oldRc, newRc, &buf); err != nil {
+		return err
+	}
+	return addDataToZip(w, buf.Bytes(), zipPath)
+}
+
+// readChangelogFiles reads release notes given as <file> or <lang>=<file>.
+func readChangelogFiles(specs []string) (resource.Changelog, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	changelog := resource.Changelog{}
+	for _, spec := range specs {
+		lang, path, ok := strings.Cut(spec, "=")
+		if !ok {
+			lang, path = "", spec
 		}
-		oldRc.Close()
-		newRc.Close()
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		changelog[lang] = string(b)
+	}
+	return changelog, nil
+}
 
-		if err := addDataToZip(w, buf.Bytes(), "patches/"+rel); err != nil {
-			fmt.Printf("Failed to add patch %s: %v\n", rel, err)
+// draftChangelog lists the files and overrides a patch from oldIndex to newIndex adds, removes and updates, as a
+// starting point for the release notes.
+func draftChangelog(oldIndex, newIndex resource.SBPackIndex, addedOverrides, patchedOverrides, removedOverrides []string) string {
+	oldFiles := make(map[string]resource.SBFile, len(oldIndex.Files))
+	for _, f := range oldIndex.Files {
+		oldFiles[f.Path] = f
+	}
+	added := slices.Clone(addedOverrides)
+	updated := slices.Clone(patchedOverrides)
+	removed := slices.Clone(removedOverrides)
+	for _, f := range newIndex.Files {
+		old, ok := oldFiles[f.Path]
+		delete(oldFiles, f.Path)
+		switch {
+		case !ok:
+			added = append(added, f.Path)
+		case !maps.Equal(old.Hashes, f.Hashes):
+			updated = append(updated, f.Path)
 		}
 	}
+	for path := range oldFiles {
+		removed = append(removed, path)
+	}
 
-	fmt.Printf("Successfully created patch %s\n", outPatch)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n", newIndex.Name)
+	for _, section := range []struct {
+		title string
+		paths []string
+	}{{"Added", added}, {"Updated", updated}, {"Removed", removed}} {
+		if len(section.paths) == 0 {
+			continue
+		}
+		slices.Sort(section.paths)
+		fmt.Fprintf(&sb, "\n## %s\n\n", section.title)
+		for _, path := range section.paths {
+			fmt.Fprintf(&sb, "- %s\n", path)
+		}
+	}
+	return sb.String()
 }
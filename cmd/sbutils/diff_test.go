@@ -0,0 +1,64 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ikafly144/sabalauncher/v2/pkg/resource"
+)
+
+func TestDraftChangelog(t *testing.T) {
+	oldIndex := resource.SBPackIndex{Files: []resource.SBFile{
+		{Path: "mods/kept.jar", Hashes: map[string]string{"sha1": "a"}},
+		{Path: "mods/updated.jar", Hashes: map[string]string{"sha1": "b"}},
+		{Path: "mods/removed.jar", Hashes: map[string]string{"sha1": "c"}},
+	}}
+	newIndex := resource.SBPackIndex{Name: "Pack", Files: []resource.SBFile{
+		{Path: "mods/kept.jar", Hashes: map[string]string{"sha1": "a"}},
+		{Path: "mods/updated.jar", Hashes: map[string]string{"sha1": "b2"}},
+		{Path: "mods/added.jar", Hashes: map[string]string{"sha1": "d"}},
+	}}
+
+	got := draftChangelog(oldIndex, newIndex, []string{"config/new.toml"}, []string{"options.txt"}, []string{"config/old.toml"})
+	want := `# Pack
+
+## Added
+
+- config/new.toml
+- mods/added.jar
+
+## Updated
+
+- mods/updated.jar
+- options.txt
+
+## Removed
+
+- config/old.toml
+- mods/removed.jar
+`
+	if got != want {
+		t.Errorf("draftChangelog() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestReadChangelogFiles(t *testing.T) {
+	dir := t.TempDir()
+	en := filepath.Join(dir, "notes.md")
+	ja := filepath.Join(dir, "notes.ja.md")
+	_ = os.WriteFile(en, []byte("Fixed crashes"), 0644)
+	_ = os.WriteFile(ja, []byte("クラッシュを修正"), 0644)
+
+	changelog, err := readChangelogFiles([]string{en, "ja=" + ja})
+	if err != nil {
+		t.Fatalf("readChangelogFiles failed: %v", err)
+	}
+	if changelog.Text("ja") != "クラッシュを修正" || changelog.Text("en") != "Fixed crashes" {
+		t.Errorf("unexpected changelog: %v", changelog)
+	}
+	if _, err := readChangelogFiles([]string{filepath.Join(dir, "missing.md")}); err == nil || !strings.Contains(err.Error(), "missing.md") {
+		t.Errorf("expected a missing file to fail, got %v", err)
+	}
+}
@@ -319,6 +319,7 @@ func upsertSBFile(files []resource.SBFile, filePath string, downloadURL string,
 		Hashes: map[string]string{
 			"sha1":   meta.SHA1,
 			"sha256": meta.SHA256,
+			"sha512": meta.SHA512,
 		},
 		Downloads: []string{downloadURL},
 		FileSize:  meta.Size,
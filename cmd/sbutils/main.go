@@ -26,10 +26,14 @@ func main() {
 		runDiff(os.Args[2:])
 	case "patch":
 		runPatch(os.Args[2:])
+	case "squash":
+		runSquash(os.Args[2:])
 	case "split":
 		runSplit(os.Args[2:])
 	case "repo":
 		runRepo(os.Args[2:])
+	case "export-mrpack":
+		runExportMRPack(os.Args[2:])
 	default:
 		fmt.Printf("Unknown command: %s\n", command)
 		printUsage()
@@ -49,12 +53,16 @@ func printUsage() {
 	fmt.Println("      Edit sb.index.json fields (name/id/dependencies/files)")
 	fmt.Println("  pack <dir> <output.sbpack>")
 	fmt.Println("      Package a directory into an .sbpack (auto-generates new ID)")
-	fmt.Println("  diff <old.sbpack> <new.sbpack> <output.sbpatch>")
-	fmt.Println("      Create a patch from old to new sbpack")
+	fmt.Println("  diff [--changelog [<lang>=]<notes.md>]... [--draft-changelog] <old.sbpack> <new.sbpack> <output.sbpatch>")
+	fmt.Println("      Create a patch from old to new sbpack, with release notes from files or a generated draft")
 	fmt.Println("  patch <base.sbpack> <patch.sbpatch> <output.sbpack>")
 	fmt.Println("      Apply a patch to a base sbpack to generate a new sbpack")
+	fmt.Println("  squash <base.sbpack> <p1.sbpatch> [<p2.sbpatch> ...] <output.sbpatch>")
+	fmt.Println("      Collapse a chain of patches into one patch against the base sbpack")
 	fmt.Println("  split <base.sbpack> <large.sbpatch> <output_prefix> <max_size_mb>")
 	fmt.Println("      Split a large sbpatch into multiple sequential patches")
-	fmt.Println("  repo <init|add|validate> [arguments]")
+	fmt.Println("  repo <init|add|promote|validate|keygen|sign> [arguments]")
 	fmt.Println("      Manage an sbrepository manifest.json")
+	fmt.Println("  export-mrpack <input.sbpack> [output.mrpack] [version_id]")
+	fmt.Println("      Convert an sbpack into a Modrinth .mrpack")
 }
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/ikafly144/sabalauncher/v2/pkg/resource"
+)
+
+// mrpackAllowedHosts are the download hosts Modrinth accepts for files of a published modpack.
+var mrpackAllowedHosts = []string{"cdn.modrinth.com", "github.com", "raw.githubusercontent.com", "gitlab.com"}
+
+func runExportMRPack(args []string) {
+	if len(args) < 1 || len(args) > 3 {
+		fmt.Println("Usage: sbutils export-mrpack <input.sbpack> [output.mrpack] [version_id]")
+		os.Exit(1)
+	}
+
+	inPath := args[0]
+	outPath := strings.TrimSuffix(inPath, ".sbpack") + ".mrpack"
+	if len(args) > 1 {
+		outPath = args[1]
+	}
+
+	r, err := zip.OpenReader(inPath)
+	if err != nil {
+		fmt.Printf("Failed to open %s: %v\n", inPath, err)
+		os.Exit(1)
+	}
+	defer r.Close()
+
+	files := mapZipFiles(&r.Reader)
+	indexFile, ok := files["sb.index.json"]
+	if !ok {
+		fmt.Printf("Error: %s does not contain sb.index.json\n", inPath)
+		os.Exit(1)
+	}
+	rc, err := indexFile.Open()
+	if err != nil {
+		fmt.Printf("Failed to read sb.index.json: %v\n", err)
+		os.Exit(1)
+	}
+	var index resource.SBPackIndex
+	err = json.NewDecoder(rc).Decode(&index)
+	rc.Close()
+	if err != nil {
+		fmt.Printf("Failed to parse sb.index.json: %v\n", err)
+		os.Exit(1)
+	}
+
+	versionID := index.ID.String()
+	if len(args) > 2 {
+		versionID = args[2]
+	}
+
+	if err := completeMRPackHashes(&index); err != nil {
+		fmt.Printf("Failed to hash files: %v\n", err)
+		os.Exit(1)
+	}
+
+	mrIndex, err := resource.NewMRPackIndex(index, versionID)
+	if err != nil {
+		fmt.Printf("Failed to convert index: %v\n", err)
+		os.Exit(1)
+	}
+	for _, f := range mrIndex.Files {
+		for _, d := range f.Downloads {
+			if u, err := url.Parse(d); err != nil || !slices.Contains(mrpackAllowedHosts, u.Host) {
+				fmt.Printf("Warning: %s is downloaded from %s, which Modrinth does not accept for published packs\n", f.Path, d)
+			}
+		}
+	}
+
+	mrIndexBytes, err := json.MarshalIndent(mrIndex, "", "  ")
+	if err != nil {
+		fmt.Printf("Failed to marshal %s: %v\n", resource.MRPackIndexName, err)
+		os.Exit(1)
+	}
+
+	outFile, err := os.Create(outPath)
+	if err != nil {
+		fmt.Printf("Failed to create %s: %v\n", outPath, err)
+		os.Exit(1)
+	}
+	defer outFile.Close()
+
+	w := zip.NewWriter(outFile)
+	if err := addDataToZip(w, mrIndexBytes, resource.MRPackIndexName); err != nil {
+		fmt.Printf("Failed to write %s: %v\n", resource.MRPackIndexName, err)
+		os.Exit(1)
+	}
+	// Both formats keep local files under overrides/
+	for _, f := range r.File {
+		if !strings.HasPrefix(f.Name, "overrides/") {
+			continue
+		}
+		if err := copyZipFile(w, f); err != nil {
+			fmt.Printf("Failed to copy %s: %v\n", f.Name, err)
+			os.Exit(1)
+		}
+	}
+	if err := w.Close(); err != nil {
+		fmt.Printf("Failed to finalize %s: %v\n", outPath, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Exported %s (version %s) to %s\n", index.Name, versionID, outPath)
+}
+
+// completeMRPackHashes downloads files lacking the sha1/sha512 hashes required by mrpack and fills them in.
+func completeMRPackHashes(index *resource.SBPackIndex) error {
+	for i, f := range index.Files {
+		if f.Hashes["sha1"] != "" && f.Hashes["sha512"] != "" {
+			continue
+		}
+		if len(f.Downloads) == 0 {
+			return fmt.Errorf("%s has no download URL", f.Path)
+		}
+
+		fmt.Printf("Hashing %s\n", f.Path)
+		meta, err := fetchFileMetadata(f.Downloads[0])
+		if err != nil {
+			return fmt.Errorf("failed to download %s: %w", f.Path, err)
+		}
+		if expected := f.Hashes["sha256"]; expected != "" && expected != meta.SHA256 {
+			return fmt.Errorf("%s does not match its sha256 hash", f.Path)
+		}
+		if expected := f.Hashes["sha1"]; expected != "" && expected != meta.SHA1 {
+			return fmt.Errorf("%s does not match its sha1 hash", f.Path)
+		}
+
+		hashes := make(map[string]string, len(f.Hashes)+2)
+		for k, v := range f.Hashes {
+			hashes[k] = v
+		}
+		hashes["sha1"] = meta.SHA1
+		hashes["sha512"] = meta.SHA512
+		index.Files[i].Hashes = hashes
+		if index.Files[i].FileSize == 0 {
+			index.Files[i].FileSize = meta.Size
+		}
+	}
+	return nil
+}
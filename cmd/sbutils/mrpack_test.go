@@ -0,0 +1,109 @@
+package main
+
+import (
+	"archive/zip"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ikafly144/sabalauncher/v2/pkg/resource"
+)
+
+func TestExportMRPack(t *testing.T) {
+	modContent := []byte("mod_content")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(modContent)
+	}))
+	defer server.Close()
+
+	tempDir := t.TempDir()
+	inPath := filepath.Join(tempDir, "pack.sbpack")
+	outPath := filepath.Join(tempDir, "pack.mrpack")
+
+	id, _ := uuid.NewV7()
+	index := resource.SBPackIndex{
+		FormatVersion: resource.SBPackFormatVersion,
+		Name:          "Export Pack",
+		ID:            id,
+		Dependencies:  map[string]string{"minecraft": "1.20.1", "fabric-loader": "0.15.0"},
+		Files: []resource.SBFile{
+			{
+				Path:      "mods/mod.jar",
+				Hashes:    map[string]string{"sha256": sha256Hex(modContent)},
+				Downloads: []string{server.URL + "/mod.jar"},
+			},
+		},
+	}
+	indexBytes, _ := json.Marshal(index)
+
+	f, err := os.Create(inPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(f)
+	for name, data := range map[string][]byte{
+		"sb.index.json":         indexBytes,
+		"sb.index.json.sig":     []byte("{}"),
+		"overrides/options.txt": []byte("options"),
+		"unrelated/readme.txt":  []byte("ignored"),
+	} {
+		zw, _ := w.Create(name)
+		_, _ = zw.Write(data)
+	}
+	_ = w.Close()
+	_ = f.Close()
+
+	runExportMRPack([]string{inPath, outPath, "v1"})
+
+	r, err := zip.OpenReader(outPath)
+	if err != nil {
+		t.Fatalf("failed to open exported mrpack: %v", err)
+	}
+	defer r.Close()
+
+	files := mapZipFiles(&r.Reader)
+	if _, ok := files["overrides/options.txt"]; !ok {
+		t.Errorf("expected overrides to be copied")
+	}
+	for _, name := range []string{"sb.index.json", "sb.index.json.sig", "unrelated/readme.txt"} {
+		if _, ok := files[name]; ok {
+			t.Errorf("did not expect %s in the mrpack", name)
+		}
+	}
+
+	indexFile, ok := files[resource.MRPackIndexName]
+	if !ok {
+		t.Fatalf("%s missing from mrpack", resource.MRPackIndexName)
+	}
+	rc, _ := indexFile.Open()
+	data, _ := io.ReadAll(rc)
+	rc.Close()
+
+	var mr resource.MRPackIndex
+	if err := json.Unmarshal(data, &mr); err != nil {
+		t.Fatalf("failed to parse %s: %v", resource.MRPackIndexName, err)
+	}
+	if mr.FormatVersion != resource.MRPackFormatVersion || mr.Game != resource.MRPackGame || mr.VersionID != "v1" {
+		t.Errorf("unexpected header: %+v", mr)
+	}
+	if mr.Dependencies["fabric-loader"] != "0.15.0" {
+		t.Errorf("unexpected dependencies: %v", mr.Dependencies)
+	}
+	if len(mr.Files) != 1 {
+		t.Fatalf("expected 1 file, got %d", len(mr.Files))
+	}
+	sum := sha512.Sum512(modContent)
+	if mr.Files[0].Hashes["sha1"] != sha1Hex(modContent) || mr.Files[0].Hashes["sha512"] != hex.EncodeToString(sum[:]) {
+		t.Errorf("expected sha1 and sha512 to be filled in, got %v", mr.Files[0].Hashes)
+	}
+	if mr.Files[0].FileSize != int64(len(modContent)) {
+		t.Errorf("expected file size %d, got %d", len(modContent), mr.Files[0].FileSize)
+	}
+}
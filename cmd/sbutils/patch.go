@@ -18,21 +18,23 @@ func runPatch(args []string) {
 		os.Exit(1)
 	}
 
-	basePackPath := args[0]
-	patchPackPath := args[1]
-	outPackPath := args[2]
+	if err := applyPatch(args[0], args[1], args[2]); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
 
+// applyPatch writes the pack produced by applying the patch at patchPackPath to the pack at basePackPath.
+func applyPatch(basePackPath, patchPackPath, outPackPath string) error {
 	baseZip, err := zip.OpenReader(basePackPath)
 	if err != nil {
-		fmt.Printf("Failed to open base pack: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to open base pack: %w", err)
 	}
 	defer baseZip.Close()
 
 	patchZip, err := zip.OpenReader(patchPackPath)
 	if err != nil {
-		fmt.Printf("Failed to open patch pack: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to open patch pack: %w", err)
 	}
 	defer patchZip.Close()
 
@@ -43,13 +45,11 @@ func runPatch(args []string) {
 	if f, ok := patchFiles["sb.patch.json"]; ok {
 		rc, _ := f.Open()
 		if err := json.NewDecoder(rc).Decode(&patch); err != nil {
-			fmt.Printf("Failed to decode patch JSON: %v\n", err)
-			os.Exit(1)
+			return fmt.Errorf("failed to decode patch JSON: %w", err)
 		}
 		rc.Close()
 	} else {
-		fmt.Println("Error: patch missing sb.patch.json")
-		os.Exit(1)
+		return fmt.Errorf("patch missing sb.patch.json")
 	}
 
 	// Verify base ID
@@ -57,21 +57,18 @@ func runPatch(args []string) {
 	if f, ok := baseFiles["sb.index.json"]; ok {
 		rc, _ := f.Open()
 		if err := json.NewDecoder(rc).Decode(&baseIndex); err != nil {
-			fmt.Printf("Failed to decode base index: %v\n", err)
-			os.Exit(1)
+			return fmt.Errorf("failed to decode base index: %w", err)
 		}
 		rc.Close()
 	}
 
 	if baseIndex.ID != patch.BaseID {
-		fmt.Printf("Version mismatch: base is %s, patch expects %s\n", baseIndex.ID, patch.BaseID)
-		os.Exit(1)
+		return fmt.Errorf("version mismatch: base is %s, patch expects %s", baseIndex.ID, patch.BaseID)
 	}
 
 	outFile, err := os.Create(outPackPath)
 	if err != nil {
-		fmt.Printf("Failed to create output file: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to create output file: %w", err)
 	}
 	defer outFile.Close()
 
@@ -115,8 +112,7 @@ func runPatch(args []string) {
 		}
 
 		if err := copyZipFile(w, f); err != nil {
-			fmt.Printf("Failed to copy %s: %v\n", name, err)
-			os.Exit(1)
+			return fmt.Errorf("failed to copy %s: %w", name, err)
 		}
 	}
 
@@ -131,57 +127,8 @@ func runPatch(args []string) {
 			fmt.Printf("Warning: base file missing for patch %s\n", rel)
 			continue
 		}
-
-		baseRc, err := baseF.Open()
-		if err != nil {
-			fmt.Printf("Failed to open base file %s: %v\n", rel, err)
-			continue
-		}
-		patchRc, err := f.Open()
-		if err != nil {
-			baseRc.Close()
-			fmt.Printf("Failed to open patch file %s: %v\n", name, err)
-			continue
-		}
-
-		// Use a temp file to apply patch safely
-		tempFile, err := os.CreateTemp("", "sbpatch-*")
-		if err != nil {
-			baseRc.Close()
-			patchRc.Close()
-			fmt.Printf("Failed to create temp file for patch %s: %v\n", rel, err)
-			continue
-		}
-
-		if err := binarydist.Patch(baseRc, tempFile, patchRc); err != nil {
-			fmt.Printf("Failed to apply patch to %s: %v\n", rel, err)
-			baseRc.Close()
-			patchRc.Close()
-			tempFile.Close()
-			_ = os.Remove(tempFile.Name())
-			continue
-		}
-		baseRc.Close()
-		patchRc.Close()
-
-		// Read patched data from temp file
-		if _, err := tempFile.Seek(0, 0); err != nil {
-			fmt.Printf("Failed to seek temp file for %s: %v\n", rel, err)
-			tempFile.Close()
-			_ = os.Remove(tempFile.Name())
-			continue
-		}
-
-		patchedData, err := io.ReadAll(tempFile)
-		tempFile.Close()
-		_ = os.Remove(tempFile.Name())
-		if err != nil {
-			fmt.Printf("Failed to read patched data for %s: %v\n", rel, err)
-			continue
-		}
-
-		if err := addDataToZip(w, patchedData, "overrides/"+rel); err != nil {
-			fmt.Printf("Failed to add patched file %s to zip: %v\n", rel, err)
+		if err := applyFilePatch(w, baseF, f, "overrides/"+rel); err != nil {
+			return fmt.Errorf("failed to apply patch to %s: %w", rel, err)
 		}
 	}
 
@@ -191,15 +138,53 @@ func runPatch(args []string) {
 			continue
 		}
 		if err := copyZipFile(w, f); err != nil {
-			fmt.Printf("Failed to add %s: %v\n", name, err)
+			return fmt.Errorf("failed to add %s: %w", name, err)
 		}
 	}
 
 	// 4. Add new sb.index.json
 	newIndexBytes, _ := json.MarshalIndent(patch.Index, "", "  ")
 	if err := addDataToZip(w, newIndexBytes, "sb.index.json"); err != nil {
-		fmt.Printf("Failed to add new index: %v\n", err)
+		return fmt.Errorf("failed to add new index: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outPackPath, err)
 	}
 
 	fmt.Printf("Successfully patched to %s\n", outPackPath)
+	return nil
+}
+
+// applyFilePatch applies the binary diff patchF to baseF and adds the result to w as zipPath.
+func applyFilePatch(w *zip.Writer, baseF, patchF *zip.File, zipPath string) error {
+	baseRc, err := baseF.Open()
+	if err != nil {
+		return err
+	}
+	defer baseRc.Close()
+	patchRc, err := patchF.Open()
+	if err != nil {
+		return err
+	}
+	defer patchRc.Close()
+
+	// Use a temp file to apply patch safely
+	tempFile, err := os.CreateTemp("", "sbpatch-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile.Name())
+	defer tempFile.Close()
+
+	if err := binarydist.Patch(baseRc, tempFile, patchRc); err != nil {
+		return err
+	}
+	if _, err := tempFile.Seek(0, 0); err != nil {
+		return err
+	}
+	patchedData, err := io.ReadAll(tempFile)
+	if err != nil {
+		return err
+	}
+	return addDataToZip(w, patchedData, zipPath)
 }
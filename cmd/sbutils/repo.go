@@ -3,6 +3,7 @@ package main
 import (
 	"archive/zip"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -26,8 +27,14 @@ func runRepo(args []string) {
 		runRepoInit(args[1:])
 	case "add":
 		runRepoAdd(args[1:])
+	case "promote":
+		runRepoPromote(args[1:])
 	case "validate":
 		runRepoValidate(args[1:])
+	case "keygen":
+		runRepoKeygen(args[1:])
+	case "sign":
+		runRepoSign(args[1:])
 	default:
 		fmt.Printf("Unknown repo command: %s\n", command)
 		printRepoUsage()
@@ -41,12 +48,21 @@ func printRepoUsage() {
 	fmt.Println("Commands:")
 	fmt.Println("  init <name>")
 	fmt.Println("      Initialize a new repository manifest.json")
-	fmt.Println("  add <id> <type(sbpack|sbpatch)> <file_path> <remote_url> [local_path]")
+	fmt.Println("  add [--channel <stable|beta|dev>] [--shortcut] <id> <type(sbpack|sbpatch)> <file_path> <remote_url> [local_path]")
 	fmt.Println("      Full: Calculate hashes for a local file and add it with specific details")
-	fmt.Println("  add <remote_prefix> <file_path> [<file_path2> ...]")
+	fmt.Println("  add [--channel <stable|beta|dev>] [--shortcut] <remote_prefix> <file_path> [<file_path2> ...]")
 	fmt.Println("      Shorthand: Add one or more files, deriving ID/type from filenames joined with prefix")
+	fmt.Println("      New entries are published to the stable channel unless --channel is given")
+	fmt.Println("      With --shortcut, the files are squashed patches added next to the entries they lead to")
+	fmt.Println("  promote <id> <stable|beta|dev>")
+	fmt.Println("      Move an entry to another release channel")
 	fmt.Println("  validate")
 	fmt.Println("      Check if all patches in the manifest form a valid dependency graph")
+	fmt.Println("  keygen [name]")
+	fmt.Println("      Generate an ed25519 signing key pair (<name>.key / <name>.pub, default 'sbrepo')")
+	fmt.Println("  sign <key_file> [<manifest.json|file.sbpack|file.sbpatch> ...]")
+	fmt.Println("      Sign the manifest (detached .sig) or the metadata of packs/patches (default: manifest.json)")
+	fmt.Println("      Sign packs before 'repo add', and re-sign the manifest after every change")
 }
 
 func runRepoInit(args []string) {
@@ -65,10 +81,19 @@ func runRepoInit(args []string) {
 }
 
 func runRepoAdd(args []string) {
+	fs := flag.NewFlagSet("repo add", flag.ExitOnError)
+	channel := fs.String("channel", "", "release channel of the added entries (stable, beta or dev)")
+	shortcut := fs.Bool("shortcut", false, "add squashed patches as shortcuts to versions already in the manifest")
+	_ = fs.Parse(args)
+	args = fs.Args()
 	if len(args) < 2 {
 		printRepoUsage()
 		os.Exit(1)
 	}
+	if *channel != "" && !resource.IsValidChannel(*channel) {
+		fmt.Printf("Error: Unknown channel '%s'. Must be one of %s.\n", *channel, strings.Join(resource.Channels, ", "))
+		os.Exit(1)
+	}
 
 	repo := readManifest("manifest.json")
 	timestamp := time.Now().Unix()
@@ -90,7 +115,10 @@ func runRepoAdd(args []string) {
 		if len(args) > 4 {
 			localPath = args[4]
 		}
-		addFileToManifest(&repo, id, typ, filePath, remoteURL, localPath, timestamp)
+		addFileToManifest(&repo, id, typ, filePath, remoteURL, localPath, *channel, timestamp)
+		if *shortcut {
+			registerShortcut(&repo, id)
+		}
 	} else {
 		// Shorthand: add <remote_prefix> <file1> <file2> ...
 		remotePrefix := args[0]
@@ -106,7 +134,10 @@ func runRepoAdd(args []string) {
 			// localPath := filename
 
 			fmt.Printf("Processing shorthand: %s -> ID=%s, Type=%s\n", filename, id, typ)
-			addFileToManifest(&repo, id, typ, filePath, remoteURL, "", timestamp)
+			addFileToManifest(&repo, id, typ, filePath, remoteURL, "", *channel, timestamp)
+			if *shortcut {
+				registerShortcut(&repo, id)
+			}
 		}
 	}
 
@@ -120,9 +151,15 @@ func runRepoAdd(args []string) {
 	}
 
 	writeManifest("manifest.json", repo)
+
+	if _, err := os.Stat("manifest.json" + resource.SignatureSuffix); err == nil {
+		fmt.Println("Note: manifest.json has changed, re-run 'sbutils repo sign' to update its signature")
+	}
 }
 
-func addFileToManifest(repo *resource.SBRepository, id string, typ resource.SBPatchType, filePath, remoteURL, localPath string, timestamp int64) {
+// addFileToManifest adds the pack or patch at filePath to repo, or replaces the entry with the same ID. An empty
+// channel keeps the channel of the replaced entry.
+func addFileToManifest(repo *resource.SBRepository, id string, typ resource.SBPatchType, filePath, remoteURL, localPath, channel string, timestamp int64) {
 	if typ != resource.SBPatchTypePack && typ != resource.SBPatchTypePatch {
 		fmt.Printf("Error: Invalid type '%s' for ID '%s'. Must be 'sbpack' or 'sbpatch'.\n", typ, id)
 		os.Exit(1)
@@ -134,18 +171,25 @@ func addFileToManifest(repo *resource.SBRepository, id string, typ resource.SBPa
 		os.Exit(1)
 	}
 
+	entry := resource.SBRepoPatch{
+		ID:         id,
+		Type:       typ,
+		Hash:       map[string]string{"sha256": hash},
+		RemotePath: remoteURL,
+		LocalPath:  localPath,
+		Timestamp:  timestamp,
+		Channel:    channel,
+	}
+	fillPatchMetadata(&entry, filePath)
+
 	// Update existing or add new
 	found := false
 	for i, p := range repo.Patches {
 		if p.ID == id {
-			repo.Patches[i] = resource.SBRepoPatch{
-				ID:         id,
-				Type:       typ,
-				Hash:       map[string]string{"sha256": hash},
-				RemotePath: remoteURL,
-				LocalPath:  localPath,
-				Timestamp:  timestamp,
+			if entry.Channel == "" {
+				entry.Channel = p.Channel
 			}
+			repo.Patches[i] = entry
 			found = true
 			fmt.Printf("Updated existing patch entry '%s' with timestamp %d\n", id, timestamp)
 			break
@@ -153,18 +197,104 @@ func addFileToManifest(repo *resource.SBRepository, id string, typ resource.SBPa
 	}
 
 	if !found {
-		repo.Patches = append(repo.Patches, resource.SBRepoPatch{
-			ID:         id,
-			Type:       typ,
-			Hash:       map[string]string{"sha256": hash},
-			RemotePath: remoteURL,
-			LocalPath:  localPath,
-			Timestamp:  timestamp,
-		})
+		repo.Patches = append(repo.Patches, entry)
 		fmt.Printf("Added new patch entry '%s' with timestamp %d\n", id, timestamp)
 	}
 }
 
+// registerShortcut turns the entry with the given ID, a squashed patch, into a shortcut to the version it produces:
+// it is moved next to the entry of that version and takes its timestamp and channel, so it does not become a new
+// version of its own. The original patches stay in the manifest.
+func registerShortcut(repo *resource.SBRepository, id string) {
+	i := slices.IndexFunc(repo.Patches, func(p resource.SBRepoPatch) bool { return p.ID == id })
+	if i < 0 {
+		return
+	}
+	entry := repo.Patches[i]
+	if entry.Type != resource.SBPatchTypePatch || entry.IndexID == "" || entry.BaseID == "" {
+		fmt.Printf("Error: '%s' is not a patch with recorded base and index IDs, it cannot be a shortcut\n", id)
+		os.Exit(1)
+	}
+	repo.Patches = slices.Delete(repo.Patches, i, i+1)
+
+	target := slices.IndexFunc(repo.Patches, func(p resource.SBRepoPatch) bool { return p.IndexID == entry.IndexID })
+	if target < 0 {
+		fmt.Printf("Error: no entry in the manifest produces index %s, add the original patches first\n", entry.IndexID)
+		os.Exit(1)
+	}
+	entry.Timestamp = repo.Patches[target].Timestamp
+	// A shortcut must not publish its version to a channel the version is not in
+	if entry.Channel != "" && entry.Channel != repo.Patches[target].Channel {
+		fmt.Printf("Note: '%s' takes the channel of '%s' instead of '%s'\n", id, repo.Patches[target].ID, entry.Channel)
+	}
+	entry.Channel = repo.Patches[target].Channel
+	repo.Patches = slices.Insert(repo.Patches, target+1, entry)
+	fmt.Printf("Registered '%s' as a shortcut to '%s'\n", id, repo.Patches[target].ID)
+}
+
+// fillPatchMetadata records the index IDs and the size of the pack or patch at path in entry, so the launcher can
+// resolve update paths without downloading it. Entries whose file cannot be read keep their manifest order.
+func fillPatchMetadata(entry *resource.SBRepoPatch, path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	entry.Size = info.Size()
+	baseID, indexID, changelog, err := peekPatchMetadata(path)
+	if err != nil {
+		return
+	}
+	entry.IndexID = indexID.String()
+	if len(entry.Changelog) == 0 {
+		entry.Changelog = changelog
+	}
+	entry.BaseID = ""
+	if baseID != uuid.Nil {
+		entry.BaseID = baseID.String()
+	}
+}
+
+func runRepoPromote(args []string) {
+	if len(args) != 2 {
+		fmt.Println("Usage: sbutils repo promote <id> <stable|beta|dev>")
+		os.Exit(1)
+	}
+
+	repo := readManifest("manifest.json")
+	if err := promoteEntry(&repo, args[0], args[1]); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := validateRepoGraph(&repo, ""); err != nil {
+		fmt.Printf("Warning: Repository graph validation failed: %v\n", err)
+	}
+
+	writeManifest("manifest.json", repo)
+	fmt.Printf("Moved '%s' to the %s channel\n", args[0], args[1])
+
+	if _, err := os.Stat("manifest.json" + resource.SignatureSuffix); err == nil {
+		fmt.Println("Note: manifest.json has changed, re-run 'sbutils repo sign' to update its signature")
+	}
+}
+
+// promoteEntry publishes the entry with the given ID to channel, along with the shortcuts producing the same version.
+func promoteEntry(repo *resource.SBRepository, id, channel string) error {
+	if !resource.IsValidChannel(channel) || channel == "" {
+		return fmt.Errorf("unknown channel '%s', must be one of %s", channel, strings.Join(resource.Channels, ", "))
+	}
+	i := slices.IndexFunc(repo.Patches, func(p resource.SBRepoPatch) bool { return p.ID == id })
+	if i < 0 {
+		return fmt.Errorf("entry '%s' not found in manifest", id)
+	}
+	indexID := repo.Patches[i].IndexID
+	for j := range repo.Patches {
+		if j == i || (indexID != "" && repo.Patches[j].IndexID == indexID) {
+			repo.Patches[j].Channel = channel
+		}
+	}
+	return nil
+}
+
 func runRepoValidate(args []string) {
 	repo := readManifest("manifest.json")
 	if err := validateRepoGraph(&repo, ""); err != nil {
@@ -192,88 +322,48 @@ func validateRepoGraph(repo *resource.SBRepository, currentFile string) error {
 
 	latestPatchID := repo.Patches[len(repo.Patches)-1].ID
 
-	// Map patch ID (manifest) -> metadata
-	type patchMeta struct {
-		typ    resource.SBPatchType
-		baseID string
-	}
-	metadata := make(map[string]patchMeta)
-	// Map internal index ID -> manifest entry ID
-	internalToManifest := make(map[string]string)
-
-	for _, p := range repo.Patches {
+	// Entries added before the manifest recorded index IDs get them from the local files.
+	for i := range repo.Patches {
+		p := &repo.Patches[i]
+		if p.IndexID != "" {
+			continue
+		}
 		path := p.LocalPath
 		if p.ID == latestPatchID && currentFile != "" {
 			path = currentFile
 		}
-
 		if path == "" {
-			ext := ".sbpatch"
-			if p.Type == resource.SBPatchTypePack {
-				ext = ".sbpack"
-			}
-			if _, err := os.Stat(p.ID + ext); err == nil {
-				path = p.ID + ext
-			} else {
-				// Fallback to other extension if primary not found
-				altExt := ".sbpack"
-				if ext == ".sbpack" {
-					altExt = ".sbpatch"
-				}
-				if _, err := os.Stat(p.ID + altExt); err == nil {
-					path = p.ID + altExt
-				}
-			}
+			path = findPatchFile(p)
 		}
-
 		if path != "" {
-			bID, iID, err := peekPatchMetadata(path)
-			if err == nil {
-				metadata[p.ID] = patchMeta{typ: p.Type, baseID: bID.String()}
-				internalToManifest[iID.String()] = p.ID
-			}
-		} else {
-			metadata[p.ID] = patchMeta{typ: p.Type, baseID: ""}
+			fillPatchMetadata(p, path)
 		}
 	}
 
-	// Check reachability of LatestPatch
-	if latestPatchID != "" {
-		curr := latestPatchID
-		visited := make(map[string]bool)
-		for {
-			if curr == "" || curr == uuid.Nil.String() {
-				return fmt.Errorf("reached dead end before finding an sbpack")
-			}
-			if visited[curr] {
-				return fmt.Errorf("circular dependency detected at %s", curr)
-			}
-			visited[curr] = true
-
-			m, ok := metadata[curr]
-			if !ok {
-				return fmt.Errorf("patch %s is missing from manifest or file not found", curr)
-			}
-
-			if m.typ == resource.SBPatchTypePack {
-				break
-			}
+	return resource.NewRepoGraph(repo).Validate()
+}
 
-			next, ok := internalToManifest[m.baseID]
-			if !ok {
-				return fmt.Errorf("reached dead end at %s: baseID %s not found in manifest", curr, m.baseID)
-			}
-			curr = next
+// findPatchFile looks for <id>.sbpatch or <id>.sbpack in the working directory, preferring the extension of the
+// entry type.
+func findPatchFile(p *resource.SBRepoPatch) string {
+	exts := []string{".sbpatch", ".sbpack"}
+	if p.Type == resource.SBPatchTypePack {
+		slices.Reverse(exts)
+	}
+	for _, ext := range exts {
+		if _, err := os.Stat(p.ID + ext); err == nil {
+			return p.ID + ext
 		}
 	}
-
-	return nil
+	return ""
 }
 
-func peekPatchMetadata(path string) (baseID uuid.UUID, indexID uuid.UUID, err error) {
+// peekPatchMetadata returns the base and index IDs and the release notes of the pack or patch at path. The notes of
+// a patch fall back to those of the index it produces.
+func peekPatchMetadata(path string) (baseID uuid.UUID, indexID uuid.UUID, changelog resource.Changelog, err error) {
 	r, err := zip.OpenReader(path)
 	if err != nil {
-		return uuid.Nil, uuid.Nil, err
+		return uuid.Nil, uuid.Nil, nil, err
 	}
 	defer r.Close()
 
@@ -281,31 +371,34 @@ func peekPatchMetadata(path string) (baseID uuid.UUID, indexID uuid.UUID, err er
 		if f.Name == "sb.patch.json" {
 			rc, err := f.Open()
 			if err != nil {
-				return uuid.Nil, uuid.Nil, err
+				return uuid.Nil, uuid.Nil, nil, err
 			}
 			var p resource.SBPatch
 			err = json.NewDecoder(rc).Decode(&p)
 			rc.Close()
 			if err != nil {
-				return uuid.Nil, uuid.Nil, err
+				return uuid.Nil, uuid.Nil, nil, err
+			}
+			if len(p.Changelog) == 0 {
+				p.Changelog = p.Index.Changelog
 			}
-			return p.BaseID, p.Index.ID, nil
+			return p.BaseID, p.Index.ID, p.Changelog, nil
 		}
 		if f.Name == "sb.index.json" {
 			rc, err := f.Open()
 			if err != nil {
-				return uuid.Nil, uuid.Nil, err
+				return uuid.Nil, uuid.Nil, nil, err
 			}
 			var index resource.SBPackIndex
 			err = json.NewDecoder(rc).Decode(&index)
 			rc.Close()
 			if err != nil {
-				return uuid.Nil, uuid.Nil, err
+				return uuid.Nil, uuid.Nil, nil, err
 			}
-			return uuid.Nil, index.ID, nil
+			return uuid.Nil, index.ID, index.Changelog, nil
 		}
 	}
-	return uuid.Nil, uuid.Nil, fmt.Errorf("metadata not found in %s", path)
+	return uuid.Nil, uuid.Nil, nil, fmt.Errorf("metadata not found in %s", path)
 }
 
 func readManifest(path string) resource.SBRepository {
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"archive/zip"
 	"encoding/json"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/ikafly144/sabalauncher/v2/pkg/resource"
@@ -53,3 +55,126 @@ func TestRepoCommands(t *testing.T) {
 		t.Errorf("expected sha256 hash to be calculated")
 	}
 }
+
+func TestRepoKeygenAndSign(t *testing.T) {
+	tempDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	_ = os.Chdir(tempDir)
+	defer func() { _ = os.Chdir(oldWd) }()
+
+	runRepoKeygen([]string{"publisher"})
+	pubBytes, err := os.ReadFile("publisher.pub")
+	if err != nil {
+		t.Fatalf("publisher.pub was not created: %v", err)
+	}
+	pub := strings.TrimSpace(string(pubBytes))
+
+	runRepoInit([]string{"SignedRepo"})
+
+	index := resource.SBPackIndex{FormatVersion: resource.SBPackFormatVersion, Name: "SignedRepo"}
+	indexBytes, _ := json.Marshal(index)
+	zf, _ := os.Create("v1.sbpack")
+	zw := zip.NewWriter(zf)
+	_ = addDataToZip(zw, indexBytes, "sb.index.json")
+	_ = addDataToZip(zw, []byte("data"), "overrides/config.txt")
+	_ = zw.Close()
+	_ = zf.Close()
+
+	runRepoSign([]string{"publisher.key", "v1.sbpack", "manifest.json"})
+
+	// Manifest has a detached signature
+	manifestBytes, _ := os.ReadFile("manifest.json")
+	sigBytes, err := os.ReadFile("manifest.json.sig")
+	if err != nil {
+		t.Fatalf("manifest.json.sig was not created: %v", err)
+	}
+	var sig resource.SBSignature
+	_ = json.Unmarshal(sigBytes, &sig)
+	if sig.PublicKey != pub {
+		t.Errorf("expected signature by %s, got %s", pub, sig.PublicKey)
+	}
+	if err := sig.Verify(manifestBytes); err != nil {
+		t.Errorf("manifest signature does not verify: %v", err)
+	}
+
+	// Pack has an embedded signature entry and keeps its contents
+	r, err := zip.OpenReader("v1.sbpack")
+	if err != nil {
+		t.Fatalf("failed to open signed pack: %v", err)
+	}
+	defer r.Close()
+	files := mapZipFiles(&r.Reader)
+	if _, ok := files["overrides/config.txt"]; !ok {
+		t.Errorf("signed pack lost overrides/config.txt")
+	}
+	sigFile, ok := files["sb.index.json.sig"]
+	if !ok {
+		t.Fatalf("sb.index.json.sig missing from signed pack")
+	}
+	rc, _ := sigFile.Open()
+	_ = json.NewDecoder(rc).Decode(&sig)
+	rc.Close()
+	if err := sig.Verify(indexBytes); err != nil {
+		t.Errorf("pack signature does not verify: %v", err)
+	}
+}
+
+func TestValidateRepoGraphUsesRecordedIDs(t *testing.T) {
+	tempDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	_ = os.Chdir(tempDir)
+	defer func() { _ = os.Chdir(oldWd) }()
+
+	// Two branches off the base pack; the latest entry continues the first branch even though the second was
+	// added after it.
+	repo := resource.SBRepository{Patches: []resource.SBRepoPatch{
+		{ID: "v1", Type: resource.SBPatchTypePack, IndexID: "i1", Timestamp: 1},
+		{ID: "v2a", Type: resource.SBPatchTypePatch, IndexID: "i2a", BaseID: "i1", Timestamp: 2},
+		{ID: "v2b", Type: resource.SBPatchTypePatch, IndexID: "i2b", BaseID: "i1", Timestamp: 3},
+		{ID: "v3", Type: resource.SBPatchTypePatch, IndexID: "i3", BaseID: "i2a", Timestamp: 4},
+	}}
+	if err := validateRepoGraph(&repo, ""); err != nil {
+		t.Errorf("expected a branched manifest to be valid: %v", err)
+	}
+
+	repo.Patches[3].BaseID = "missing"
+	if err := validateRepoGraph(&repo, ""); err == nil || !strings.Contains(err.Error(), "dead end") {
+		t.Errorf("expected a dead end error, got %v", err)
+	}
+}
+
+func TestRepoAddChannelAndPromote(t *testing.T) {
+	tempDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	_ = os.Chdir(tempDir)
+	defer func() { _ = os.Chdir(oldWd) }()
+
+	runRepoInit([]string{"TestRepo"})
+	_ = os.WriteFile("v1.sbpack", []byte("dummy data"), 0644)
+	runRepoAdd([]string{"--channel", resource.ChannelBeta, "v1", string(resource.SBPatchTypePack), "v1.sbpack", "http://example.com/v1.sbpack"})
+
+	repo := readManifest("manifest.json")
+	if len(repo.Patches) != 1 || repo.Patches[0].Channel != resource.ChannelBeta {
+		t.Fatalf("expected a beta entry, got %+v", repo.Patches)
+	}
+
+	// Adding the entry again without a channel keeps it in beta
+	runRepoAdd([]string{"v1", string(resource.SBPatchTypePack), "v1.sbpack", "http://example.com/v1.sbpack"})
+	repo = readManifest("manifest.json")
+	if repo.Patches[0].Channel != resource.ChannelBeta {
+		t.Errorf("expected re-adding to keep the channel, got %q", repo.Patches[0].Channel)
+	}
+
+	runRepoPromote([]string{"v1", resource.ChannelStable})
+	repo = readManifest("manifest.json")
+	if repo.Patches[0].Channel != resource.ChannelStable {
+		t.Errorf("expected the entry to be promoted to stable, got %q", repo.Patches[0].Channel)
+	}
+
+	if err := promoteEntry(&repo, "v1", "nightly"); err == nil {
+		t.Error("expected an unknown channel to be rejected")
+	}
+	if err := promoteEntry(&repo, "missing", resource.ChannelBeta); err == nil {
+		t.Error("expected an unknown entry to be rejected")
+	}
+}
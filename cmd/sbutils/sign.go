@@ -0,0 +1,194 @@
+package main
+
+import (
+	"archive/zip"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ikafly144/sabalauncher/v2/pkg/resource"
+)
+
+func runRepoKeygen(args []string) {
+	name := "sbrepo"
+	if len(args) > 0 {
+		name = args[0]
+	}
+	keyPath := name + ".key"
+	pubPath := name + ".pub"
+
+	for _, p := range []string{keyPath, pubPath} {
+		if _, err := os.Stat(p); err == nil {
+			fmt.Printf("Error: %s already exists\n", p)
+			os.Exit(1)
+		}
+	}
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		fmt.Printf("Failed to generate key: %v\n", err)
+		os.Exit(1)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		fmt.Printf("Failed to encode private key: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		fmt.Printf("Failed to write %s: %v\n", keyPath, err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(pubPath, []byte(resource.EncodePublicKey(pub)+"\n"), 0644); err != nil {
+		fmt.Printf("Failed to write %s: %v\n", pubPath, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Generated signing key %s (public key: %s)\n", keyPath, resource.EncodePublicKey(pub))
+	fmt.Println("Keep the private key secret. Launchers pin the public key on first import.")
+}
+
+func runRepoSign(args []string) {
+	if len(args) < 1 {
+		fmt.Println("Usage: sbutils repo sign <key_file> [<manifest.json|file.sbpack|file.sbpatch> ...]")
+		os.Exit(1)
+	}
+
+	priv, err := loadSigningKey(args[0])
+	if err != nil {
+		fmt.Printf("Failed to load signing key: %v\n", err)
+		os.Exit(1)
+	}
+
+	files := args[1:]
+	if len(files) == 0 {
+		files = []string{"manifest.json"}
+	}
+
+	for _, file := range files {
+		switch strings.ToLower(filepath.Ext(file)) {
+		case ".sbpack", ".sbpatch":
+			err = signArchive(priv, file)
+		default:
+			err = signFile(priv, file)
+		}
+		if err != nil {
+			fmt.Printf("Failed to sign %s: %v\n", file, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Signed %s\n", file)
+	}
+}
+
+func loadSigningKey(path string) (ed25519.PrivateKey, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found in %s", path)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an ed25519 private key", path)
+	}
+	return priv, nil
+}
+
+// signFile writes a detached signature next to a JSON document.
+func signFile(priv ed25519.PrivateKey, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	sig, err := resource.SignJSON(priv, data)
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(sig, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path+resource.SignatureSuffix, b, 0644)
+}
+
+// signArchive rewrites an sbpack/sbpatch with a signature entry for its metadata.
+// Any previous signature is replaced.
+func signArchive(priv ed25519.PrivateKey, path string) error {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	files := mapZipFiles(&r.Reader)
+	metaName := ""
+	for _, name := range []string{"sb.patch.json", "sb.index.json"} {
+		if _, ok := files[name]; ok {
+			metaName = name
+			break
+		}
+	}
+	if metaName == "" {
+		return fmt.Errorf("metadata not found in %s", path)
+	}
+
+	rc, err := files[metaName].Open()
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		return err
+	}
+	sig, err := resource.SignJSON(priv, data)
+	if err != nil {
+		return err
+	}
+	sigBytes, err := json.MarshalIndent(sig, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	out, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	w := zip.NewWriter(out)
+	err = func() error {
+		for _, f := range r.File {
+			if f.Name == metaName+resource.SignatureSuffix {
+				continue
+			}
+			if err := copyZipFile(w, f); err != nil {
+				return err
+			}
+		}
+		if err := addDataToZip(w, sigBytes, metaName+resource.SignatureSuffix); err != nil {
+			return err
+		}
+		return w.Close()
+	}()
+	out.Close()
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	// The source archive must be closed before it can be replaced on Windows.
+	r.Close()
+	return os.Rename(tmpPath, path)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ikafly144/sabalauncher/v2/pkg/resource"
+)
+
+func runSquash(args []string) {
+	if len(args) < 3 {
+		fmt.Println("Usage: sbutils squash <base.sbpack> <p1.sbpatch> [<p2.sbpatch> ...] <output.sbpatch>")
+		os.Exit(1)
+	}
+
+	if err := squashPatches(args[0], args[1:len(args)-1], args[len(args)-1]); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// squashPatches writes a single patch to outPatch that takes the pack at basePackPath to the result of applying
+// patchPaths in order.
+func squashPatches(basePackPath string, patchPaths []string, outPatch string) error {
+	tempDir, err := os.MkdirTemp("", "sbsquash-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Rebuild the pack of every step, so the final pack is exactly what applying the chain produces
+	changelogs := make([]resource.Changelog, 0, len(patchPaths))
+	current := basePackPath
+	for i, patchPath := range patchPaths {
+		_, _, changelog, err := peekPatchMetadata(patchPath)
+		if err != nil {
+			return fmt.Errorf("failed to read patch %s: %w", patchPath, err)
+		}
+		changelogs = append(changelogs, changelog)
+
+		next := filepath.Join(tempDir, fmt.Sprintf("step%d.sbpack", i+1))
+		if err := applyPatch(current, patchPath, next); err != nil {
+			return fmt.Errorf("failed to apply patch %s: %w", patchPath, err)
+		}
+		current = next
+	}
+
+	// Deltas are computed against the original base rather than chained, so every override is patched once.
+	if err := createPatch(basePackPath, current, outPatch, combineChangelogs(changelogs), false); err != nil {
+		return err
+	}
+	fmt.Printf("Squashed %d patches into %s\n", len(patchPaths), outPatch)
+	return nil
+}
+
+// combineChangelogs joins the notes of consecutive patches, language by language.
+func combineChangelogs(changelogs []resource.Changelog) resource.Changelog {
+	var langs []string
+	for _, c := range changelogs {
+		for lang := range c {
+			if !slices.Contains(langs, lang) {
+				langs = append(langs, lang)
+			}
+		}
+	}
+
+	combined := resource.Changelog{}
+	for _, lang := range langs {
+		var parts []string
+		for _, c := range changelogs {
+			if text := c.Text(lang); text != "" {
+				parts = append(parts, text)
+			}
+		}
+		combined[lang] = strings.Join(parts, "\n\n")
+	}
+	if len(combined) == 0 {
+		return nil
+	}
+	return combined
+}
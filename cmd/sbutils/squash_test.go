@@ -0,0 +1,182 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ikafly144/sabalauncher/v2/pkg/resource"
+)
+
+func writeTestPack(t *testing.T, path string, index resource.SBPackIndex, overrides map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	w := zip.NewWriter(f)
+	indexB, _ := json.Marshal(index)
+	if err := addDataToZip(w, indexB, "sb.index.json"); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range overrides {
+		if err := addDataToZip(w, []byte(content), "overrides/"+name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readTestPack(t *testing.T, path string) (resource.SBPackIndex, map[string]string) {
+	t.Helper()
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	var index resource.SBPackIndex
+	overrides := map[string]string{}
+	for _, f := range r.File {
+		rc, _ := f.Open()
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		if f.Name == "sb.index.json" {
+			_ = json.Unmarshal(b, &index)
+		} else if rel, ok := strings.CutPrefix(f.Name, "overrides/"); ok {
+			overrides[rel] = string(b)
+		}
+	}
+	return index, overrides
+}
+
+func TestSquash(t *testing.T) {
+	dir := t.TempDir()
+	ids := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	packs := []struct {
+		changelog string
+		overrides map[string]string
+	}{
+		{"", map[string]string{"config.txt": "alpha", "old.txt": "old"}},
+		{"second", map[string]string{"config.txt": "alpha beta", "old.txt": "old", "new.txt": "new"}},
+		{"third", map[string]string{"config.txt": "alpha beta gamma", "new.txt": "new"}},
+	}
+	paths := make([]string, len(packs))
+	for i, p := range packs {
+		paths[i] = filepath.Join(dir, fmt.Sprintf("v%d.sbpack", i+1))
+		index := resource.SBPackIndex{FormatVersion: resource.SBPackFormatVersion, Name: "Pack", ID: ids[i]}
+		if p.changelog != "" {
+			index.Changelog = resource.Changelog{"": p.changelog}
+		}
+		writeTestPack(t, paths[i], index, p.overrides)
+	}
+
+	p1 := filepath.Join(dir, "p1.sbpatch")
+	p2 := filepath.Join(dir, "p2.sbpatch")
+	if err := createPatch(paths[0], paths[1], p1, nil, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := createPatch(paths[1], paths[2], p2, nil, false); err != nil {
+		t.Fatal(err)
+	}
+
+	squashed := filepath.Join(dir, "squashed.sbpatch")
+	if err := squashPatches(paths[0], []string{p1, p2}, squashed); err != nil {
+		t.Fatalf("squash failed: %v", err)
+	}
+
+	baseID, indexID, changelog, err := peekPatchMetadata(squashed)
+	if err != nil {
+		t.Fatalf("failed to read squashed patch: %v", err)
+	}
+	if baseID != ids[0] || indexID != ids[2] {
+		t.Errorf("squashed patch goes from %s to %s, want %s to %s", baseID, indexID, ids[0], ids[2])
+	}
+	if got := changelog.Text("en"); got != "second\n\nthird" {
+		t.Errorf("squashed changelog = %q", got)
+	}
+
+	result := filepath.Join(dir, "result.sbpack")
+	if err := applyPatch(paths[0], squashed, result); err != nil {
+		t.Fatalf("failed to apply the squashed patch: %v", err)
+	}
+	index, overrides := readTestPack(t, result)
+	if index.ID != ids[2] {
+		t.Errorf("expected the squashed patch to produce %s, got %s", ids[2], index.ID)
+	}
+	if len(overrides) != len(packs[2].overrides) {
+		t.Errorf("unexpected overrides after applying the squashed patch: %v", overrides)
+	}
+	for name, want := range packs[2].overrides {
+		if overrides[name] != want {
+			t.Errorf("%s = %q, want %q", name, overrides[name], want)
+		}
+	}
+}
+
+func TestRegisterShortcut(t *testing.T) {
+	repo := resource.SBRepository{Patches: []resource.SBRepoPatch{
+		{ID: "v1", Type: resource.SBPatchTypePack, IndexID: "i1", Timestamp: 1},
+		{ID: "v2", Type: resource.SBPatchTypePatch, IndexID: "i2", BaseID: "i1", Timestamp: 2, Channel: resource.ChannelBeta},
+		{ID: "v3", Type: resource.SBPatchTypePatch, IndexID: "i3", BaseID: "i2", Timestamp: 3},
+		{ID: "v1-v2", Type: resource.SBPatchTypePatch, IndexID: "i2", BaseID: "i1", Timestamp: 4},
+	}}
+	registerShortcut(&repo, "v1-v2")
+
+	if got := pathIDs(repo.Patches); !slices.Equal(got, []string{"v1", "v2", "v1-v2", "v3"}) {
+		t.Fatalf("expected the shortcut next to its version, got %v", got)
+	}
+	shortcut := repo.Patches[2]
+	if shortcut.Timestamp != 2 || shortcut.Channel != resource.ChannelBeta {
+		t.Errorf("expected the shortcut to take the timestamp and channel of v2, got %+v", shortcut)
+	}
+	if latest := resource.NewRepoGraph(&repo).Latest(); latest.ID != "v3" {
+		t.Errorf("expected v3 to stay the latest entry, got %s", latest.ID)
+	}
+}
+
+func TestRegisterShortcutKeepsTargetChannel(t *testing.T) {
+	repo := resource.SBRepository{Patches: []resource.SBRepoPatch{
+		{ID: "v1", Type: resource.SBPatchTypePack, IndexID: "i1", Timestamp: 1},
+		{ID: "v2", Type: resource.SBPatchTypePatch, IndexID: "i2", BaseID: "i1", Timestamp: 2, Channel: resource.ChannelBeta},
+		{ID: "v1-v2", Type: resource.SBPatchTypePatch, IndexID: "i2", BaseID: "i1", Timestamp: 3, Channel: resource.ChannelStable},
+	}}
+	registerShortcut(&repo, "v1-v2")
+
+	if shortcut := repo.Patches[2]; shortcut.Channel != resource.ChannelBeta {
+		t.Errorf("expected the shortcut to take the beta channel of v2, got %q", shortcut.Channel)
+	}
+	graph := resource.NewRepoGraph(&repo)
+	if latest := graph.LatestIn(resource.ChannelStable); latest == nil || latest.ID != "v1" {
+		t.Errorf("expected v1 to stay the latest stable entry, got %+v", latest)
+	}
+	for _, v := range graph.Versions() {
+		if v.ID == "v2" && v.Channel != resource.ChannelBeta {
+			t.Errorf("expected v2 to be listed as beta, got %q", v.Channel)
+		}
+	}
+
+	if err := promoteEntry(&repo, "v2", resource.ChannelStable); err != nil {
+		t.Fatal(err)
+	}
+	if shortcut := repo.Patches[2]; shortcut.Channel != resource.ChannelStable {
+		t.Errorf("expected the shortcut to be promoted with v2, got %q", shortcut.Channel)
+	}
+}
+
+func pathIDs(patches []resource.SBRepoPatch) []string {
+	ids := make([]string, len(patches))
+	for i, p := range patches {
+		ids[i] = p.ID
+	}
+	return ids
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/bugph0bia/go-logging"
 	"github.com/ikafly144/sabalauncher/v2/pkg/core"
 	"github.com/ikafly144/sabalauncher/v2/pkg/msa"
+	"github.com/ikafly144/sabalauncher/v2/pkg/redact"
 	"github.com/ikafly144/sabalauncher/v2/pkg/resource"
 	"github.com/ikafly144/sabalauncher/v2/pkg/rpc"
 	uifyne "github.com/ikafly144/sabalauncher/v2/pkg/ui/fyne"
@@ -55,10 +56,15 @@ func init() {
 	logging.MaxSizeMB = 8
 	logging.WithStdout = true
 	logging.MaxBackups = 200
-	slog.SetDefault(logging.NewLogger(filepath.Join(resource.DataDir, "log", "latest.log")))
+	if err := resource.CheckDataDir(); err != nil {
+		log.Fatal(err)
+	}
+	logger := logging.NewLogger(filepath.Join(resource.DataDir, "log", "latest.log"))
+	slog.SetDefault(slog.New(redact.NewHandler(logger.Handler())))
 
 	msa.ClientID = secret.GetSecret("MSA_CLIENT_ID")
 	resource.CurseForgeAPIKey = secret.GetSecret("CURSEFORGE_API_KEY")
+	redact.Register(resource.CurseForgeAPIKey)
 
 	if err := rpc.Login(); err != nil {
 		slog.Error("failed to login to Discord RPC", "err", err)
@@ -75,6 +81,14 @@ func main() {
 		os.Exit(0)
 	}
 
+	config, err := core.LoadConfig(resource.DataDir)
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+	if err := config.ApplyNetwork(); err != nil {
+		log.Fatalf("failed to apply network settings: %v", err)
+	}
+
 	// Initialize Core Services
 	auth, err := core.NewAuthenticator(filepath.Join(resource.DataDir, "msa_cache"))
 	if err != nil {
@@ -86,11 +100,6 @@ func main() {
 		log.Fatalf("failed to initialize instance manager: %v", err)
 	}
 
-	config, err := core.LoadConfig(resource.DataDir)
-	if err != nil {
-		log.Fatalf("failed to load config: %v", err)
-	}
-
 	runner := core.NewGameRunner(auth, instances, resource.DataDir, config)
 	discord := core.NewDiscordManager(auth, instances)
 
@@ -4,16 +4,26 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
 	"sync"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/ikafly144/sabalauncher/v2/pkg/i18n"
 	"github.com/ikafly144/sabalauncher/v2/pkg/msa"
+	"github.com/ikafly144/sabalauncher/v2/pkg/resource"
 )
 
+// ErrAccountNotFound is returned when an account is not signed in to the launcher.
+var ErrAccountNotFound = errors.New("account not found")
+
 type msaAuthenticator struct {
 	session   msa.Session
+	store     *msa.AccountStore
 	status    AuthStatus
 	user      string
+	accountID string
 	mcProfile *msa.MinecraftProfile
 	account   *msa.MinecraftAccount
 	lastErr   error
@@ -36,8 +46,14 @@ func NewAuthenticator(cachePath string) (Authenticator, error) {
 		return nil, fmt.Errorf("failed to create msa session: %w", err)
 	}
 
+	store, err := msa.NewAccountStore(cache)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open account store: %w", err)
+	}
+
 	return &msaAuthenticator{
 		session: sess,
+		store:   store,
 		status:  AuthStatusLoggedOut,
 	}, nil
 }
@@ -48,14 +64,9 @@ func (a *msaAuthenticator) GetLastError() error {
 	return a.lastErr
 }
 
-func (a *msaAuthenticator) TrySilentLogin(ctx context.Context) error {
-	a.mu.Lock()
-	a.status = AuthStatusLoggingIn
-	a.lastErr = nil
-	a.mu.Unlock()
-
-	// Try to get the Minecraft account from cache (silent flow)
-	account, err := msa.NewMinecraftAccount(a.session)
+// signIn signs in to Minecraft with a cached account and makes it the active account.
+func (a *msaAuthenticator) signIn(accountID string) error {
+	account, err := msa.NewMinecraftAccount(a.session, accountID)
 	if err != nil {
 		a.mu.Lock()
 		a.status = AuthStatusLoggedOut
@@ -74,7 +85,14 @@ func (a *msaAuthenticator) TrySilentLogin(ctx context.Context) error {
 
 	profile, err := mcAuth.GetMinecraftProfile()
 	if err != nil {
-		_ = a.Logout()
+		if resource.IsNetworkError(err) {
+			a.mu.Lock()
+			a.status = AuthStatusError
+			a.lastErr = err
+			a.mu.Unlock()
+			return err
+		}
+		_ = a.RemoveAccount(accountID)
 		a.mu.Lock()
 		a.status = AuthStatusError
 		a.lastErr = errors.New(i18n.T("minecraft_not_owned"))
@@ -82,8 +100,23 @@ func (a *msaAuthenticator) TrySilentLogin(ctx context.Context) error {
 		return err
 	}
 
+	if err := a.store.Put(msa.StoredAccount{
+		ID:          accountID,
+		Username:    profile.Username,
+		UUID:        profile.UUID,
+		LastUsed:    time.Now(),
+		AccessToken: mcAuth.AccessToken,
+		ExpiresAt:   time.Now().Add(time.Duration(mcAuth.ExpiresIn) * time.Second),
+	}); err != nil {
+		return err
+	}
+	if err := a.store.SetActive(accountID); err != nil {
+		return err
+	}
+
 	a.mu.Lock()
 	a.user = profile.Username
+	a.accountID = accountID
 	a.mcProfile = profile
 	a.account = account
 	a.status = AuthStatusLoggedIn
@@ -92,6 +125,51 @@ func (a *msaAuthenticator) TrySilentLogin(ctx context.Context) error {
 	return nil
 }
 
+func (a *msaAuthenticator) TrySilentLogin(ctx context.Context) error {
+	a.mu.Lock()
+	a.status = AuthStatusLoggingIn
+	a.lastErr = nil
+	a.mu.Unlock()
+
+	accountID := a.store.Active()
+	if accountID == "" {
+		// Caches written before accounts were tracked hold a single account
+		accounts, err := a.session.Accounts()
+		if err != nil || len(accounts) != 1 {
+			a.mu.Lock()
+			a.status = AuthStatusLoggedOut
+			a.mu.Unlock()
+			return fmt.Errorf("no active account")
+		}
+		accountID = accounts[0].HomeAccountID
+	}
+
+	// Try to get the Minecraft account from cache (silent flow)
+	err := a.signIn(accountID)
+	if err == nil || !resource.IsNetworkError(err) {
+		return err
+	}
+
+	// Without a connection, sign in with the last known profile so the game can be played offline
+	stored, ok := a.store.Get(accountID)
+	if !ok || stored.UUID == uuid.Nil {
+		return err
+	}
+	slog.Warn("Minecraft services are unreachable, signing in offline", "account", stored.Username, "error", err)
+	a.mu.Lock()
+	if a.accountID != accountID {
+		// Keep a Minecraft account already signed in for this account, never one of another account
+		a.account = nil
+	}
+	a.user = stored.Username
+	a.accountID = accountID
+	a.mcProfile = stored.Profile()
+	a.status = AuthStatusLoggedIn
+	a.lastErr = nil
+	a.mu.Unlock()
+	return nil
+}
+
 func (a *msaAuthenticator) Login(ctx context.Context, method msa.LoginMethod) error {
 	a.mu.Lock()
 	defer a.mu.Unlock()
@@ -108,7 +186,7 @@ func (a *msaAuthenticator) Login(ctx context.Context, method msa.LoginMethod) er
 
 func (a *msaAuthenticator) WaitLogin(ctx context.Context) error {
 	// This blocks until the device code flow is complete or fails.
-	_, err := a.session.AuthResult()
+	result, err := a.session.AuthResult()
 	if err != nil {
 		a.mu.Lock()
 		a.status = AuthStatusError
@@ -118,41 +196,15 @@ func (a *msaAuthenticator) WaitLogin(ctx context.Context) error {
 	}
 
 	// Once logged in to MS, we need to get the Minecraft account.
-	account, err := msa.NewMinecraftAccount(a.session)
-	if err != nil {
+	if err := a.signIn(result.Account.HomeAccountID); err != nil {
 		a.mu.Lock()
 		a.status = AuthStatusError
-		a.lastErr = err
+		if a.lastErr == nil {
+			a.lastErr = err
+		}
 		a.mu.Unlock()
 		return err
 	}
-
-	mcAuth, err := account.GetMinecraftAccount()
-	if err != nil {
-		a.mu.Lock()
-		a.status = AuthStatusError
-		a.lastErr = err
-		a.mu.Unlock()
-		return err
-	}
-
-	profile, err := mcAuth.GetMinecraftProfile()
-	if err != nil {
-		_ = a.Logout()
-		a.mu.Lock()
-		a.status = AuthStatusError
-		a.lastErr = errors.New(i18n.T("minecraft_not_owned"))
-		a.mu.Unlock()
-		return err
-	}
-
-	a.mu.Lock()
-	a.user = profile.Username
-	a.mcProfile = profile
-	a.account = account
-	a.status = AuthStatusLoggedIn
-	a.mu.Unlock()
-
 	return nil
 }
 
@@ -174,17 +226,177 @@ func (a *msaAuthenticator) GetMinecraftAccount() (*msa.MinecraftAccount, error)
 	return a.account, nil
 }
 
-func (a *msaAuthenticator) Logout() error {
+func (a *msaAuthenticator) GetMinecraftAccountFor(accountID string) (*msa.MinecraftAccount, error) {
+	if accountID == "" || accountID == a.ActiveAccountID() {
+		if account, err := a.GetMinecraftAccount(); err == nil {
+			return account, nil
+		}
+		// Signed in offline; try again now that the network may be back
+		accountID = a.ActiveAccountID()
+	}
+	if _, ok := a.store.Get(accountID); !ok {
+		return nil, fmt.Errorf("%w: %s", ErrAccountNotFound, accountID)
+	}
+	account, err := msa.NewMinecraftAccount(a.session, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign in with account %s: %w", accountID, err)
+	}
+	return account, nil
+}
+
+func (a *msaAuthenticator) LaunchCredentials(accountID string, offline bool) (*LaunchCredentials, error) {
+	if accountID == "" {
+		accountID = a.ActiveAccountID()
+		if accountID == "" {
+			// Nobody signed in during this run, as with offline launches; use the account that was active last
+			accountID = a.store.Active()
+		}
+		if accountID == "" {
+			return nil, fmt.Errorf("not logged in")
+		}
+	}
+	stored, ok := a.store.Get(accountID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrAccountNotFound, accountID)
+	}
+
+	if !offline {
+		creds, err := a.onlineCredentials(stored)
+		if err == nil {
+			return creds, nil
+		}
+		if !resource.IsNetworkError(err) {
+			return nil, err
+		}
+		slog.Warn("Minecraft services are unreachable, launching offline", "account", stored.Username, "error", err)
+	}
+
+	if stored.UUID == uuid.Nil {
+		return nil, fmt.Errorf("no profile cached for %s, sign in online once to play offline", stored.Username)
+	}
+	if stored.AccessToken == "" || stored.ExpiresAt.Before(time.Now()) {
+		slog.Info("Cached Minecraft token is missing or expired, online features will be unavailable", "account", stored.Username)
+	}
+	// The token may be empty; Offline tells the game to start without one
+	return &LaunchCredentials{
+		Profile:     stored.Profile(),
+		AccessToken: stored.AccessToken,
+		ExpiresAt:   stored.ExpiresAt,
+		Offline:     true,
+	}, nil
+}
+
+// onlineCredentials signs in to Minecraft services and caches the profile and token for offline use.
+func (a *msaAuthenticator) onlineCredentials(stored msa.StoredAccount) (*LaunchCredentials, error) {
+	account, err := a.GetMinecraftAccountFor(stored.ID)
+	if err != nil {
+		return nil, err
+	}
+	mcAuth, err := account.GetMinecraftAccount()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get minecraft account: %w", err)
+	}
+	profile, err := mcAuth.GetMinecraftProfile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get minecraft profile: %w", err)
+	}
+
+	stored.Username = profile.Username
+	stored.UUID = profile.UUID
+	stored.LastUsed = time.Now()
+	stored.AccessToken = mcAuth.AccessToken
+	stored.ExpiresAt = time.Now().Add(time.Duration(mcAuth.ExpiresIn) * time.Second)
+	if err := a.store.Put(stored); err != nil {
+		slog.Warn("Failed to cache minecraft profile", "error", err)
+	}
+
+	return &LaunchCredentials{
+		Profile:     profile,
+		AccessToken: mcAuth.AccessToken,
+		ExpiresAt:   stored.ExpiresAt,
+	}, nil
+}
+
+func (a *msaAuthenticator) Accounts() []msa.StoredAccount {
+	return a.store.List()
+}
+
+func (a *msaAuthenticator) ActiveAccountID() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.accountID
+}
+
+func (a *msaAuthenticator) SwitchAccount(ctx context.Context, accountID string) error {
+	if _, ok := a.store.Get(accountID); !ok {
+		return fmt.Errorf("%w: %s", ErrAccountNotFound, accountID)
+	}
 	a.mu.Lock()
-	defer a.mu.Unlock()
+	prevStatus := a.status
+	a.status = AuthStatusLoggingIn
+	a.lastErr = nil
+	a.mu.Unlock()
+	if err := a.signIn(accountID); err != nil {
+		a.mu.Lock()
+		if a.account != nil {
+			// Stay signed in with the previous account
+			a.status = prevStatus
+			a.lastErr = err
+		}
+		a.mu.Unlock()
+		return err
+	}
+	return nil
+}
 
-	if err := a.session.Logout(); err != nil {
+func (a *msaAuthenticator) RemoveAccount(accountID string) error {
+	if err := a.session.RemoveAccount(accountID); err != nil {
+		return err
+	}
+	if err := a.store.Remove(accountID); err != nil {
 		return err
 	}
 
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.accountID == accountID {
+		a.user = ""
+		a.accountID = ""
+		a.mcProfile = nil
+		a.account = nil
+		a.status = AuthStatusLoggedOut
+	}
+	return nil
+}
+
+// Logout signs out of the active account. If other accounts remain, the most recently used one becomes active.
+func (a *msaAuthenticator) Logout() error {
+	accountID := a.ActiveAccountID()
+	if accountID == "" {
+		accountID = a.store.Active()
+	}
+	if accountID != "" {
+		if err := a.RemoveAccount(accountID); err != nil {
+			return err
+		}
+	}
+
+	a.mu.Lock()
 	a.user = ""
+	a.accountID = ""
 	a.mcProfile = nil
+	a.account = nil
 	a.status = AuthStatusLoggedOut
+	a.mu.Unlock()
+
+	remaining := a.store.List()
+	if len(remaining) == 0 {
+		return nil
+	}
+	next := slices.MaxFunc(remaining, func(x, y msa.StoredAccount) int { return x.LastUsed.Compare(y.LastUsed) })
+	if err := a.SwitchAccount(context.Background(), next.ID); err != nil {
+		slog.Warn("Failed to switch to remaining account", "account", next.Username, "error", err)
+	}
 	return nil
 }
 
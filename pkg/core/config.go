@@ -2,12 +2,22 @@ package core
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+
+	"github.com/ikafly144/sabalauncher/v2/pkg/msa"
+	"github.com/ikafly144/sabalauncher/v2/pkg/resource"
 )
 
 type LauncherConfig struct {
 	MaxMemory uint64 `json:"max_memory"`
+	// Mirror rewrites upstream URLs, see resource.MirrorProfile.
+	Mirror *resource.MirrorProfile `json:"mirror,omitempty"`
+	// Network configures the proxy, timeouts and limits of the HTTP client, see resource.NetworkConfig.
+	Network *resource.NetworkConfig `json:"network,omitempty"`
+	// DownloadConcurrency is the number of files downloaded at once. Zero uses the default.
+	DownloadConcurrency int `json:"download_concurrency,omitempty"`
 }
 
 func DefaultConfig() *LauncherConfig {
@@ -30,6 +40,9 @@ func LoadConfig(dataPath string) (*LauncherConfig, error) {
 	if err := json.Unmarshal(data, config); err != nil {
 		return nil, err
 	}
+	if err := config.Mirror.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid mirror profile: %w", err)
+	}
 	return config, nil
 }
 
@@ -44,3 +57,20 @@ func (c *LauncherConfig) Save(dataPath string) error {
 	}
 	return os.WriteFile(configPath, data, 0644)
 }
+
+// ApplyNetwork installs the mirror profile, the download concurrency and an HTTP client built from the
+// network settings for every upstream request, including sign-in. Call it before creating sessions or
+// starting downloads.
+func (c *LauncherConfig) ApplyNetwork() error {
+	client, err := resource.NewHTTPClient(c.Network)
+	if err != nil {
+		return fmt.Errorf("invalid network settings: %w", err)
+	}
+	resource.SetHTTPClient(client)
+	resource.SetMirrorProfile(c.Mirror)
+	msa.HTTPClient = client
+	if c.DownloadConcurrency > 0 {
+		resource.DefaultDownloadConcurrency = c.DownloadConcurrency
+	}
+	return nil
+}
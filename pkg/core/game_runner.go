@@ -2,6 +2,7 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -75,29 +76,67 @@ func (r *gameRunner) Launch(instanceID uuid.UUID, options *LaunchOptions) error
 		return err
 	}
 
-	account, err := r.auth.GetMinecraftAccount()
+	offline := options != nil && options.Offline
+	creds, err := r.auth.LaunchCredentials(inst.Account, offline)
+	if errors.Is(err, ErrAccountNotFound) {
+		slog.Warn("Instance account is no longer signed in, using the active account", "instance", inst.Name, "account", inst.Account)
+		r.notificationChan <- NotificationEvent{
+			Title:    i18n.T("account_missing_title"),
+			Message:  i18n.T("account_missing_msg"),
+			Duration: 5 * time.Second,
+		}
+		creds, err = r.auth.LaunchCredentials("", offline)
+	}
 	if err != nil {
 		return err
 	}
+	if creds.Offline && !offline {
+		r.notificationChan <- NotificationEvent{
+			Title:    i18n.T("offline_launch_title"),
+			Message:  i18n.T("offline_launch_msg"),
+			Duration: 5 * time.Second,
+		}
+	}
 
 	// 1. Start Setup
-	state := resource.SetupInstance(r.dataPath, inst)
+	var state *resource.SetupState
+	if creds.Offline {
+		state = resource.SetupInstanceOffline(ctx, r.dataPath, inst)
+	} else {
+		state = resource.SetupInstance(ctx, r.dataPath, inst)
+	}
 
 	// 2. Monitor Progress
 	ticker := time.NewTicker(100 * time.Millisecond)
 	defer ticker.Stop()
 
+	// Steps run side by side, so each running step gets a bar of its own next to the overall progress.
+	shown := map[string]bool{}
 	for !state.IsDone() {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
 		case <-ticker.C:
+			status := fmt.Sprintf("%.1f%%", state.CurrentProgress()*100.0)
+			if stats, ok := state.DownloadStats(); ok && stats.DoneBytes > 0 {
+				status = formatDownloadStats(stats)
+			}
 			r.progressChan <- ProgressEvent{
 				TaskName:   state.FriendlyName(),
 				Percentage: float64(state.Progress()) * 100.0,
-				Status:     fmt.Sprintf("%.1f%%", state.CurrentProgress()*100.0),
+				Status:     status,
 				IsFinished: false,
 			}
+			for _, step := range state.StepStatuses() {
+				if event, ok := stepProgressEvent(step, shown); ok {
+					r.progressChan <- event
+				}
+			}
+		}
+	}
+	for _, step := range state.StepStatuses() {
+		if shown[step.Name] {
+			r.progressChan <- ProgressEvent{TaskName: step.FriendlyName, Percentage: 100.0, IsFinished: true, Category: ProgressCategoryDownload}
 		}
 	}
 
@@ -120,7 +159,7 @@ func (r *gameRunner) Launch(instanceID uuid.UUID, options *LaunchOptions) error
 
 	features := map[string]bool{
 		"is_demo_user":          false,
-		"has_custom_resolution": true,
+		"has_custom_resolution": inst.LaunchSettings == nil || !inst.LaunchSettings.Fullscreen,
 	}
 
 	if options != nil {
@@ -173,24 +212,22 @@ func (r *gameRunner) Launch(instanceID uuid.UUID, options *LaunchOptions) error
 		}
 	}
 
-	manifest, err := resource.GetClientManifestForInstance(inst)
+	manifest, err := resource.GetClientManifestForInstance(inst, creds.Offline)
 	if err != nil {
 		return fmt.Errorf("failed to get client manifest: %w", err)
 	}
 
-	javaPath, err := resource.GetJavaExecutablePath(manifest.JavaVersion.Component, "C:\\")
-	if err != nil {
-		return fmt.Errorf("failed to get java executable path: %w", err)
-	}
-
-	mcAccount, err := account.GetMinecraftAccount()
-	if err != nil {
-		return fmt.Errorf("failed to get minecraft account: %w", err)
-	}
-
-	profile, err := mcAccount.GetMinecraftProfile()
-	if err != nil {
-		return fmt.Errorf("failed to get minecraft profile: %w", err)
+	var javaPath string
+	if inst.LaunchSettings != nil && inst.LaunchSettings.JavaPath != "" {
+		javaPath = inst.LaunchSettings.JavaPath
+		if _, err := os.Stat(javaPath); err != nil {
+			return fmt.Errorf("failed to find custom java executable: %w", err)
+		}
+	} else {
+		javaPath, err = resource.GetJavaExecutablePath(manifest.JavaVersion.Component, r.dataPath)
+		if err != nil {
+			return fmt.Errorf("failed to get java executable path: %w", err)
+		}
 	}
 
 	start := time.Now()
@@ -228,7 +265,7 @@ func (r *gameRunner) Launch(instanceID uuid.UUID, options *LaunchOptions) error
 		}
 	}()
 
-	err = resource.BootGameFromConfig(ctx, javaPath, config, manifest, inst, profile, mcAccount.AccessToken, r.logFile, r.logFile)
+	err = resource.BootGameFromConfig(ctx, javaPath, config, manifest, inst, creds.Profile, creds.AccessToken, creds.Offline, r.logFile, r.logFile)
 	duration := time.Since(start)
 
 	// Update final playtime
@@ -279,3 +316,57 @@ func (r *gameRunner) GetLogReader() (io.ReadCloser, error) {
 	}
 	return os.Open(r.logFile.Name())
 }
+
+// stepProgressEvent returns the event for the bar of a setup step. shown tracks the steps that have a bar, so a
+// finished step sends a final event that removes its bar once.
+func stepProgressEvent(step resource.StepStatus, shown map[string]bool) (ProgressEvent, bool) {
+	event := ProgressEvent{
+		TaskName:   step.FriendlyName,
+		Percentage: float64(step.Progress) * 100.0,
+		Category:   ProgressCategoryDownload,
+	}
+	switch {
+	case step.Running:
+		shown[step.Name] = true
+		event.Status = fmt.Sprintf("%.1f%%", step.Progress*100.0)
+		if step.Download != nil && step.Download.DoneBytes > 0 {
+			event.Status = formatDownloadStats(*step.Download)
+		}
+		return event, true
+	case shown[step.Name]:
+		delete(shown, step.Name)
+		event.Percentage = 100.0
+		event.IsFinished = true
+		return event, true
+	}
+	return ProgressEvent{}, false
+}
+
+// formatDownloadStats describes download progress as "12.0 MB / 48.0 MB, 2.0 MB/s, 18s left".
+func formatDownloadStats(stats resource.DownloadStats) string {
+	var b strings.Builder
+	b.WriteString(formatBytes(stats.DoneBytes))
+	if stats.TotalBytes > 0 {
+		b.WriteString(" / " + formatBytes(stats.TotalBytes))
+	}
+	if stats.BytesPerSecond > 0 {
+		b.WriteString(", " + formatBytes(int64(stats.BytesPerSecond)) + "/s")
+	}
+	if stats.ETA > 0 {
+		b.WriteString(", " + stats.ETA.Round(time.Second).String() + " left")
+	}
+	return b.String()
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value, exp := float64(n)/unit, 0
+	for value >= unit && exp < 3 {
+		value /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", value, "KMGT"[exp])
+}
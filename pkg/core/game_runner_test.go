@@ -2,6 +2,7 @@ package core
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/ikafly144/sabalauncher/v2/pkg/msa"
@@ -17,6 +18,14 @@ func (m *mockAuth) GetMinecraftAccount() (*msa.MinecraftAccount, error) {
 	return &msa.MinecraftAccount{}, nil
 }
 
+func (m *mockAuth) GetMinecraftAccountFor(accountID string) (*msa.MinecraftAccount, error) {
+	return &msa.MinecraftAccount{}, nil
+}
+
+func (m *mockAuth) LaunchCredentials(accountID string, offline bool) (*LaunchCredentials, error) {
+	return &LaunchCredentials{Profile: &msa.MinecraftProfile{}, Offline: offline}, nil
+}
+
 type mockInstanceManager struct {
 	InstanceManager
 	inst *resource.Instance
@@ -51,3 +60,19 @@ func TestGameRunner_LaunchFlow(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatDownloadStats(t *testing.T) {
+	tests := []struct {
+		stats resource.DownloadStats
+		want  string
+	}{
+		{resource.DownloadStats{DoneBytes: 512}, "512 B"},
+		{resource.DownloadStats{DoneBytes: 12 << 20, TotalBytes: 48 << 20, BytesPerSecond: 2 << 20, ETA: 18 * time.Second}, "12.0 MB / 48.0 MB, 2.0 MB/s, 18s left"},
+		{resource.DownloadStats{DoneBytes: 3 << 30, BytesPerSecond: 1536}, "3.0 GB, 1.5 KB/s"},
+	}
+	for _, tt := range tests {
+		if got := formatDownloadStats(tt.stats); got != tt.want {
+			t.Errorf("formatDownloadStats(%+v) = %q, want %q", tt.stats, got, tt.want)
+		}
+	}
+}
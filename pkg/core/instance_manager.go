@@ -21,6 +21,11 @@ type instanceManager struct {
 	instances    []*resource.Instance
 	progressChan chan ProgressEvent
 	mu           sync.RWMutex
+	// gcMu is held for reading while an import links store blobs into an instance that is not registered yet,
+	// and for writing while the shared store is garbage collected. resource.LockStore does the same across processes.
+	gcMu sync.RWMutex
+	// background tracks file removal and store cleanup running after an operation has returned.
+	background sync.WaitGroup
 }
 
 func NewInstanceManager(dataDir string) (InstanceManager, error) {
@@ -43,6 +48,10 @@ func (im *instanceManager) SubscribeProgress() <-chan ProgressEvent {
 	return im.progressChan
 }
 
+func (im *instanceManager) Wait() {
+	im.background.Wait()
+}
+
 func (im *instanceManager) GetInstances() ([]*resource.Instance, error) {
 	im.mu.RLock()
 	defer im.mu.RUnlock()
@@ -61,9 +70,30 @@ func (im *instanceManager) GetInstance(id uuid.UUID) (*resource.Instance, error)
 }
 
 func (im *instanceManager) ImportInstance(ctx context.Context, packPath string) error {
+	im.gcMu.RLock()
+	defer im.gcMu.RUnlock()
+	unlock, err := resource.LockStore()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	uid := uuid.New()
 	destDir := filepath.Join(im.dataDir, "instances", uid.String())
-	inst, err := resource.ImportSBPack(ctx, packPath, destDir, uid, nil)
+	observer := &progressBridge{ch: im.progressChan}
+
+	var inst *resource.Instance
+	var manual []resource.ManualDownload
+	switch strings.ToLower(filepath.Ext(packPath)) {
+	case ".mrpack":
+		inst, err = resource.ImportMRPack(ctx, packPath, destDir, uid, observer)
+	case ".sbpack":
+		inst, err = resource.ImportSBPack(ctx, packPath, destDir, uid, observer)
+	case ".zip":
+		inst, manual, err = resource.ImportCurseForgePack(ctx, packPath, destDir, uid, observer)
+	default:
+		return fmt.Errorf("unsupported file format: %s (expected .sbpack, .mrpack or a CurseForge .zip)", filepath.Base(packPath))
+	}
 	if err != nil {
 		_ = os.RemoveAll(destDir)
 		return err
@@ -73,10 +103,24 @@ func (im *instanceManager) ImportInstance(ctx context.Context, packPath string)
 	im.instances = append(im.instances, inst)
 	im.mu.Unlock()
 
-	return im.saveInstances()
+	if err := im.saveInstances(); err != nil {
+		return err
+	}
+	if len(manual) > 0 {
+		return &resource.ManualDownloadError{Files: manual}
+	}
+	return nil
 }
 
 func (im *instanceManager) AddRemoteInstance(ctx context.Context, manifestURL string) error {
+	im.gcMu.RLock()
+	defer im.gcMu.RUnlock()
+	unlock, err := resource.LockStore()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	uid := uuid.New()
 	destDir := filepath.Join(im.dataDir, "instances", uid.String())
 
@@ -94,7 +138,14 @@ func (im *instanceManager) AddRemoteInstance(ctx context.Context, manifestURL st
 	im.instances = append(im.instances, inst)
 	im.mu.Unlock()
 
-	return im.saveInstances()
+	if err := im.saveInstances(); err != nil {
+		return err
+	}
+	// The patches applied on top of the base pack are journaled like any update; the saved instance supersedes them
+	if err := resource.ClearUpdateJournal(inst.Path); err != nil {
+		slog.Warn("Failed to remove update journal", "instance", inst.Name, "error", err)
+	}
+	return nil
 }
 
 type progressBridge struct {
@@ -116,21 +167,15 @@ func (im *instanceManager) CheckUpdate(ctx context.Context, instanceID uuid.UUID
 		return false, err
 	}
 
-	if inst.Upstream == nil || inst.Upstream.ManifestURL == "" {
+	if inst.Upstream == nil || inst.Upstream.ManifestURL == "" || inst.Upstream.Pinned {
 		return false, nil
 	}
 
-	repo, err := resource.FetchRepository(ctx, inst.Upstream.ManifestURL)
+	available, err := resource.CheckRemoteUpdate(ctx, inst)
 	if err != nil {
 		return false, fmt.Errorf("failed to fetch repository manifest: %w", err)
 	}
-
-	if len(repo.Patches) == 0 {
-		return false, nil
-	}
-
-	latestPatchID := repo.Patches[len(repo.Patches)-1].ID
-	return inst.Upstream.Version != latestPatchID, nil
+	return available, nil
 }
 
 func (im *instanceManager) RepairInstance(ctx context.Context, instanceID uuid.UUID) error {
@@ -142,6 +187,10 @@ func (im *instanceManager) RepairInstance(ctx context.Context, instanceID uuid.U
 	return resource.RepairInstance(ctx, inst, &progressBridge{ch: im.progressChan})
 }
 
+func (im *instanceManager) VerifyGameFiles(ctx context.Context) (*resource.VerifyReport, error) {
+	return resource.VerifyGameFiles(ctx, im.dataDir, &progressBridge{ch: im.progressChan})
+}
+
 func (im *instanceManager) SaveInstance(inst *resource.Instance) error {
 	im.mu.Lock()
 	defer im.mu.Unlock()
@@ -155,7 +204,83 @@ func (im *instanceManager) SaveInstance(inst *resource.Instance) error {
 	return fmt.Errorf("instance not found: %s", inst.UID)
 }
 
+func (im *instanceManager) ListVersions(ctx context.Context, instanceID uuid.UUID) ([]resource.RemoteVersion, error) {
+	inst, err := im.GetInstance(instanceID)
+	if err != nil {
+		return nil, err
+	}
+	return resource.ListRemoteVersions(ctx, inst)
+}
+
+func (im *instanceManager) ResolveUpdate(ctx context.Context, instanceID uuid.UUID, version string) ([]resource.SBRepoPatch, error) {
+	inst, err := im.GetInstance(instanceID)
+	if err != nil {
+		return nil, err
+	}
+	return resource.ResolveRemoteUpdate(ctx, inst, version)
+}
+
+func (im *instanceManager) SetPinned(instanceID uuid.UUID, pinned bool) error {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	for _, inst := range im.instances {
+		if inst.UID == instanceID {
+			if inst.Upstream == nil || inst.Upstream.ManifestURL == "" {
+				return fmt.Errorf("instance does not have a remote manifest")
+			}
+			inst.Upstream.Pinned = pinned
+			return im.saveInstances()
+		}
+	}
+	return fmt.Errorf("instance not found: %s", instanceID)
+}
+
+func (im *instanceManager) SetChannel(instanceID uuid.UUID, channel string) error {
+	if !resource.IsValidChannel(channel) {
+		return fmt.Errorf("unknown channel: %s", channel)
+	}
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	for _, inst := range im.instances {
+		if inst.UID == instanceID {
+			if inst.Upstream == nil || inst.Upstream.ManifestURL == "" {
+				return fmt.Errorf("instance does not have a remote manifest")
+			}
+			inst.Upstream.Channel = channel
+			return im.saveInstances()
+		}
+	}
+	return fmt.Errorf("instance not found: %s", instanceID)
+}
+
 func (im *instanceManager) UpdateInstance(ctx context.Context, instanceID uuid.UUID, path string) error {
+	return im.updateInstance(ctx, instanceID, func(inst *resource.Instance, observer resource.ProgressObserver) error {
+		if path == "" {
+			// Remote update
+			if inst.Upstream == nil || inst.Upstream.ManifestURL == "" {
+				return fmt.Errorf("instance does not have a remote manifest, please provide a patch file")
+			}
+			return resource.UpdateInstanceRemote(ctx, inst, observer)
+		} else if strings.HasSuffix(strings.ToLower(path), ".sbpatch") {
+			return resource.ApplySBPatch(ctx, inst, path, observer)
+		} else if strings.HasSuffix(strings.ToLower(path), ".sbpack") {
+			return resource.ApplySBPack(ctx, inst, path, observer)
+		}
+		return fmt.Errorf("unsupported file format: %s (expected .sbpack or .sbpatch)", filepath.Base(path))
+	})
+}
+
+func (im *instanceManager) UpdateInstanceTo(ctx context.Context, instanceID uuid.UUID, version string) error {
+	return im.updateInstance(ctx, instanceID, func(inst *resource.Instance, observer resource.ProgressObserver) error {
+		return resource.UpdateInstanceRemoteTo(ctx, inst, version, observer)
+	})
+}
+
+// updateInstance runs apply on the instance and records the state it ended up in, whether apply succeeded or not.
+// A failed update is settled through the journal; a successful one already left the instance in its final state.
+func (im *instanceManager) updateInstance(ctx context.Context, instanceID uuid.UUID, apply func(inst *resource.Instance, observer resource.ProgressObserver) error) error {
 	im.mu.Lock()
 	defer im.mu.Unlock()
 
@@ -171,22 +296,26 @@ func (im *instanceManager) UpdateInstance(ctx context.Context, instanceID uuid.U
 		return fmt.Errorf("instance not found: %s", instanceID)
 	}
 
-	var err error
-	observer := &progressBridge{ch: im.progressChan}
+	// The update links blobs before the new sb.index.json lists them
+	unlock, err := resource.LockStore()
+	if err != nil {
+		return err
+	}
+	err = apply(targetInst, &progressBridge{ch: im.progressChan})
+	unlock()
 
-	if path == "" {
-		// Remote update
-		if targetInst.Upstream == nil || targetInst.Upstream.ManifestURL == "" {
-			err = fmt.Errorf("instance does not have a remote manifest, please provide a patch file")
-		} else {
-			err = resource.UpdateInstanceRemote(ctx, targetInst, observer)
+	// A remote update applies several steps; after a failure the journal tells which state the instance ended up in
+	if err != nil {
+		if _, jerr := resource.RecoverInstanceUpdate(targetInst); jerr != nil {
+			slog.Error("Failed to settle update journal", "instance", targetInst.Name, "error", jerr)
+			return fmt.Errorf("update failed: %w", err)
 		}
-	} else if strings.HasSuffix(strings.ToLower(path), ".sbpatch") {
-		err = resource.ApplySBPatch(ctx, targetInst, path, observer)
-	} else if strings.HasSuffix(strings.ToLower(path), ".sbpack") {
-		err = resource.ApplySBPack(ctx, targetInst, path, observer)
-	} else {
-		err = fmt.Errorf("unsupported file format: %s (expected .sbpack or .sbpatch)", filepath.Base(path))
+	}
+	if saveErr := im.saveInstances(); saveErr != nil {
+		return saveErr
+	}
+	if cerr := resource.ClearUpdateJournal(targetInst.Path); cerr != nil {
+		slog.Warn("Failed to remove update journal", "instance", targetInst.Name, "error", cerr)
 	}
 
 	if err != nil {
@@ -197,7 +326,10 @@ func (im *instanceManager) UpdateInstance(ctx context.Context, instanceID uuid.U
 		return err
 	}
 
-	return im.saveInstances()
+	// Files dropped by the update may leave unreferenced blobs behind
+	im.background.Go(im.collectStoreGarbage)
+
+	return nil
 }
 
 func (im *instanceManager) DeleteInstance(instanceID uuid.UUID) error {
@@ -207,14 +339,17 @@ func (im *instanceManager) DeleteInstance(instanceID uuid.UUID) error {
 	found := false
 	for i, inst := range im.instances {
 		if inst.UID == instanceID {
+			im.instances = append(im.instances[:i], im.instances[i+1:]...)
+
 			// Delete files from disk
-			go func() {
+			im.background.Go(func() {
 				if err := os.RemoveAll(inst.Path); err != nil {
 					slog.Error("Failed to delete instance files", "error", err)
+					return
 				}
-			}()
+				im.collectStoreGarbage()
+			})
 
-			im.instances = append(im.instances[:i], im.instances[i+1:]...)
 			found = true
 			break
 		}
@@ -247,8 +382,20 @@ func (im *instanceManager) RefreshInstances() error {
 		return err
 	}
 
+	recovered := []*resource.Instance{}
 	for _, inst := range instances {
 		inst.Path = filepath.Join(im.dataDir, "instances", inst.UID.String())
+
+		// Finish updates interrupted by a crash or power loss
+		changed, err := resource.RecoverInstanceUpdate(inst)
+		if err != nil {
+			slog.Error("Failed to recover interrupted update", "instance", inst.Name, "error", err)
+			continue
+		}
+		if changed {
+			slog.Warn("Recovered interrupted update", "instance", inst.Name)
+			recovered = append(recovered, inst)
+		}
 	}
 
 	sort.SliceStable(instances, func(i, j int) bool {
@@ -256,18 +403,70 @@ func (im *instanceManager) RefreshInstances() error {
 	})
 
 	im.instances = instances
+
+	if len(recovered) > 0 {
+		if err := im.saveInstances(); err != nil {
+			return err
+		}
+		for _, inst := range recovered {
+			if err := resource.ClearUpdateJournal(inst.Path); err != nil {
+				slog.Warn("Failed to remove update journal", "instance", inst.Name, "error", err)
+			}
+		}
+	}
 	return nil
 }
 
+// collectStoreGarbage drops blobs from the shared store that no instance on disk refers to. Instances are listed
+// from disk rather than from memory, so instances added by another process keep their blobs.
+// It waits for running imports, whose blobs are not referenced by an instance yet.
+func (im *instanceManager) collectStoreGarbage() {
+	im.gcMu.Lock()
+	defer im.gcMu.Unlock()
+
+	entries, err := os.ReadDir(filepath.Join(im.dataDir, "instances"))
+	if err != nil && !os.IsNotExist(err) {
+		slog.Error("Failed to list instances for store garbage collection", "error", err)
+		return
+	}
+	var dirs []string
+	for _, e := range entries {
+		if e.IsDir() {
+			dirs = append(dirs, filepath.Join(im.dataDir, "instances", e.Name()))
+		}
+	}
+
+	removed, freed, err := resource.GarbageCollectStore(dirs)
+	if err != nil {
+		slog.Error("Failed to collect shared store garbage", "error", err)
+		return
+	}
+	if removed > 0 {
+		slog.Info("Collected shared store garbage", "blobs", removed, "bytes", freed)
+	}
+}
+
+// saveInstances writes instances.json through a temporary file, so a crash never leaves it truncated.
 func (im *instanceManager) saveInstances() error {
 	path := filepath.Join(im.dataDir, "instances.json")
-	file, err := os.Create(path)
+	tmpPath := path + ".tmp"
+	file, err := os.Create(tmpPath)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
 
 	encoder := json.NewEncoder(file)
 	encoder.SetIndent("", "  ")
-	return encoder.Encode(im.instances)
+	if err := encoder.Encode(im.instances); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
 }
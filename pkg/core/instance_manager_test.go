@@ -0,0 +1,260 @@
+package core
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ikafly144/sabalauncher/v2/pkg/resource"
+)
+
+// testRepo serves a remote repository whose versions are added with addPack and addPatch.
+type testRepo struct {
+	t      *testing.T
+	server *httptest.Server
+	mu     sync.Mutex
+	repo   resource.SBRepository
+	files  map[string][]byte
+	lastID uuid.UUID
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	r := &testRepo{t: t, repo: resource.SBRepository{Name: "Remote Pack"}, files: map[string][]byte{}}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if req.URL.Path == "/manifest.json" {
+			_ = json.NewEncoder(w).Encode(r.repo)
+			return
+		}
+		data, ok := r.files[req.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+func (r *testRepo) manifestURL() string {
+	return r.server.URL + "/manifest.json"
+}
+
+func (r *testRepo) index(id uuid.UUID) resource.SBPackIndex {
+	return resource.SBPackIndex{
+		FormatVersion: resource.SBPackFormatVersion,
+		Name:          "Remote Pack",
+		ID:            id,
+		Dependencies:  map[string]string{"minecraft": "1.20.1"},
+	}
+}
+
+// add publishes an entry named version without an index_id, so the instance is known by the entry ID only.
+func (r *testRepo) add(version string, typ resource.SBPatchType, name string, meta any) {
+	r.t.Helper()
+	data, err := json.Marshal(meta)
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	path := filepath.Join(r.t.TempDir(), "entry.zip")
+	f, err := os.Create(path)
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	w, err := zw.Create(name)
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	_, _ = w.Write(data)
+	zw.Close()
+	f.Close()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	sum := sha256.Sum256(content)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	remote := "/" + version + "." + string(typ)
+	r.files[remote] = content
+	r.repo.Patches = append(r.repo.Patches, resource.SBRepoPatch{
+		ID:         version,
+		Type:       typ,
+		Hash:       map[string]string{"sha256": hex.EncodeToString(sum[:])},
+		RemotePath: r.server.URL + remote,
+		Timestamp:  int64(len(r.repo.Patches) + 1),
+	})
+}
+
+func (r *testRepo) addPack(version string) {
+	r.lastID = uuid.New()
+	r.add(version, resource.SBPatchTypePack, "sb.index.json", r.index(r.lastID))
+}
+
+func (r *testRepo) addPatch(version string) {
+	base := r.lastID
+	r.lastID = uuid.New()
+	r.add(version, resource.SBPatchTypePatch, "sb.patch.json", resource.SBPatch{
+		FormatVersion: resource.SBPatchFormatVersion,
+		BaseID:        base,
+		Index:         r.index(r.lastID),
+	})
+}
+
+func newTestInstanceManager(t *testing.T, dataDir string) *instanceManager {
+	t.Helper()
+	manager, err := NewInstanceManager(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	im := manager.(*instanceManager)
+	go func() {
+		for range im.progressChan {
+		}
+	}()
+	return im
+}
+
+func setupRemoteInstanceTest(t *testing.T) (*testRepo, string) {
+	oldDataDir := resource.DataDir
+	resource.DataDir = t.TempDir()
+	t.Cleanup(func() { resource.DataDir = oldDataDir })
+
+	repo := newTestRepo(t)
+	repo.addPack("1.0.0")
+	repo.addPatch("1.1.0")
+	return repo, t.TempDir()
+}
+
+func TestAddRemoteInstanceKeepsSettingsAcrossRestart(t *testing.T) {
+	repo, dataDir := setupRemoteInstanceTest(t)
+
+	im := newTestInstanceManager(t, dataDir)
+	if err := im.AddRemoteInstance(context.Background(), repo.manifestURL()); err != nil {
+		t.Fatalf("AddRemoteInstance failed: %v", err)
+	}
+	instances, _ := im.GetInstances()
+	if len(instances) != 1 {
+		t.Fatalf("expected one instance, got %d", len(instances))
+	}
+	inst := instances[0]
+	if inst.Upstream.Version != "1.1.0" {
+		t.Fatalf("expected version 1.1.0, got %s", inst.Upstream.Version)
+	}
+
+	inst.Account = "account"
+	inst.Upstream.Channel = resource.ChannelBeta
+	inst.LaunchSettings = &resource.LaunchSettings{JVMArgs: []string{"-Dfoo=bar"}}
+	if err := im.SaveInstance(inst); err != nil {
+		t.Fatal(err)
+	}
+
+	restarted := newTestInstanceManager(t, dataDir)
+	got, err := restarted.GetInstance(inst.UID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Upstream.Version != "1.1.0" {
+		t.Errorf("expected version 1.1.0 after restart, got %s", got.Upstream.Version)
+	}
+	if got.Account != "account" || got.Upstream.Channel != resource.ChannelBeta || got.LaunchSettings == nil {
+		t.Errorf("settings were lost after restart: account=%q channel=%q launch=%v", got.Account, got.Upstream.Channel, got.LaunchSettings)
+	}
+}
+
+func TestUpdateInstanceKeepsRepositoryVersion(t *testing.T) {
+	repo, dataDir := setupRemoteInstanceTest(t)
+
+	im := newTestInstanceManager(t, dataDir)
+	if err := im.AddRemoteInstance(context.Background(), repo.manifestURL()); err != nil {
+		t.Fatalf("AddRemoteInstance failed: %v", err)
+	}
+	instances, _ := im.GetInstances()
+	uid := instances[0].UID
+
+	repo.addPatch("1.2.0")
+	if err := im.UpdateInstance(context.Background(), uid, ""); err != nil {
+		t.Fatalf("UpdateInstance failed: %v", err)
+	}
+	inst, _ := im.GetInstance(uid)
+	if inst.Upstream.Version != "1.2.0" {
+		t.Errorf("expected version 1.2.0, got %s", inst.Upstream.Version)
+	}
+	if available, err := im.CheckUpdate(context.Background(), uid); err != nil || available {
+		t.Errorf("CheckUpdate = %v, %v, want no update", available, err)
+	}
+
+	restarted := newTestInstanceManager(t, dataDir)
+	inst, _ = restarted.GetInstance(uid)
+	if inst.Upstream.Version != "1.2.0" {
+		t.Errorf("expected version 1.2.0 after restart, got %s", inst.Upstream.Version)
+	}
+}
+
+// publish moves the entry named version to channel.
+func (r *testRepo) publish(version, channel string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.repo.Patches {
+		if r.repo.Patches[i].ID == version {
+			r.repo.Patches[i].Channel = channel
+		}
+	}
+}
+
+func TestUpdateInstanceDoesNotRollBackAfterChannelSwitch(t *testing.T) {
+	repo, dataDir := setupRemoteInstanceTest(t)
+	repo.addPatch("1.2.0")
+	repo.publish("1.2.0", resource.ChannelBeta)
+
+	im := newTestInstanceManager(t, dataDir)
+	if err := im.AddRemoteInstance(context.Background(), repo.manifestURL()); err != nil {
+		t.Fatalf("AddRemoteInstance failed: %v", err)
+	}
+	instances, _ := im.GetInstances()
+	uid := instances[0].UID
+	if err := im.SetChannel(uid, resource.ChannelBeta); err != nil {
+		t.Fatal(err)
+	}
+	if err := im.UpdateInstance(context.Background(), uid, ""); err != nil {
+		t.Fatalf("UpdateInstance failed: %v", err)
+	}
+	if inst, _ := im.GetInstance(uid); inst.Upstream.Version != "1.2.0" {
+		t.Fatalf("expected beta version 1.2.0, got %s", inst.Upstream.Version)
+	}
+
+	// Back on stable, the newest stable version 1.1.0 is older than the installed beta
+	if err := im.SetChannel(uid, resource.ChannelStable); err != nil {
+		t.Fatal(err)
+	}
+	if available, err := im.CheckUpdate(context.Background(), uid); err != nil || available {
+		t.Errorf("CheckUpdate = %v, %v, want no update", available, err)
+	}
+	if err := im.UpdateInstance(context.Background(), uid, ""); err != nil {
+		t.Fatalf("UpdateInstance failed: %v", err)
+	}
+	if inst, _ := im.GetInstance(uid); inst.Upstream.Version != "1.2.0" {
+		t.Errorf("update moved the instance back to %s", inst.Upstream.Version)
+	}
+
+	// Rolling back has to be asked for
+	if err := im.UpdateInstanceTo(context.Background(), uid, "1.1.0"); err != nil {
+		t.Fatalf("UpdateInstanceTo failed: %v", err)
+	}
+	if inst, _ := im.GetInstance(uid); inst.Upstream.Version != "1.1.0" {
+		t.Errorf("expected version 1.1.0 after the explicit rollback, got %s", inst.Upstream.Version)
+	}
+}
@@ -3,6 +3,7 @@ package core
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/ikafly144/sabalauncher/v2/pkg/msa"
@@ -24,7 +25,8 @@ type Authenticator interface {
 	// TrySilentLogin attempts to login using cached credentials.
 	TrySilentLogin(ctx context.Context) error
 	// Login starts the interactive login process.
-	Login(ctx context.Context, method msa.LoginMethod) error // Logout clears the current session.
+	Login(ctx context.Context, method msa.LoginMethod) error
+	// Logout signs out of the active account and switches to another signed-in account if there is one.
 	Logout() error
 	// GetStatus returns the current authentication status.
 	GetStatus() AuthStatus
@@ -34,6 +36,20 @@ type Authenticator interface {
 	GetMinecraftProfile() (*msa.MinecraftProfile, error)
 	// GetMinecraftAccount returns the raw Minecraft account object.
 	GetMinecraftAccount() (*msa.MinecraftAccount, error)
+	// GetMinecraftAccountFor returns the Minecraft account for a signed-in account without switching to it.
+	// An empty ID means the active account.
+	GetMinecraftAccountFor(accountID string) (*msa.MinecraftAccount, error)
+	// LaunchCredentials returns the profile and token to launch the game with an account (the active one if empty).
+	// When offline is set or Minecraft services cannot be reached, the last cached profile and token are used.
+	LaunchCredentials(accountID string, offline bool) (*LaunchCredentials, error)
+	// Accounts returns all signed-in accounts.
+	Accounts() []msa.StoredAccount
+	// ActiveAccountID returns the ID of the active account, or an empty string if none is signed in.
+	ActiveAccountID() string
+	// SwitchAccount makes another signed-in account the active one.
+	SwitchAccount(ctx context.Context, accountID string) error
+	// RemoveAccount signs out of an account and removes its cached tokens.
+	RemoveAccount(accountID string) error
 	// DeviceCode returns the device code information for the user to login.
 	// This should only be called when status is AuthStatusLoggingIn.
 	DeviceCode() (url, code string)
@@ -45,6 +61,16 @@ type Authenticator interface {
 	GetLastError() error
 }
 
+// LaunchCredentials identifies the player to the game.
+type LaunchCredentials struct {
+	Profile *msa.MinecraftProfile
+	// AccessToken is empty when launching offline without a cached token.
+	AccessToken string
+	ExpiresAt   time.Time
+	// Offline reports that the credentials come from the cache and may no longer be valid online.
+	Offline bool
+}
+
 // InstanceManager defines the interface for managing game instances.
 type InstanceManager interface {
 	// GetInstances returns the list of all available instances.
@@ -55,20 +81,41 @@ type InstanceManager interface {
 	RefreshInstances() error
 	// GetInstance returns a specific instance.
 	GetInstance(id uuid.UUID) (*resource.Instance, error)
-	// ImportInstance imports a modpack from an .sbpack file.
+	// ImportInstance imports a modpack from an .sbpack, Modrinth .mrpack or CurseForge .zip file.
+	// If the instance was imported but some files must be downloaded by the user, a *resource.ManualDownloadError is returned.
 	ImportInstance(ctx context.Context, packPath string) error
 	// AddRemoteInstance registers a remote modpack repository.
 	AddRemoteInstance(ctx context.Context, manifestURL string) error
 	// UpdateInstance updates an instance using an .sbpatch file.
 	UpdateInstance(ctx context.Context, instanceID uuid.UUID, patchPath string) error
-	// CheckUpdate checks if a remote update is available for the instance.
+	// UpdateInstanceTo updates a remote instance to the given version of its repository. Older versions roll the
+	// instance back.
+	UpdateInstanceTo(ctx context.Context, instanceID uuid.UUID, version string) error
+	// ResolveUpdate returns the repository entries, in order, that UpdateInstanceTo applies to bring a remote instance
+	// to version, or to the latest version of its channel if version is empty. Their changelogs describe the update.
+	ResolveUpdate(ctx context.Context, instanceID uuid.UUID, version string) ([]resource.SBRepoPatch, error)
+	// ListVersions returns the versions of the remote repository of the instance, oldest first.
+	ListVersions(ctx context.Context, instanceID uuid.UUID) ([]resource.RemoteVersion, error)
+	// SetPinned pins a remote instance to its installed version, or unpins it.
+	SetPinned(instanceID uuid.UUID, pinned bool) error
+	// SetChannel subscribes a remote instance to a release channel. The next update moves it to the latest version
+	// of that channel.
+	SetChannel(instanceID uuid.UUID, channel string) error
+	// CheckUpdate checks if a remote update is available for the instance in the channel it follows. Pinned
+	// instances never have one.
 	CheckUpdate(ctx context.Context, instanceID uuid.UUID) (bool, error)
 	// RepairInstance verifies and repairs instance files.
 	RepairInstance(ctx context.Context, instanceID uuid.UUID) error
+	// VerifyGameFiles checks the assets, libraries, client jars and Java runtimes shared by all instances
+	// and downloads missing or corrupt files again.
+	VerifyGameFiles(ctx context.Context) (*resource.VerifyReport, error)
 	// SaveInstance saves the current state of an instance.
 	SaveInstance(inst *resource.Instance) error
 	// SubscribeProgress returns a channel that receives progress updates.
 	SubscribeProgress() <-chan ProgressEvent
+	// Wait blocks until background work, such as removing the files of deleted instances and cleaning up the
+	// shared store, has finished.
+	Wait()
 }
 
 // LaunchOptions contains options for game launch.
@@ -76,6 +123,8 @@ type LaunchOptions struct {
 	QuickPlayMultiplayer  string
 	QuickPlaySingleplayer string
 	MemoryMB              uint64
+	// Offline launches with the cached profile and the files already on disk, without using the network.
+	Offline bool
 }
 
 // GameRunner defines the interface for launching and managing the game process.
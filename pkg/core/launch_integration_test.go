@@ -74,7 +74,7 @@ func TestModLoaders_FullLifecycle(t *testing.T) {
 			slog.Info("Starting setup for", "loader", tc.name)
 
 			// 1. Run SetupInstance (Real download/install)
-			state := resource.SetupInstance(tempDir, inst)
+			state := resource.SetupInstance(context.Background(), tempDir, inst)
 
 			// Monitor progress until done or timeout
 			timeout := time.After(10 * time.Minute) // Installers can be slow
@@ -126,9 +126,9 @@ func TestModLoaders_FullLifecycle(t *testing.T) {
 			defer cancel()
 
 			var stdout, stderr strings.Builder
-			manifest, _ := resource.GetClientManifestForInstance(inst)
+			manifest, _ := resource.GetClientManifestForInstance(inst, false)
 
-			err = resource.BootGameFromConfig(ctx, javaPath, config, manifest, inst, profile, accessToken, &stdout, &stderr)
+			err = resource.BootGameFromConfig(ctx, javaPath, config, manifest, inst, profile, accessToken, false, &stdout, &stderr)
 
 			// We don't strictly require err == nil because the game might exit with error code
 			// if it can't initialize graphics, but we want to see if it ATTEMPTED to boot
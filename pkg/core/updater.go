@@ -9,9 +9,11 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/google/go-github/v88/github"
+	"github.com/ikafly144/sabalauncher/v2/pkg/resource"
 )
 
 const (
@@ -19,6 +21,16 @@ const (
 	RepoName  = "sabalauncher"
 )
 
+// updateRepository returns the repository updates are fetched from, which the mirror profile can override.
+func updateRepository() (owner, name string) {
+	if p := resource.CurrentMirrorProfile(); p != nil && p.UpdateRepository != "" {
+		if owner, name, ok := strings.Cut(p.UpdateRepository, "/"); ok && owner != "" && name != "" {
+			return owner, name
+		}
+	}
+	return RepoOwner, RepoName
+}
+
 type UpdateInfo struct {
 	Version      string
 	DownloadURL  string
@@ -36,11 +48,12 @@ func CheckForUpdate(currentVersionStr string) (*UpdateInfo, error) {
 		return nil, fmt.Errorf("invalid current version %s: %w", currentVersionStr, err)
 	}
 
-	client, err := github.NewClient()
+	client, err := github.NewClient(github.WithHTTPClient(resource.HTTPClient()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create GitHub client: %w", err)
 	}
-	release, _, err := client.Repositories.GetLatestRelease(context.Background(), RepoOwner, RepoName)
+	owner, name := updateRepository()
+	release, _, err := client.Repositories.GetLatestRelease(context.Background(), owner, name)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch latest release: %w", err)
 	}
@@ -80,7 +93,7 @@ func CheckForUpdate(currentVersionStr string) (*UpdateInfo, error) {
 
 func DownloadAndRunInstaller(downloadURL string, onProgress func(percentage float64)) error {
 	slog.Info("Downloading installer", "url", downloadURL)
-	resp, err := http.Get(downloadURL)
+	resp, err := resource.MirrorGet(downloadURL)
 	if err != nil {
 		return fmt.Errorf("failed to download installer: %w", err)
 	}
@@ -51,23 +51,68 @@ var dictEN = map[string]string{
 	"memory_limit_body":               "To ensure system stability, memory allocation has been limited to 80%% of physical memory.\nRequested: %d MB -> Capped: %d MB",
 	"username_label":                  "Username: %s",
 	"uuid_label":                      "UUID: %s",
+	"switch_account_label":            "Account",
+	"add_account_btn":                 "Add Account",
+	"instance_account_title":          "Launch Account",
+	"instance_account_label":          "Account",
+	"instance_account_active":         "Current account",
+	"save":                            "Save",
+	"account_missing_title":           "Account Not Found",
+	"account_missing_msg":             "The account set for this instance is no longer signed in. Launching with the current account.",
+	"instance_settings_title":         "Launch Settings",
+	"instance_settings_jvm_args":      "Extra JVM arguments",
+	"instance_settings_gc":            "Garbage collector",
+	"instance_settings_gc_default":    "Default (tuned G1)",
+	"instance_settings_gc_none":       "None (JVM default)",
+	"instance_settings_java":          "Java executable",
+	"instance_settings_java_auto":     "Managed by the launcher",
+	"instance_settings_width":         "Window width",
+	"instance_settings_height":        "Window height",
+	"instance_settings_fullscreen":    "Fullscreen",
+	"instance_settings_env":           "Environment variables",
+	"instance_settings_pre_launch":    "Pre-launch command",
+	"instance_settings_post_exit":     "Post-exit command",
 
 	// launch overlay
 	"preparing": "Preparing...",
 	"stop_btn":  "STOP",
 
 	// import.go
-	"importing_progress":    "Importing...",
-	"cancel":                "Cancel",
-	"yes":                   "Yes",
-	"no":                    "No",
-	"registering_progress":  "Registering...",
-	"register_remote_title": "Register Remote Modpack",
-	"register_btn":          "Register",
-	"manifest_url_label":    "Manifest URL",
-	"updating_progress":     "Updating...",
-	"repairing_progress":    "Repairing...",
-	"downloading_update":    "Downloading Update",
+	"importing_progress":             "Importing...",
+	"cancel":                         "Cancel",
+	"yes":                            "Yes",
+	"no":                             "No",
+	"registering_progress":           "Registering...",
+	"register_remote_title":          "Register Remote Modpack",
+	"register_btn":                   "Register",
+	"manifest_url_label":             "Manifest URL",
+	"updating_progress":              "Updating...",
+	"select_version_btn":             "Select Version",
+	"select_version_title":           "Select Version",
+	"version_select_label":           "Version",
+	"version_latest":                 "%s - latest",
+	"version_installed":              "%s - installed",
+	"version_pinned":                 "%s (pinned)",
+	"channel_select_label":           "Channel",
+	"channel_stable":                 "Stable",
+	"channel_beta":                   "Beta",
+	"channel_dev":                    "Development",
+	"pin_version_check":              "Pin this version",
+	"pin_version_hint":               "A pinned instance is not updated before play until you unpin it. Selecting an older version rolls the instance back.",
+	"no_versions_available":          "The repository does not list any versions.",
+	"apply":                          "Apply",
+	"update_changelog_title":         "Release Notes",
+	"no_changelog":                   "No release notes.",
+	"repairing_progress":             "Repairing...",
+	"verify_game_files_btn":          "Verify Game Files",
+	"verifying_progress":             "Verifying...",
+	"verify_game_files_result_title": "Verification Complete",
+	"verify_game_files_result_body":  "Checked %d file(s).\nRepaired: %d\nCould not repair: %d",
+	"verify_game_files_unrepairable": "Reinstall the mod loader of the affected instances to restore these files:\n%s",
+	"downloading_update":             "Downloading Update",
+	"manual_download_title":          "Manual Download Required",
+	"manual_download_body":           "The modpack was imported, but the authors of %d file(s) do not allow third-party downloads.\nDownload them from CurseForge and place them in the instance folder as shown below.",
+	"close":                          "Close",
 
 	// updater.go
 	"update_available_title":  "Update Available",
@@ -86,6 +131,12 @@ var dictEN = map[string]string{
 	"setup_mods":          "Download Mods",
 	"starting_game":       "Starting Game...",
 
+	// verify.go
+	"verify_files":   "Verifying Game Files",
+	"verify_assets":  "Verifying Assets",
+	"verify_runtime": "Verifying Java Runtime (%s)",
+	"verify_done":    "Verification Complete",
+
 	// versions.go
 	"playing_state":   "Playing %s",
 	"playing_details": "Playing Minecraft",
@@ -93,6 +144,10 @@ var dictEN = map[string]string{
 	"playtime_milestone_title": "Playtime Milestone!",
 	"playtime_milestone_msg":   "You've played for %d hours!",
 
+	"play_offline":         "Play Offline",
+	"offline_launch_title": "Playing Offline",
+	"offline_launch_msg":   "Minecraft services could not be reached. Launching with the files already downloaded; multiplayer on online-mode servers is unavailable.",
+
 	"systray_show": "Show",
 	"systray_quit": "Quit",
 }
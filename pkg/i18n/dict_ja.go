@@ -51,23 +51,68 @@ var dictJA = map[string]string{
 	"memory_limit_body":               "システム安定のため、割り当てメモリを搭載メモリの80%%に制限しました。\n予定: %d MB -> 制限後: %d MB",
 	"username_label":                  "ユーザー名: %s",
 	"uuid_label":                      "UUID: %s",
+	"switch_account_label":            "アカウント",
+	"add_account_btn":                 "アカウントを追加",
+	"instance_account_title":          "起動アカウント",
+	"instance_account_label":          "アカウント",
+	"instance_account_active":         "現在のアカウント",
+	"save":                            "保存",
+	"account_missing_title":           "アカウントが見つかりません",
+	"account_missing_msg":             "このインスタンスに設定されたアカウントはログアウトされています。現在のアカウントで起動します。",
+	"instance_settings_title":         "起動設定",
+	"instance_settings_jvm_args":      "追加のJVM引数",
+	"instance_settings_gc":            "ガベージコレクタ",
+	"instance_settings_gc_default":    "デフォルト (調整済みG1)",
+	"instance_settings_gc_none":       "なし (JVMの既定)",
+	"instance_settings_java":          "Java実行ファイル",
+	"instance_settings_java_auto":     "ランチャーが管理",
+	"instance_settings_width":         "ウィンドウの幅",
+	"instance_settings_height":        "ウィンドウの高さ",
+	"instance_settings_fullscreen":    "フルスクリーン",
+	"instance_settings_env":           "環境変数",
+	"instance_settings_pre_launch":    "起動前コマンド",
+	"instance_settings_post_exit":     "終了後コマンド",
 
 	// launch overlay
 	"preparing": "準備中...",
 	"stop_btn":  "停止",
 
 	// import.go
-	"importing_progress":    "インポート中...",
-	"cancel":                "キャンセル",
-	"yes":                   "はい",
-	"no":                    "いいえ",
-	"registering_progress":  "登録中...",
-	"register_remote_title": "リモートModpackの登録",
-	"register_btn":          "登録",
-	"manifest_url_label":    "マニフェストURL",
-	"updating_progress":     "アップデート中...",
-	"repairing_progress":    "修復中...",
-	"downloading_update":    "アップデートをダウンロード中",
+	"importing_progress":             "インポート中...",
+	"cancel":                         "キャンセル",
+	"yes":                            "はい",
+	"no":                             "いいえ",
+	"registering_progress":           "登録中...",
+	"register_remote_title":          "リモートModpackの登録",
+	"register_btn":                   "登録",
+	"manifest_url_label":             "マニフェストURL",
+	"updating_progress":              "アップデート中...",
+	"select_version_btn":             "バージョンを選択",
+	"select_version_title":           "バージョンを選択",
+	"version_select_label":           "バージョン",
+	"version_latest":                 "%s - 最新",
+	"version_installed":              "%s - インストール済み",
+	"version_pinned":                 "%s (固定中)",
+	"channel_select_label":           "チャンネル",
+	"channel_stable":                 "安定版",
+	"channel_beta":                   "ベータ版",
+	"channel_dev":                    "開発版",
+	"pin_version_check":              "このバージョンに固定する",
+	"pin_version_hint":               "固定したインスタンスは、固定を解除するまでプレイ前にアップデートされません。古いバージョンを選択するとロールバックします。",
+	"no_versions_available":          "リポジトリにバージョンがありません。",
+	"apply":                          "適用",
+	"update_changelog_title":         "リリースノート",
+	"no_changelog":                   "リリースノートはありません。",
+	"repairing_progress":             "修復中...",
+	"verify_game_files_btn":          "ゲームファイルを検証",
+	"verifying_progress":             "検証中...",
+	"verify_game_files_result_title": "検証完了",
+	"verify_game_files_result_body":  "%d 個のファイルを検証しました。\n修復: %d\n修復不可: %d",
+	"verify_game_files_unrepairable": "次のファイルを復元するには、該当するインスタンスのMod Loaderを再インストールしてください:\n%s",
+	"downloading_update":             "アップデートをダウンロード中",
+	"manual_download_title":          "手動ダウンロードが必要です",
+	"manual_download_body":           "Modpackはインポートされましたが、%d 個のファイルは作者が外部からのダウンロードを許可していません。\nCurseForgeからダウンロードし、以下のとおりインスタンスフォルダに配置してください。",
+	"close":                          "閉じる",
 
 	// updater.go
 	"update_available_title":  "アップデート利用可能",
@@ -86,6 +131,12 @@ var dictJA = map[string]string{
 	"setup_mods":          "Modのダウンロード",
 	"starting_game":       "ゲームを起動中...",
 
+	// verify.go
+	"verify_files":   "ゲームファイルの検証",
+	"verify_assets":  "アセットの検証",
+	"verify_runtime": "Javaランタイムの検証 (%s)",
+	"verify_done":    "検証完了",
+
 	// versions.go
 	"playing_state":   "%sをプレイ中",
 	"playing_details": "SabaLauncherでプレイ中",
@@ -93,6 +144,10 @@ var dictJA = map[string]string{
 	"playtime_milestone_title": "プレイ時間のマイルストーン達成！",
 	"playtime_milestone_msg":   "累計プレイ時間が %d 時間に達しました！",
 
+	"play_offline":         "オフラインでプレイ",
+	"offline_launch_title": "オフラインでプレイ",
+	"offline_launch_msg":   "Minecraftのサービスに接続できませんでした。ダウンロード済みのファイルで起動します。オンラインモードのサーバーには参加できません。",
+
 	"systray_show": "表示",
 	"systray_quit": "終了",
 }
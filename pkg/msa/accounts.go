@@ -0,0 +1,149 @@
+package msa
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StoredAccount describes a Microsoft account signed in to the launcher and the Minecraft profile it owns.
+type StoredAccount struct {
+	// ID is the MSAL home account ID, which identifies the account in the token cache.
+	ID       string    `json:"id"`
+	Username string    `json:"username"`
+	UUID     uuid.UUID `json:"uuid"`
+	LastUsed time.Time `json:"last_used"`
+	// AccessToken and ExpiresAt hold the last Minecraft access token, used to launch the game offline.
+	AccessToken string    `json:"access_token,omitempty"`
+	ExpiresAt   time.Time `json:"expires_at,omitzero"`
+}
+
+// Profile returns the Minecraft profile last seen for the account.
+func (a StoredAccount) Profile() *MinecraftProfile {
+	return &MinecraftProfile{Username: a.Username, UUID: a.UUID}
+}
+
+type accountStoreData struct {
+	Active   string          `json:"active"`
+	Accounts []StoredAccount `json:"accounts"`
+}
+
+// AccountStore keeps track of the accounts whose tokens live in the MSAL cache and which of them is active,
+// along with the last Minecraft profile and token of each account. It is encrypted like the cache.
+type AccountStore struct {
+	path string
+	data accountStoreData
+	mu   sync.Mutex
+}
+
+// NewAccountStore opens the account store kept next to the token cache of c.
+func NewAccountStore(c *CacheAccessor) (*AccountStore, error) {
+	if c.path == "" {
+		return nil, fmt.Errorf("cache path is not set")
+	}
+	s := &AccountStore{path: c.path + ".accounts.json"}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("failed to read account store: %w", err)
+	}
+	if decrypted, err := decrypt(data); err == nil {
+		data = decrypted
+	} else {
+		slog.Warn("Failed to decrypt account store, attempting to use as-is", "error", err)
+	}
+	if err := json.Unmarshal(data, &s.data); err != nil {
+		return nil, fmt.Errorf("failed to parse account store: %w", err)
+	}
+	return s, nil
+}
+
+// List returns all stored accounts ordered by username.
+func (s *AccountStore) List() []StoredAccount {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	accounts := slices.Clone(s.data.Accounts)
+	slices.SortFunc(accounts, func(a, b StoredAccount) int {
+		return strings.Compare(strings.ToLower(a.Username), strings.ToLower(b.Username))
+	})
+	return accounts
+}
+
+// Get returns the stored account with the given ID.
+func (s *AccountStore) Get(id string) (StoredAccount, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, a := range s.data.Accounts {
+		if a.ID == id {
+			return a, true
+		}
+	}
+	return StoredAccount{}, false
+}
+
+// Active returns the ID of the active account, or an empty string if none is active.
+func (s *AccountStore) Active() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data.Active
+}
+
+// Put adds an account or updates the stored profile of an existing one.
+func (s *AccountStore) Put(account StoredAccount) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := slices.IndexFunc(s.data.Accounts, func(a StoredAccount) bool { return a.ID == account.ID })
+	if i >= 0 {
+		s.data.Accounts[i] = account
+	} else {
+		s.data.Accounts = append(s.data.Accounts, account)
+	}
+	return s.save()
+}
+
+// SetActive marks an account as the one in use.
+func (s *AccountStore) SetActive(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Active = id
+	return s.save()
+}
+
+// Remove forgets an account. If it was active, no account is active afterwards.
+func (s *AccountStore) Remove(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Accounts = slices.DeleteFunc(s.data.Accounts, func(a StoredAccount) bool { return a.ID == id })
+	if s.data.Active == id {
+		s.data.Active = ""
+	}
+	return s.save()
+}
+
+// save writes the store through a temporary file. The caller must hold s.mu.
+func (s *AccountStore) save() error {
+	data, err := json.Marshal(s.data)
+	if err != nil {
+		return err
+	}
+	data, err = encrypt(data)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt account store: %w", err)
+	}
+	_ = os.MkdirAll(filepath.Dir(s.path), 0755)
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write account store: %w", err)
+	}
+	return os.Rename(tmpPath, s.path)
+}
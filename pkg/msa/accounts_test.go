@@ -0,0 +1,56 @@
+package msa
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestAccountStore(t *testing.T) {
+	cache, err := NewCacheAccessor(filepath.Join(t.TempDir(), "msa_cache"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewAccountStore(cache)
+	if err != nil {
+		t.Fatalf("NewAccountStore failed: %v", err)
+	}
+
+	bob := StoredAccount{ID: "bob.tenant", Username: "bob", UUID: uuid.New()}
+	alice := StoredAccount{ID: "alice.tenant", Username: "Alice", UUID: uuid.New()}
+	for _, a := range []StoredAccount{bob, alice} {
+		if err := store.Put(a); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+	}
+	if err := store.SetActive(bob.ID); err != nil {
+		t.Fatal(err)
+	}
+	bob.Username = "Bob"
+	if err := store.Put(bob); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := NewAccountStore(cache)
+	if err != nil {
+		t.Fatalf("failed to reopen store: %v", err)
+	}
+	list := reopened.List()
+	if len(list) != 2 || list[0].ID != alice.ID || list[1].Username != "Bob" {
+		t.Errorf("unexpected accounts: %+v", list)
+	}
+	if reopened.Active() != bob.ID {
+		t.Errorf("expected active account %s, got %s", bob.ID, reopened.Active())
+	}
+
+	if err := reopened.Remove(bob.ID); err != nil {
+		t.Fatal(err)
+	}
+	if reopened.Active() != "" {
+		t.Error("expected no active account after removing it")
+	}
+	if _, ok := reopened.Get(bob.ID); ok {
+		t.Error("expected removed account to be gone")
+	}
+}
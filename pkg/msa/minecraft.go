@@ -8,6 +8,7 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"slices"
 	"time"
 
 	"github.com/AzureAD/microsoft-authentication-library-for-go/apps/public"
@@ -88,7 +89,7 @@ type MinecraftAccountAuthResult struct {
 }
 
 func (m *MinecraftAccountAuthResult) GetMinecraftProfile() (*MinecraftProfile, error) {
-	httpClient := http.DefaultClient
+	httpClient := HTTPClient
 	req, err := http.NewRequest(http.MethodGet, MinecraftProfileURL, nil)
 	if err != nil {
 		return nil, err
@@ -114,33 +115,32 @@ func (m *MinecraftAccountAuthResult) GetMinecraftProfile() (*MinecraftProfile, e
 	return &mcProfile, nil
 }
 
-func NewMinecraftAccount(s Session) (*MinecraftAccount, error) {
+// NewMinecraftAccount signs in to Xbox Live with the cached account identified by accountID.
+// If accountID is empty, the only account in the cache is used.
+func NewMinecraftAccount(s Session, accountID string) (*MinecraftAccount, error) {
 	session := s.(*session)
 	accounts, err := session.client.Accounts(context.Background())
 	if err != nil {
 		return nil, fmt.Errorf("アカウントの取得に失敗しました: %w", err)
 	}
-	if len(accounts) > 1 {
-		var err error
-		for _, account := range accounts {
-			e := session.client.RemoveAccount(context.Background(), account)
-			if e != nil {
-				slog.Error("Failed to remove account", "account", account, "error", e)
-				err = e
-			} else {
-				slog.Info("Removed account", "account", account)
-			}
-		}
-		if err != nil {
-			slog.Error("Failed to remove multiple accounts", "error", err)
-		}
-		return nil, fmt.Errorf("複数のアカウントが見つかりました。再ログインしてください。: %v", accounts)
-	}
 	if len(accounts) == 0 {
 		return nil, fmt.Errorf("アカウントが見つかりません。ログインしてください")
 	}
-	slog.Info("Already logged in with account", "account", accounts[0])
-	result, err := session.client.AcquireTokenSilent(context.Background(), []string{"XboxLive.signin", "XboxLive.offline_access"}, public.WithSilentAccount(accounts[0]))
+	var account public.Account
+	if accountID == "" {
+		if len(accounts) > 1 {
+			return nil, fmt.Errorf("複数のアカウントが見つかりました。アカウントを選択してください")
+		}
+		account = accounts[0]
+	} else {
+		i := slices.IndexFunc(accounts, func(a public.Account) bool { return a.HomeAccountID == accountID })
+		if i < 0 {
+			return nil, fmt.Errorf("アカウントが見つかりません。ログインしてください: %s", accountID)
+		}
+		account = accounts[i]
+	}
+	slog.Info("Already logged in with account", "account", account)
+	result, err := session.client.AcquireTokenSilent(context.Background(), []string{"XboxLive.signin", "XboxLive.offline_access"}, public.WithSilentAccount(account))
 	if err != nil {
 		slog.Warn("Failed to acquire token silently", "error", err)
 		return nil, fmt.Errorf("failed to acquire token silently: %w", err)
@@ -156,7 +156,7 @@ func NewMinecraftAccount(s Session) (*MinecraftAccount, error) {
 	if expiresIn.Before(time.Now()) {
 		return nil, fmt.Errorf("expiresIn is before now")
 	}
-	httpClient := http.DefaultClient
+	httpClient := HTTPClient
 
 	req := xblAuthReqest{
 		Properties: xblAuthProperties{
@@ -217,7 +217,7 @@ type MinecraftProfile struct {
 }
 
 func (m *MinecraftAccount) GetMinecraftAccount() (*MinecraftAccountAuthResult, error) {
-	httpClient := http.DefaultClient
+	httpClient := HTTPClient
 
 	xstsReq := xstsRequest{
 		Properties: xstsProperties{
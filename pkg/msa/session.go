@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"net/http"
 	"os"
 	"path/filepath"
 	"slices"
@@ -17,6 +18,9 @@ import (
 
 var ClientID string
 
+// HTTPClient is used for every Microsoft, Xbox Live and Minecraft services request.
+var HTTPClient = http.DefaultClient
+
 type LoginMethod string
 
 const (
@@ -29,6 +33,10 @@ type Session interface {
 	DeviceCode() *public.DeviceCode
 	InteractiveURL() string
 	AuthResult() (*public.AuthResult, error)
+	// Accounts returns the accounts held in the token cache.
+	Accounts() ([]public.Account, error)
+	// RemoveAccount removes the account with the given home account ID and its tokens from the cache.
+	RemoveAccount(accountID string) error
 	Logout() error
 	impl()
 }
@@ -111,7 +119,7 @@ func (c *CacheAccessor) Replace(ctx context.Context, cache cache.Unmarshaler, hi
 }
 
 func NewSession(c *CacheAccessor) (Session, error) {
-	client, err := public.New(ClientID, public.WithAuthority(MicrosoftAuthorityURL), public.WithCache(c))
+	client, err := public.New(ClientID, public.WithAuthority(MicrosoftAuthorityURL), public.WithCache(c), public.WithHTTPClient(HTTPClient))
 	if err != nil {
 		return nil, err
 	}
@@ -185,17 +193,6 @@ func (s *session) handleResult(result public.AuthResult) {
 		s.resultError = fmt.Errorf("no accounts found after login")
 		return
 	}
-	if len(accounts) > 1 {
-		for _, account := range accounts {
-			if account.Key() != result.Account.Key() {
-				slog.Info("Multiple accounts found", "account", account)
-				if err := s.client.RemoveAccount(context.Background(), account); err != nil {
-					slog.Error("Failed to remove account", "account", account, "error", err)
-				}
-				break
-			}
-		}
-	}
 	slog.Info("Login successful", "result", result)
 	if len(result.DeclinedScopes) > 0 {
 		slog.Warn("Login with declined scopes", "declinedScopes", result.DeclinedScopes)
@@ -222,6 +219,24 @@ func (s *session) AuthResult() (*public.AuthResult, error) {
 	return s.result, s.resultError
 }
 
+func (s *session) Accounts() ([]public.Account, error) {
+	return s.client.Accounts(context.Background())
+}
+
+func (s *session) RemoveAccount(accountID string) error {
+	accounts, err := s.client.Accounts(context.Background())
+	if err != nil {
+		return err
+	}
+	for _, account := range accounts {
+		if account.HomeAccountID == accountID {
+			return s.client.RemoveAccount(context.Background(), account)
+		}
+	}
+	return nil
+}
+
+// Logout removes every account from the cache and resets the login state.
 func (s *session) Logout() error {
 	accounts, err := s.client.Accounts(context.Background())
 	if err != nil {
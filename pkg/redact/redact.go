@@ -0,0 +1,214 @@
+// Package redact masks access tokens and other secrets before they reach log files or the console.
+package redact
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Mask replaces every redacted value.
+const Mask = "[REDACTED]"
+
+// maxLineLength is the longest line a Writer buffers before writing it out anyway.
+const maxLineLength = 64 << 10
+
+// minSecretLength keeps short values such as the offline access token "0" from masking unrelated text.
+const minSecretLength = 8
+
+var (
+	mu       sync.RWMutex
+	secrets  []string
+	patterns = []*regexp.Regexp{
+		// JSON web tokens, used for MSAL and Minecraft access tokens.
+		regexp.MustCompile(`eyJ[A-Za-z0-9_-]{4,}\.[A-Za-z0-9_-]{4,}\.[A-Za-z0-9_-]*`),
+		// Xbox Live authorization headers.
+		regexp.MustCompile(`XBL3\.0 x=[^;\s]+;[^\s"',]+`),
+	}
+	// flagArg matches a sensitive command line flag followed by its value.
+	flagArg = regexp.MustCompile(`(?i)(--(?:accessToken|xuid|session|refreshToken|apiKey)\s+)(\S+)`)
+	// keyValue matches a sensitive key=value or "key": "value" pair.
+	keyValue = regexp.MustCompile(`(?i)(\b(?:access_?token|auth_access_token|auth_xuid|xuid|refresh_?token|api_?key|x-api-key|client_?secret)["']?\s*[:=]\s*["']?)([^\s"',;&]+)`)
+)
+
+// sensitiveKeys are log attribute keys and command line flags whose values are always masked.
+var sensitiveKeys = []string{
+	"accesstoken", "access_token", "auth_access_token", "auth_xuid", "xuid", "session",
+	"refreshtoken", "refresh_token", "token", "idtoken", "id_token",
+	"apikey", "api_key", "x-api-key", "authorization", "client_secret", "secret", "password",
+}
+
+// Register adds a secret value, such as an access token, to mask wherever it appears.
+// Values shorter than a few characters are ignored.
+func Register(secret string) {
+	secret = strings.TrimSpace(secret)
+	if len(secret) < minSecretLength {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !slices.Contains(secrets, secret) {
+		secrets = append(secrets, secret)
+	}
+}
+
+// RegisterPattern adds a pattern whose matches are masked.
+func RegisterPattern(re *regexp.Regexp) {
+	mu.Lock()
+	defer mu.Unlock()
+	patterns = append(patterns, re)
+}
+
+// IsSensitiveKey reports whether a log attribute key or command line flag names a secret.
+func IsSensitiveKey(key string) bool {
+	key = strings.ToLower(strings.TrimLeft(key, "-"))
+	return slices.Contains(sensitiveKeys, key)
+}
+
+// String masks registered secrets, registered patterns and the values of sensitive flags in s.
+func String(s string) string {
+	if s == "" {
+		return s
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, Mask)
+	}
+	for _, re := range patterns {
+		s = re.ReplaceAllString(s, Mask)
+	}
+	for _, re := range []*regexp.Regexp{flagArg, keyValue} {
+		s = re.ReplaceAllString(s, "${1}"+Mask)
+	}
+	return s
+}
+
+// Args masks the values that follow sensitive flags in a command line, along with anything String masks.
+func Args(args []string) []string {
+	out := make([]string, len(args))
+	for i, arg := range args {
+		if i > 0 && strings.HasPrefix(args[i-1], "-") && IsSensitiveKey(args[i-1]) {
+			out[i] = Mask
+			continue
+		}
+		out[i] = String(arg)
+	}
+	return out
+}
+
+// Attr masks the value of a log attribute.
+func Attr(a slog.Attr) slog.Attr {
+	if IsSensitiveKey(a.Key) {
+		return slog.String(a.Key, Mask)
+	}
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, String(v.String()))
+	case slog.KindGroup:
+		attrs := v.Group()
+		redacted := make([]slog.Attr, len(attrs))
+		for i, ga := range attrs {
+			redacted[i] = Attr(ga)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(redacted...)}
+	case slog.KindAny:
+		switch val := v.Any().(type) {
+		case []string:
+			return slog.Any(a.Key, Args(val))
+		case error:
+			return slog.String(a.Key, String(val.Error()))
+		default:
+			s := fmt.Sprintf("%+v", val)
+			if r := String(s); r != s {
+				return slog.String(a.Key, r)
+			}
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
+
+type handler struct {
+	slog.Handler
+}
+
+// NewHandler returns a handler that masks secrets in the message and attributes of every record before passing it to h.
+func NewHandler(h slog.Handler) slog.Handler {
+	return &handler{Handler: h}
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	redacted := slog.NewRecord(r.Time, r.Level, String(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(Attr(a))
+		return true
+	})
+	return h.Handler.Handle(ctx, redacted)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = Attr(a)
+	}
+	return &handler{Handler: h.Handler.WithAttrs(redacted)}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{Handler: h.Handler.WithGroup(name)}
+}
+
+// Writer masks secrets in text written through it. It buffers until the end of each line,
+// so a secret split across writes is still masked. Call Flush after the last write.
+type Writer struct {
+	w   io.Writer
+	buf []byte
+	mu  sync.Mutex
+}
+
+// NewWriter returns a Writer that writes redacted lines to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		if _, err := io.WriteString(w.w, String(string(w.buf[:i+1]))); err != nil {
+			return len(p), err
+		}
+		w.buf = w.buf[i+1:]
+	}
+	if len(w.buf) > maxLineLength {
+		if _, err := io.WriteString(w.w, String(string(w.buf))); err != nil {
+			return len(p), err
+		}
+		w.buf = nil
+	}
+	return len(p), nil
+}
+
+// Flush writes any incomplete last line.
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.buf) == 0 {
+		return nil
+	}
+	_, err := io.WriteString(w.w, String(string(w.buf)))
+	w.buf = nil
+	return err
+}
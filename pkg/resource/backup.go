@@ -1,34 +1,133 @@
 package resource
 
 import (
+	"bufio"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// Updates are journaled inside the instance directory so that an update interrupted by a crash or
+// power loss can be recovered on the next start. Every apply step appends a "begin" record holding
+// the instance as it was, a "backup" record for each file before it is touched, and finally a
+// "commit" record holding the updated instance (or a "rollback" record if it failed).
+// A remote update applies several steps in a row, so the journal may hold multiple segments;
+// only the last one can be unfinished.
+//
+// The journal is removed by ClearUpdateJournal once the instance list has been saved.
+
+const (
+	updateJournalDir  = ".sbupdate"
+	updateJournalName = "journal.jsonl"
+
+	journalOpBegin    = "begin"
+	journalOpBackup   = "backup"
+	journalOpCommit   = "commit"
+	journalOpRollback = "rollback"
+)
+
+// ErrUnfinishedUpdate is returned when an update is started on an instance whose previous update was interrupted.
+var ErrUnfinishedUpdate = errors.New("instance has an unfinished update, restart the launcher to recover it")
+
+type journalRecord struct {
+	Op   string    `json:"op"`
+	Seq  int       `json:"seq"`
+	Time time.Time `json:"time,omitzero"`
+	// Target is the version being applied (begin)
+	Target string `json:"target,omitempty"`
+	// Path and Existed describe a backed up file (backup)
+	Path    string `json:"path,omitempty"`
+	Existed bool   `json:"existed,omitempty"`
+	// Instance is the instance before (begin) or after (commit) the step
+	Instance *Instance `json:"instance,omitempty"`
+}
+
 type instanceBackup struct {
+	inst      *Instance
+	before    *Instance
 	baseDir   string
 	backupDir string
-	backedUp  map[string]bool
+	seq       int
+	journal   *os.File
+	// backedUp maps the backed up paths to whether the file existed before the step
+	backedUp map[string]bool
+	// committed reports whether an earlier step of the journal is committed and must be kept on rollback.
+	committed bool
 }
 
-func newInstanceBackup(baseDir string) (*instanceBackup, error) {
-	backupDir, err := os.MkdirTemp("", "sb-instance-backup-*")
+func newInstanceBackup(inst *Instance, target string) (*instanceBackup, error) {
+	journalDir := filepath.Join(inst.Path, updateJournalDir)
+	records, err := readUpdateJournal(inst.Path)
 	if err != nil {
+		return nil, err
+	}
+	seq := 0
+	committed := false
+	if len(records) > 0 {
+		last := records[len(records)-1]
+		if last.Op == journalOpBegin || last.Op == journalOpBackup {
+			return nil, ErrUnfinishedUpdate
+		}
+		seq = last.Seq + 1
+		committed = hasCommit(records)
+	}
+
+	backupDir := filepath.Join(journalDir, "backup", strconv.Itoa(seq))
+	if err := os.MkdirAll(backupDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create backup dir: %w", err)
 	}
-	return &instanceBackup{
-		baseDir:   baseDir,
+	journal, err := os.OpenFile(filepath.Join(journalDir, updateJournalName), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open update journal: %w", err)
+	}
+
+	before, err := cloneInstance(inst)
+	if err != nil {
+		journal.Close()
+		return nil, err
+	}
+	b := &instanceBackup{
+		inst:      inst,
+		before:    before,
+		baseDir:   inst.Path,
 		backupDir: backupDir,
+		seq:       seq,
+		journal:   journal,
 		backedUp:  make(map[string]bool),
-	}, nil
+		committed: committed,
+	}
+	if err := b.append(journalRecord{Op: journalOpBegin, Target: target, Instance: before}); err != nil {
+		journal.Close()
+		return nil, err
+	}
+	return b, nil
+}
+
+// append writes a record to the journal and flushes it to disk.
+func (b *instanceBackup) append(r journalRecord) error {
+	r.Seq = b.seq
+	r.Time = time.Now()
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if _, err := b.journal.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write update journal: %w", err)
+	}
+	return b.journal.Sync()
 }
 
-// Backup records a file or directory for later restoration.
+// Backup records a file for later restoration. It must be called before the file is modified.
 // path is relative to the instance baseDir.
 func (b *instanceBackup) Backup(relPath string) error {
-	if b.backedUp[relPath] {
+	if _, ok := b.backedUp[relPath]; ok {
 		return nil // Already backed up
 	}
 
@@ -36,84 +135,249 @@ func (b *instanceBackup) Backup(relPath string) error {
 	info, err := os.Stat(srcPath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			// File didn't exist before, so "restoring" means deleting it.
-			// We track it by backing up an empty marker or just remembering it wasn't there.
-			// But for simplicity, we'll just track that we touched it.
-			b.backedUp[relPath] = true
+			// File didn't exist before, so restoring means deleting it
+			if err := b.append(journalRecord{Op: journalOpBackup, Path: relPath}); err != nil {
+				return err
+			}
+			b.backedUp[relPath] = false
 			return nil
 		}
 		return err
 	}
 
-	dstPath := filepath.Join(b.backupDir, relPath)
-	if err := os.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
-		return err
-	}
-
 	if info.IsDir() {
 		// We could backup entire directories, but let's stick to files to keep it minimal.
 		// Usually updates touch files. If a dir is removed, its contents will be backed up individually.
 		return nil
 	}
 
-	// Copy file
-	srcFile, err := os.Open(srcPath)
-	if err != nil {
+	if err := copyFileSync(srcPath, filepath.Join(b.backupDir, relPath)); err != nil {
+		return fmt.Errorf("failed to back up %s: %w", relPath, err)
+	}
+	if err := b.append(journalRecord{Op: journalOpBackup, Path: relPath, Existed: true}); err != nil {
 		return err
 	}
-	defer srcFile.Close()
+	b.backedUp[relPath] = true
+	return nil
+}
 
-	dstFile, err := os.Create(dstPath)
+// Commit marks the step as complete. After a crash, the instance is rolled forward to its current state.
+func (b *instanceBackup) Commit() error {
+	after, err := cloneInstance(b.inst)
 	if err != nil {
 		return err
 	}
-	defer dstFile.Close()
+	return b.append(journalRecord{Op: journalOpCommit, Instance: after})
+}
 
-	if _, err := io.Copy(dstFile, srcFile); err != nil {
+// Restore rolls back the files and the instance to the state before the step.
+func (b *instanceBackup) Restore() error {
+	if err := restoreFiles(b.baseDir, b.backupDir, b.backedUp); err != nil {
 		return err
 	}
 
-	b.backedUp[relPath] = true
+	path := b.inst.Path
+	*b.inst = *b.before
+	b.inst.Path = path
+
+	if err := b.append(journalRecord{Op: journalOpRollback}); err != nil {
+		return err
+	}
+	if !b.committed {
+		// Nothing left to roll forward
+		b.journal.Close()
+		return ClearUpdateJournal(b.baseDir)
+	}
 	return nil
 }
 
-func (b *instanceBackup) Restore() error {
-	var lastErr error
-	for relPath := range b.backedUp {
-		srcPath := filepath.Join(b.backupDir, relPath)
-		dstPath := filepath.Join(b.baseDir, relPath)
-
-		if _, err := os.Stat(srcPath); os.IsNotExist(err) {
-			// It didn't exist before, so delete it if it was created
-			_ = os.Remove(dstPath)
-		} else {
-			// Restore from backup
-			if err := os.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
-				lastErr = err
-				continue
-			}
-			srcFile, err := os.Open(srcPath)
-			if err != nil {
-				lastErr = err
-				continue
-			}
-			dstFile, err := os.Create(dstPath)
-			if err != nil {
-				srcFile.Close()
-				lastErr = err
-				continue
+// Cleanup releases the journal and the backups of the step. The journal itself stays until ClearUpdateJournal.
+func (b *instanceBackup) Cleanup() {
+	_ = b.journal.Close()
+	_ = os.RemoveAll(b.backupDir)
+}
+
+// RecoverInstanceUpdate finishes an update of inst left behind in its update journal, rolling back
+// an interrupted step or rolling forward to the last committed one. It reports whether inst was
+// changed; the caller should then persist inst and call ClearUpdateJournal.
+func RecoverInstanceUpdate(inst *Instance) (bool, error) {
+	records, err := readUpdateJournal(inst.Path)
+	if err != nil {
+		return false, err
+	}
+	if len(records) == 0 {
+		return false, nil
+	}
+
+	last := records[len(records)-1]
+	if last.Op == journalOpBegin || last.Op == journalOpBackup {
+		slog.Warn("Rolling back interrupted update", "instance", inst.Name, "step", last.Seq)
+		existed := make(map[string]bool)
+		for _, r := range records {
+			if r.Seq == last.Seq && r.Op == journalOpBackup {
+				existed[r.Path] = r.Existed
 			}
-			_, err = io.Copy(dstFile, srcFile)
-			srcFile.Close()
-			dstFile.Close()
-			if err != nil {
+		}
+		backupDir := filepath.Join(inst.Path, updateJournalDir, "backup", strconv.Itoa(last.Seq))
+		if err := restoreFiles(inst.Path, backupDir, existed); err != nil {
+			return false, fmt.Errorf("failed to roll back interrupted update: %w", err)
+		}
+	}
+
+	snapshot := lastSnapshot(records)
+	if snapshot == nil {
+		return false, nil
+	}
+	path := inst.Path
+	*inst = *snapshot
+	inst.Path = path
+	return true, nil
+}
+
+// ClearUpdateJournal removes the update journal of the instance in dir.
+func ClearUpdateJournal(dir string) error {
+	return os.RemoveAll(filepath.Join(dir, updateJournalDir))
+}
+
+// lastSnapshot returns the state the instance should be in according to the journal:
+// the result of the last committed step, or the state before the last step if it did not commit.
+func lastSnapshot(records []journalRecord) *Instance {
+	if len(records) == 0 {
+		return nil
+	}
+	lastSeq := records[len(records)-1].Seq
+	var snapshot *Instance
+	for _, r := range records {
+		if r.Seq != lastSeq {
+			continue
+		}
+		switch r.Op {
+		case journalOpBegin:
+			snapshot = r.Instance
+		case journalOpCommit:
+			return r.Instance
+		}
+	}
+	return snapshot
+}
+
+func hasCommit(records []journalRecord) bool {
+	for _, r := range records {
+		if r.Op == journalOpCommit {
+			return true
+		}
+	}
+	return false
+}
+
+// readUpdateJournal reads the journal records of the instance in dir.
+// A torn trailing record left by a crash is ignored.
+func readUpdateJournal(dir string) ([]journalRecord, error) {
+	f, err := os.Open(filepath.Join(dir, updateJournalDir, updateJournalName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open update journal: %w", err)
+	}
+	defer f.Close()
+
+	var records []journalRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var r journalRecord
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			slog.Warn("Ignoring corrupt update journal record", "dir", dir, "error", err)
+			break
+		}
+		records = append(records, r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read update journal: %w", err)
+	}
+	return records, nil
+}
+
+// restoreFiles puts back the backed up files of one step. Files that did not exist before are removed.
+func restoreFiles(baseDir, backupDir string, existed map[string]bool) error {
+	var lastErr error
+	for relPath, ok := range existed {
+		dstPath := filepath.Join(baseDir, relPath)
+		if !ok {
+			if err := os.Remove(dstPath); err != nil && !os.IsNotExist(err) {
 				lastErr = err
 			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
+			lastErr = err
+			continue
+		}
+		if err := restoreFile(filepath.Join(backupDir, relPath), dstPath); err != nil {
+			lastErr = err
 		}
 	}
 	return lastErr
 }
 
-func (b *instanceBackup) Cleanup() {
-	_ = os.RemoveAll(b.backupDir)
+func restoreFile(src, dst string) error {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+	dstFile, err := createFile(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dstFile, srcFile); err != nil {
+		dstFile.Close()
+		return err
+	}
+	return dstFile.Close()
+}
+
+// copyFileSync copies src to dst and flushes it to disk.
+func copyFileSync(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+
+	dstFile, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dstFile, srcFile); err != nil {
+		dstFile.Close()
+		return err
+	}
+	if err := dstFile.Sync(); err != nil {
+		dstFile.Close()
+		return err
+	}
+	return dstFile.Close()
+}
+
+// cloneInstance returns a deep copy of inst through its JSON form, the same form used in instances.json.
+func cloneInstance(inst *Instance) (*Instance, error) {
+	data, err := json.Marshal(inst)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot instance: %w", err)
+	}
+	var clone Instance
+	if err := json.Unmarshal(data, &clone); err != nil {
+		return nil, fmt.Errorf("failed to snapshot instance: %w", err)
+	}
+	clone.Path = inst.Path
+	return &clone, nil
 }
@@ -0,0 +1,172 @@
+package resource
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func setupJournalTestInstance(t *testing.T) *Instance {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "config"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config", "a.txt"), []byte("v1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return &Instance{
+		Name:     "Journal",
+		UID:      uuid.New(),
+		Path:     dir,
+		Upstream: &Upstream{Version: "v1"},
+	}
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "<missing>"
+	}
+	return string(b)
+}
+
+// simulateStep backs up and rewrites config/a.txt, creates config/b.txt and bumps the instance version.
+func simulateStep(t *testing.T, inst *Instance, target string, commit bool) {
+	t.Helper()
+	b, err := newInstanceBackup(inst, target)
+	if err != nil {
+		t.Fatalf("newInstanceBackup failed: %v", err)
+	}
+	for _, rel := range []string{"config/a.txt", "config/b.txt"} {
+		if err := b.Backup(rel); err != nil {
+			t.Fatalf("Backup(%s) failed: %v", rel, err)
+		}
+		if err := os.WriteFile(filepath.Join(inst.Path, rel), []byte(target), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	inst.Upstream.Version = target
+	if commit {
+		if err := b.Commit(); err != nil {
+			t.Fatalf("Commit failed: %v", err)
+		}
+		b.Cleanup()
+		return
+	}
+	// Simulate a crash: the process dies without rolling back or cleaning up
+	_ = b.journal.Close()
+}
+
+func TestUpdateJournalRollsBackInterruptedStep(t *testing.T) {
+	inst := setupJournalTestInstance(t)
+	simulateStep(t, inst, "v2", false)
+
+	if _, err := newInstanceBackup(inst, "v3"); err != ErrUnfinishedUpdate {
+		t.Errorf("expected ErrUnfinishedUpdate, got %v", err)
+	}
+
+	// The instance list on disk still describes v1
+	stale := &Instance{Name: "Journal", UID: inst.UID, Path: inst.Path, Upstream: &Upstream{Version: "v1"}}
+	changed, err := RecoverInstanceUpdate(stale)
+	if err != nil {
+		t.Fatalf("RecoverInstanceUpdate failed: %v", err)
+	}
+	if !changed {
+		t.Error("expected recovery to report a change")
+	}
+	if stale.Upstream.Version != "v1" || stale.Path != inst.Path {
+		t.Errorf("unexpected recovered instance: version %s, path %s", stale.Upstream.Version, stale.Path)
+	}
+	if got := readTestFile(t, filepath.Join(inst.Path, "config", "a.txt")); got != "v1" {
+		t.Errorf("expected a.txt to be rolled back, got %q", got)
+	}
+	if got := readTestFile(t, filepath.Join(inst.Path, "config", "b.txt")); got != "<missing>" {
+		t.Errorf("expected b.txt created by the update to be removed, got %q", got)
+	}
+
+	if err := ClearUpdateJournal(inst.Path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(inst.Path, updateJournalDir)); !os.IsNotExist(err) {
+		t.Error("expected journal directory to be removed")
+	}
+}
+
+func TestUpdateJournalRollsForwardCommittedSteps(t *testing.T) {
+	inst := setupJournalTestInstance(t)
+	simulateStep(t, inst, "v2", true)
+	simulateStep(t, inst, "v3", false)
+
+	stale := &Instance{Name: "Journal", UID: inst.UID, Path: inst.Path, Upstream: &Upstream{Version: "v1"}}
+	changed, err := RecoverInstanceUpdate(stale)
+	if err != nil {
+		t.Fatalf("RecoverInstanceUpdate failed: %v", err)
+	}
+	if !changed || stale.Upstream.Version != "v2" {
+		t.Errorf("expected roll forward to v2, got changed=%v version=%s", changed, stale.Upstream.Version)
+	}
+	for _, rel := range []string{"config/a.txt", "config/b.txt"} {
+		if got := readTestFile(t, filepath.Join(inst.Path, rel)); got != "v2" {
+			t.Errorf("expected %s to be at v2, got %q", rel, got)
+		}
+	}
+}
+
+func TestUpdateJournalRestoreKeepsEarlierCommit(t *testing.T) {
+	inst := setupJournalTestInstance(t)
+	simulateStep(t, inst, "v2", true)
+
+	b, err := newInstanceBackup(inst, "v3")
+	if err != nil {
+		t.Fatalf("newInstanceBackup failed: %v", err)
+	}
+	if err := b.Backup("config/a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	_ = os.WriteFile(filepath.Join(inst.Path, "config", "a.txt"), []byte("v3"), 0644)
+	inst.Upstream.Version = "v3"
+	if err := b.Restore(); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	b.Cleanup()
+
+	if inst.Upstream.Version != "v2" {
+		t.Errorf("expected in-memory instance to be restored to v2, got %s", inst.Upstream.Version)
+	}
+	if got := readTestFile(t, filepath.Join(inst.Path, "config", "a.txt")); got != "v2" {
+		t.Errorf("expected a.txt at v2, got %q", got)
+	}
+
+	// The committed first step must still be recoverable until the instance list is saved
+	stale := &Instance{Name: "Journal", UID: inst.UID, Path: inst.Path, Upstream: &Upstream{Version: "v1"}}
+	if changed, err := RecoverInstanceUpdate(stale); err != nil || !changed || stale.Upstream.Version != "v2" {
+		t.Errorf("expected roll forward to v2, got changed=%v version=%s err=%v", changed, stale.Upstream.Version, err)
+	}
+}
+
+func TestUpdateJournalRestoreWithoutCommitRemovesJournal(t *testing.T) {
+	inst := setupJournalTestInstance(t)
+	b, err := newInstanceBackup(inst, "v2")
+	if err != nil {
+		t.Fatalf("newInstanceBackup failed: %v", err)
+	}
+	if err := b.Backup("config/a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	_ = os.WriteFile(filepath.Join(inst.Path, "config", "a.txt"), []byte("v2"), 0644)
+	if err := b.Restore(); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	b.Cleanup()
+
+	if got := readTestFile(t, filepath.Join(inst.Path, "config", "a.txt")); got != "v1" {
+		t.Errorf("expected a.txt at v1, got %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(inst.Path, updateJournalDir)); !os.IsNotExist(err) {
+		t.Error("expected journal directory to be removed")
+	}
+}
@@ -0,0 +1,87 @@
+package resource
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Changelog holds the markdown release notes of a pack, patch or repository entry. In JSON it is either a plain
+// string or an object mapping language codes to localized notes; the notes under "" are used for languages without
+// a translation.
+type Changelog map[string]string
+
+func (c *Changelog) UnmarshalJSON(b []byte) error {
+	var text string
+	if err := json.Unmarshal(b, &text); err == nil {
+		*c = nil
+		if text != "" {
+			*c = Changelog{"": text}
+		}
+		return nil
+	}
+	var localized map[string]string
+	if err := json.Unmarshal(b, &localized); err != nil {
+		return fmt.Errorf("changelog must be a string or an object of localized strings: %w", err)
+	}
+	*c = localized
+	return nil
+}
+
+func (c Changelog) MarshalJSON() ([]byte, error) {
+	if text, ok := c[""]; ok && len(c) == 1 {
+		return json.Marshal(text)
+	}
+	return json.Marshal(map[string]string(c))
+}
+
+// Text returns the notes in lang, falling back to the unlocalized notes, English and then any other language.
+// It returns an empty string if there are no notes.
+func (c Changelog) Text(lang string) string {
+	for _, l := range []string{lang, "", "en"} {
+		if text := strings.TrimSpace(c[l]); text != "" {
+			return text
+		}
+	}
+	langs := make([]string, 0, len(c))
+	for l := range c {
+		langs = append(langs, l)
+	}
+	slices.Sort(langs)
+	for _, l := range langs {
+		if text := strings.TrimSpace(c[l]); text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// ReadChangelog returns the release notes of the .sbpack or .sbpatch at path. The notes of a patch are its own,
+// or those of the index it produces if it has none.
+func ReadChangelog(path string) (Changelog, error) {
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer reader.Close()
+
+	var patch SBPatch
+	if _, found, err := readArchiveJSON(&reader.Reader, "sb.patch.json", &patch); err != nil {
+		return nil, err
+	} else if found {
+		if len(patch.Changelog) > 0 {
+			return patch.Changelog, nil
+		}
+		return patch.Index.Changelog, nil
+	}
+
+	var index SBPackIndex
+	if _, found, err := readArchiveJSON(&reader.Reader, "sb.index.json", &index); err != nil {
+		return nil, err
+	} else if !found {
+		return nil, fmt.Errorf("neither sb.patch.json nor sb.index.json found in %s", path)
+	}
+	return index.Changelog, nil
+}
@@ -0,0 +1,64 @@
+package resource
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+func TestChangelogJSON(t *testing.T) {
+	var plain struct {
+		Changelog Changelog `json:"changelog,omitempty"`
+	}
+	if err := json.Unmarshal([]byte(`{"changelog": "Fixed crashes"}`), &plain); err != nil {
+		t.Fatalf("failed to decode plain changelog: %v", err)
+	}
+	if got := plain.Changelog.Text("ja"); got != "Fixed crashes" {
+		t.Errorf("Text(ja) = %q", got)
+	}
+	if b, _ := json.Marshal(plain); string(b) != `{"changelog":"Fixed crashes"}` {
+		t.Errorf("plain changelog encoded as %s", b)
+	}
+
+	var localized struct {
+		Changelog Changelog `json:"changelog,omitempty"`
+	}
+	if err := json.Unmarshal([]byte(`{"changelog": {"en": "Fixed crashes", "ja": "クラッシュを修正"}}`), &localized); err != nil {
+		t.Fatalf("failed to decode localized changelog: %v", err)
+	}
+	for lang, want := range map[string]string{"ja": "クラッシュを修正", "en": "Fixed crashes", "de": "Fixed crashes"} {
+		if got := localized.Changelog.Text(lang); got != want {
+			t.Errorf("Text(%s) = %q, want %q", lang, got, want)
+		}
+	}
+
+	var empty struct {
+		Changelog Changelog `json:"changelog,omitempty"`
+	}
+	if b, _ := json.Marshal(empty); string(b) != `{}` {
+		t.Errorf("empty changelog encoded as %s", b)
+	}
+	if err := json.Unmarshal([]byte(`{"changelog": 1}`), &empty); err == nil {
+		t.Error("expected a number to be rejected")
+	}
+}
+
+func TestReadChangelog(t *testing.T) {
+	dir := t.TempDir()
+	patchB, _ := json.Marshal(SBPatch{
+		FormatVersion: SBPatchFormatVersion,
+		Index:         SBPackIndex{Changelog: Changelog{"": "index notes"}},
+	})
+	patchPath := filepath.Join(dir, "v2.sbpatch")
+	writeTestZip(t, patchPath, map[string][]byte{"sb.patch.json": patchB})
+	if c, err := ReadChangelog(patchPath); err != nil || c.Text("en") != "index notes" {
+		t.Errorf("ReadChangelog(patch) = %v, %v", c, err)
+	}
+
+	indexB, _ := json.Marshal(SBPackIndex{Changelog: Changelog{"ja": "パックのノート"}})
+	packPath := filepath.Join(dir, "v1.sbpack")
+	writeTestZip(t, packPath, map[string][]byte{"sb.index.json": indexB})
+	if c, err := ReadChangelog(packPath); err != nil || c.Text("ja") != "パックのノート" {
+		t.Errorf("ReadChangelog(pack) = %v, %v", c, err)
+	}
+}
@@ -12,6 +12,7 @@ const (
 	QuiltMetaURL             = "https://meta.quiltmc.org/v3/versions/loader"
 	MojangVersionManifestURL = "https://piston-meta.mojang.com/mc/game/version_manifest_v2.json"
 	MojangAssetResourceURL   = "https://resources.download.minecraft.net/"
+	MojangLibrariesURL       = "https://libraries.minecraft.net/"
 	CurseForgeWebURL         = "https://www.curseforge.com/projects"
 	ModrinthWebURL           = "https://modrinth.com/project"
 
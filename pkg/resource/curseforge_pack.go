@@ -0,0 +1,373 @@
+package resource
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CurseForge modpack (.zip with manifest.json) support.
+// The manifest only lists project and file IDs, so every file is resolved through the CurseForge API.
+// Authors can opt their projects out of third-party distribution, in which case the API returns no
+// download URL and the file has to be fetched by the user from the CurseForge website.
+
+const (
+	CurseForgeManifestName = "manifest.json"
+	CurseForgeManifestType = "minecraftModpack"
+
+	CurseForgeModsPath  = "/v1/mods"
+	CurseForgeFilesPath = "/v1/mods/files"
+
+	// CurseForge hash algorithm IDs
+	curseForgeHashSHA1 = 1
+
+	// CurseForge class IDs of the Minecraft content types a modpack can reference
+	curseForgeClassResourcePacks = 12
+	curseForgeClassShaderPacks   = 6552
+)
+
+// curseForgeAPIURL is the CurseForge API endpoint, replaced in tests.
+var curseForgeAPIURL = CurseForgeBaseURL
+
+// curseForgeNamespace derives stable pack IDs from the pack name and version.
+var curseForgeNamespace = uuid.MustParse("2b0f5c4e-8d7a-4e61-b3c9-51a0d8f2e7c6")
+
+// CurseForgeManifest represents the content of manifest.json in a CurseForge modpack.
+type CurseForgeManifest struct {
+	Minecraft       CurseForgeManifestMinecraft `json:"minecraft"`
+	ManifestType    string                      `json:"manifestType"`
+	ManifestVersion int                         `json:"manifestVersion"`
+	Name            string                      `json:"name"`
+	Version         string                      `json:"version"`
+	Author          string                      `json:"author"`
+	Files           []CurseForgeManifestFile    `json:"files"`
+	Overrides       string                      `json:"overrides"`
+}
+
+type CurseForgeManifestMinecraft struct {
+	Version    string                        `json:"version"`
+	ModLoaders []CurseForgeManifestModLoader `json:"modLoaders"`
+}
+
+type CurseForgeManifestModLoader struct {
+	// ID is the loader and its version joined by a dash, e.g. "forge-47.2.0"
+	ID      string `json:"id"`
+	Primary bool   `json:"primary"`
+}
+
+type CurseForgeManifestFile struct {
+	ProjectID int  `json:"projectID"`
+	FileID    int  `json:"fileID"`
+	Required  bool `json:"required"`
+}
+
+// ManualDownload describes a file that could not be downloaded automatically.
+type ManualDownload struct {
+	Name     string `json:"name"`
+	FileName string `json:"file_name"`
+	// Path is where the file has to be placed, relative to the instance directory.
+	Path string `json:"path"`
+	// URL is the page the file can be downloaded from.
+	URL string `json:"url"`
+}
+
+// ManualDownloadError is returned when an instance was imported but some of its files have to be downloaded by the user.
+type ManualDownloadError struct {
+	Files []ManualDownload
+}
+
+func (e *ManualDownloadError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d file(s) must be downloaded manually:", len(e.Files))
+	for _, f := range e.Files {
+		fmt.Fprintf(&sb, "\n  %s (%s) -> %s", f.FileName, f.URL, f.Path)
+	}
+	return sb.String()
+}
+
+// ImportCurseForgePack imports a new instance from a CurseForge modpack zip.
+// Files whose authors do not allow third-party downloads are skipped and returned as manual downloads.
+func ImportCurseForgePack(ctx context.Context, packPath string, destDir string, uid uuid.UUID, observer ProgressObserver) (*Instance, []ManualDownload, error) {
+	if observer == nil {
+		observer = &NopProgressObserver{}
+	}
+	reader, err := zip.OpenReader(packPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open curseforge modpack: %w", err)
+	}
+	defer reader.Close()
+
+	manifest, err := readCurseForgeManifest(&reader.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dependencies, err := manifest.dependencies()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	observer.OnProgress("Resolving CurseForge files", 0, fmt.Sprintf("%d files", len(manifest.Files)), "main")
+	fileIDs := make([]int, 0, len(manifest.Files))
+	modIDs := make([]int, 0, len(manifest.Files))
+	for _, f := range manifest.Files {
+		if !f.Required {
+			continue
+		}
+		fileIDs = append(fileIDs, f.FileID)
+		modIDs = append(modIDs, f.ProjectID)
+	}
+	files, err := fetchCurseForgeFiles(ctx, fileIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	projects, err := fetchCurseForgeMods(ctx, modIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	index := SBPackIndex{
+		FormatVersion: SBPackFormatVersion,
+		Name:          manifest.Name,
+		ID:            uuid.NewSHA1(curseForgeNamespace, []byte(manifest.Name+"\x00"+manifest.Version)),
+		Dependencies:  dependencies,
+	}
+	if manifest.Author != "" {
+		index.Properties.Description = "by " + manifest.Author
+	}
+
+	var mods []Mod
+	var manual []ManualDownload
+	for _, mf := range manifest.Files {
+		// Files the pack author disabled are not installed, as in the CurseForge app
+		if !mf.Required {
+			continue
+		}
+		file, ok := files[mf.FileID]
+		if !ok {
+			return nil, nil, fmt.Errorf("curseforge file not found: projectID %d, fileID %d", mf.ProjectID, mf.FileID)
+		}
+		if file.FileName == "" || strings.ContainsAny(file.FileName, `/\`) || strings.HasPrefix(file.FileName, ".") {
+			return nil, nil, fmt.Errorf("invalid curseforge file name: %q", file.FileName)
+		}
+		project := projects[mf.ProjectID]
+		relPath := curseForgeContentDir(project.ClassID) + "/" + file.FileName
+
+		if filepath.Ext(file.FileName) == ".jar" {
+			name := project.Name
+			if name == "" {
+				name = file.DisplayName
+			}
+			mods = append(mods, Mod{
+				Name:     name,
+				File:     relPath,
+				Version:  file.DisplayName,
+				UpdateAt: time.Now(),
+				Source:   &CurseForgeSource{ProjectID: mf.ProjectID, FileID: mf.FileID},
+			})
+		}
+
+		if file.DownloadURL == "" {
+			manual = append(manual, ManualDownload{
+				Name:     project.Name,
+				FileName: file.FileName,
+				Path:     relPath,
+				URL:      project.fileURL(mf.ProjectID, mf.FileID),
+			})
+			continue
+		}
+
+		sbFile := SBFile{
+			Path:      relPath,
+			Hashes:    map[string]string{},
+			Downloads: []string{file.DownloadURL},
+			FileSize:  int64(file.FileLength),
+		}
+		for _, h := range file.Hashes {
+			if h.Algo == curseForgeHashSHA1 {
+				sbFile.Hashes["sha1"] = strings.ToLower(h.Value)
+			}
+		}
+		index.Files = append(index.Files, sbFile)
+	}
+
+	overrides := manifest.Overrides
+	if overrides == "" {
+		overrides = "overrides"
+	}
+	inst, err := installPackContent(ctx, &reader.Reader, index, false, []string{strings.TrimSuffix(overrides, "/") + "/"}, destDir, uid, observer)
+	if err != nil {
+		return nil, nil, err
+	}
+	inst.Mods = mods
+
+	return inst, manual, nil
+}
+
+func readCurseForgeManifest(reader *zip.Reader) (*CurseForgeManifest, error) {
+	for _, f := range reader.File {
+		if f.Name != CurseForgeManifestName {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		var manifest CurseForgeManifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", CurseForgeManifestName, err)
+		}
+		if manifest.ManifestType != CurseForgeManifestType {
+			return nil, fmt.Errorf("unsupported curseforge manifest type: %s", manifest.ManifestType)
+		}
+		if manifest.ManifestVersion != 1 {
+			return nil, fmt.Errorf("unsupported curseforge manifest version: %d", manifest.ManifestVersion)
+		}
+		return &manifest, nil
+	}
+	return nil, fmt.Errorf("%s not found in pack", CurseForgeManifestName)
+}
+
+// dependencies converts the minecraft section of the manifest into pack index dependencies.
+func (m *CurseForgeManifest) dependencies() (map[string]string, error) {
+	if m.Minecraft.Version == "" {
+		return nil, fmt.Errorf("minecraft version not specified in %s", CurseForgeManifestName)
+	}
+	deps := map[string]string{"minecraft": m.Minecraft.Version}
+
+	loaders := m.Minecraft.ModLoaders
+	for _, l := range loaders {
+		if l.Primary {
+			loaders = []CurseForgeManifestModLoader{l}
+			break
+		}
+	}
+	if len(loaders) == 0 {
+		return deps, nil
+	}
+
+	name, version, ok := strings.Cut(loaders[0].ID, "-")
+	if !ok || version == "" {
+		return nil, fmt.Errorf("invalid mod loader: %s", loaders[0].ID)
+	}
+	switch name {
+	case "forge":
+		deps["forge"] = version
+	case "neoforge":
+		// Old NeoForge releases for 1.20.1 are published as "neoforge-1.20.1-47.1.x"
+		deps["neoforge"] = strings.TrimPrefix(version, m.Minecraft.Version+"-")
+	case "fabric":
+		deps["fabric-loader"] = version
+	case "quilt":
+		deps["quilt-loader"] = version
+	default:
+		return nil, fmt.Errorf("unsupported mod loader: %s", loaders[0].ID)
+	}
+	return deps, nil
+}
+
+// curseForgeContentDir returns the instance directory files of the given CurseForge class belong in.
+func curseForgeContentDir(classID int) string {
+	switch classID {
+	case curseForgeClassResourcePacks:
+		return "resourcepacks"
+	case curseForgeClassShaderPacks:
+		return "shaderpacks"
+	default:
+		return "mods"
+	}
+}
+
+type curseForgeFilesResponse struct {
+	Data []CurseForgeModFileResponseData `json:"data"`
+}
+
+type curseForgeModsResponse struct {
+	Data []curseForgeMod `json:"data"`
+}
+
+type curseForgeMod struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	ClassID int    `json:"classId"`
+	Links   struct {
+		WebsiteURL string `json:"websiteUrl"`
+	} `json:"links"`
+}
+
+// fileURL returns the website page of a file, where users can download it manually.
+func (m curseForgeMod) fileURL(projectID, fileID int) string {
+	if m.Links.WebsiteURL != "" {
+		return fmt.Sprintf("%s/files/%d", strings.TrimSuffix(m.Links.WebsiteURL, "/"), fileID)
+	}
+	return fmt.Sprintf("%s/%d/files/%d", CurseForgeWebURL, projectID, fileID)
+}
+
+func fetchCurseForgeFiles(ctx context.Context, fileIDs []int) (map[int]CurseForgeModFileResponseData, error) {
+	var resp curseForgeFilesResponse
+	if err := postCurseForge(ctx, CurseForgeFilesPath, map[string]any{"fileIds": fileIDs}, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get curseforge files: %w", err)
+	}
+	files := make(map[int]CurseForgeModFileResponseData, len(resp.Data))
+	for _, f := range resp.Data {
+		files[f.ID] = f
+	}
+	return files, nil
+}
+
+func fetchCurseForgeMods(ctx context.Context, modIDs []int) (map[int]curseForgeMod, error) {
+	var resp curseForgeModsResponse
+	if err := postCurseForge(ctx, CurseForgeModsPath, map[string]any{"modIds": modIDs}, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get curseforge projects: %w", err)
+	}
+	mods := make(map[int]curseForgeMod, len(resp.Data))
+	for _, m := range resp.Data {
+		mods[m.ID] = m
+	}
+	return mods, nil
+}
+
+func postCurseForge(ctx context.Context, path string, body any, v any) error {
+	if CurseForgeAPIKey == "" {
+		return fmt.Errorf("curseforge api key is not configured")
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, curseForgeAPIURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("x-api-key", CurseForgeAPIKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := mirrorDo(HTTPClient(), req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("curseforge api returned: %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
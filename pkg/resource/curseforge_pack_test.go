@@ -0,0 +1,203 @@
+package resource
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func writeTestZip(t *testing.T, path string, files map[string][]byte) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("failed to create zip %s: %v", path, err)
+	}
+	defer f.Close()
+	w := zip.NewWriter(f)
+	defer w.Close()
+	for name, data := range files {
+		zw, err := w.Create(name)
+		if err != nil {
+			t.Fatalf("failed to create zip entry %s: %v", name, err)
+		}
+		if _, err := zw.Write(data); err != nil {
+			t.Fatalf("failed to write zip entry %s: %v", name, err)
+		}
+	}
+}
+
+func TestImportCurseForgePack(t *testing.T) {
+	jeiContent := []byte("jei_content")
+	packContent := []byte("resource_pack_content")
+	jeiSum := sha1.Sum(jeiContent)
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if (r.URL.Path == CurseForgeFilesPath || r.URL.Path == CurseForgeModsPath) && r.Header.Get("x-api-key") != "test-key" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case CurseForgeFilesPath:
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{
+				{"id": 101, "modId": 1, "fileName": "jei.jar", "displayName": "JEI 15.2", "downloadUrl": server.URL + "/files/jei.jar", "fileLength": len(jeiContent), "hashes": []map[string]any{{"value": hex.EncodeToString(jeiSum[:]), "algo": 1}, {"value": "ignored", "algo": 2}}},
+				{"id": 201, "modId": 2, "fileName": "restricted.jar", "displayName": "Restricted 1.0", "downloadUrl": nil},
+				{"id": 301, "modId": 3, "fileName": "pack.zip", "displayName": "Pack", "downloadUrl": server.URL + "/files/pack.zip"},
+			}})
+		case CurseForgeModsPath:
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{
+				{"id": 1, "name": "Just Enough Items", "classId": 6},
+				{"id": 2, "name": "Restricted Mod", "classId": 6, "links": map[string]any{"websiteUrl": "https://www.curseforge.com/minecraft/mc-mods/restricted"}},
+				{"id": 3, "name": "Faithful", "classId": 12},
+			}})
+		case "/files/jei.jar":
+			_, _ = w.Write(jeiContent)
+		case "/files/pack.zip":
+			_, _ = w.Write(packContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	oldURL, oldKey, oldDataDir := curseForgeAPIURL, CurseForgeAPIKey, DataDir
+	curseForgeAPIURL, CurseForgeAPIKey, DataDir = server.URL, "test-key", t.TempDir()
+	defer func() { curseForgeAPIURL, CurseForgeAPIKey, DataDir = oldURL, oldKey, oldDataDir }()
+
+	manifest := CurseForgeManifest{
+		Minecraft: CurseForgeManifestMinecraft{
+			Version:    "1.20.1",
+			ModLoaders: []CurseForgeManifestModLoader{{ID: "forge-47.2.0", Primary: true}},
+		},
+		ManifestType:    CurseForgeManifestType,
+		ManifestVersion: 1,
+		Name:            "CF Pack",
+		Version:         "1.0.0",
+		Author:          "someone",
+		Files: []CurseForgeManifestFile{
+			{ProjectID: 1, FileID: 101, Required: true},
+			{ProjectID: 2, FileID: 201, Required: true},
+			{ProjectID: 3, FileID: 301, Required: true},
+			{ProjectID: 4, FileID: 401, Required: false},
+		},
+		Overrides: "overrides",
+	}
+	manifestBytes, _ := json.Marshal(manifest)
+
+	tempDir := t.TempDir()
+	packPath := filepath.Join(tempDir, "pack.zip")
+	writeTestZip(t, packPath, map[string][]byte{
+		CurseForgeManifestName:    manifestBytes,
+		"overrides/config/a.toml": []byte("a"),
+		"modlist.html":            []byte("<ul></ul>"),
+	})
+
+	destDir := filepath.Join(tempDir, "instance")
+	inst, manual, err := ImportCurseForgePack(context.Background(), packPath, destDir, uuid.New(), nil)
+	if err != nil {
+		t.Fatalf("ImportCurseForgePack failed: %v", err)
+	}
+
+	versions := map[string]string{}
+	for _, v := range inst.Versions {
+		versions[v.ID] = v.Version
+	}
+	if versions["minecraft"] != "1.20.1" || versions["forge"] != "47.2.0" {
+		t.Errorf("unexpected versions: %v", versions)
+	}
+
+	if len(inst.Mods) != 2 {
+		t.Fatalf("expected 2 mods, got %d", len(inst.Mods))
+	}
+	for _, m := range inst.Mods {
+		src, ok := m.Source.(*CurseForgeSource)
+		if !ok {
+			t.Errorf("expected CurseForgeSource for %s, got %T", m.Name, m.Source)
+			continue
+		}
+		if m.Name == "Just Enough Items" && (src.ProjectID != 1 || src.FileID != 101 || m.File != "mods/jei.jar") {
+			t.Errorf("unexpected mod: %+v %+v", m, src)
+		}
+	}
+
+	if len(manual) != 1 {
+		t.Fatalf("expected 1 manual download, got %d", len(manual))
+	}
+	if manual[0].Path != "mods/restricted.jar" || manual[0].URL != "https://www.curseforge.com/minecraft/mc-mods/restricted/files/201" {
+		t.Errorf("unexpected manual download: %+v", manual[0])
+	}
+
+	for path, want := range map[string]string{
+		"mods/jei.jar":           string(jeiContent),
+		"resourcepacks/pack.zip": string(packContent),
+		"config/a.toml":          "a",
+	} {
+		got, err := os.ReadFile(filepath.Join(destDir, path))
+		if err != nil {
+			t.Errorf("failed to read %s: %v", path, err)
+			continue
+		}
+		if string(got) != want {
+			t.Errorf("%s: expected %q, got %q", path, want, got)
+		}
+	}
+
+	// Restricted files must not be in the index, so repairs do not try to download them
+	indexBytes, err := os.ReadFile(filepath.Join(destDir, "sb.index.json"))
+	if err != nil {
+		t.Fatalf("failed to read sb.index.json: %v", err)
+	}
+	var index SBPackIndex
+	_ = json.Unmarshal(indexBytes, &index)
+	if len(index.Files) != 2 {
+		t.Errorf("expected 2 indexed files, got %d", len(index.Files))
+	}
+	if index.Dependencies["forge"] != "47.2.0" {
+		t.Errorf("unexpected index dependencies: %v", index.Dependencies)
+	}
+}
+
+func TestCurseForgeManifestDependencies(t *testing.T) {
+	tests := []struct {
+		loader string
+		id     string
+		want   string
+	}{
+		{"forge-47.2.0", "forge", "47.2.0"},
+		{"neoforge-20.4.80", "neoforge", "20.4.80"},
+		{"neoforge-1.20.1-47.1.79", "neoforge", "47.1.79"},
+		{"fabric-0.15.0", "fabric-loader", "0.15.0"},
+		{"quilt-0.23.0", "quilt-loader", "0.23.0"},
+	}
+	for _, tt := range tests {
+		m := CurseForgeManifest{Minecraft: CurseForgeManifestMinecraft{
+			Version:    "1.20.1",
+			ModLoaders: []CurseForgeManifestModLoader{{ID: tt.loader, Primary: true}},
+		}}
+		deps, err := m.dependencies()
+		if err != nil {
+			t.Errorf("%s: %v", tt.loader, err)
+			continue
+		}
+		if deps[tt.id] != tt.want || deps["minecraft"] != "1.20.1" {
+			t.Errorf("%s: unexpected dependencies %v", tt.loader, deps)
+		}
+	}
+
+	m := CurseForgeManifest{Minecraft: CurseForgeManifestMinecraft{
+		Version:    "1.20.1",
+		ModLoaders: []CurseForgeManifestModLoader{{ID: "rift-1.0", Primary: true}},
+	}}
+	if _, err := m.dependencies(); err == nil {
+		t.Error("expected an error for an unsupported loader")
+	}
+}
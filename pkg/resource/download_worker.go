@@ -0,0 +1,279 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultDownloadConcurrency is the number of tasks a DownloadWorker runs at once unless its Concurrency is set.
+var DefaultDownloadConcurrency = 8
+
+const (
+	defaultTaskRetries = 3
+	// maxReportedErrors limits the task errors kept in a DownloadError.
+	maxReportedErrors = 10
+)
+
+// DownloadWorker runs download tasks concurrently. Each task is retried with exponential backoff before it
+// counts as failed, and the bytes written by the tasks are tracked for progress reporting.
+type DownloadWorker struct {
+	// Concurrency is the number of tasks run at once. Zero uses DefaultDownloadConcurrency.
+	Concurrency int
+	// Retries is how often a failed task is run again. Zero uses the default and a negative value disables retries.
+	Retries int
+
+	mu         sync.Mutex
+	tasks      []downloadTask
+	totalTasks int
+	remain     int
+	totalBytes int64
+	unsized    int
+	started    time.Time
+
+	doneBytes atomic.Int64
+}
+
+type downloadTask struct {
+	size int64
+	run  func(ctx context.Context, progress io.Writer) error
+}
+
+// addTask queues a task. size is the number of bytes the task writes, or 0 if unknown. The task reports the
+// bytes it writes to progress; whatever it did not report is counted once it succeeds.
+func (w *DownloadWorker) addTask(size int64, task func(ctx context.Context, progress io.Writer) error) {
+	if task == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.tasks = append(w.tasks, downloadTask{size: size, run: task})
+	w.totalTasks++
+	w.remain++
+	w.totalBytes += size
+	if size <= 0 {
+		w.unsized++
+	}
+}
+
+// Remain returns the number of tasks that have not finished yet.
+func (w *DownloadWorker) Remain() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.remain
+}
+
+type downloadBudgetKey struct{}
+
+// withDownloadBudget returns a context under which all DownloadWorkers share n download slots, so workers running
+// side by side do not multiply the number of connections.
+func withDownloadBudget(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, downloadBudgetKey{}, make(chan struct{}, max(n, 1)))
+}
+
+func hasDownloadBudget(ctx context.Context) bool {
+	_, ok := ctx.Value(downloadBudgetKey{}).(chan struct{})
+	return ok
+}
+
+// acquireDownloadSlot waits for a slot of the download budget of ctx, if it has one, and returns a function that
+// frees it.
+func acquireDownloadSlot(ctx context.Context) (func(), error) {
+	budget, ok := ctx.Value(downloadBudgetKey{}).(chan struct{})
+	if !ok {
+		return func() {}, nil
+	}
+	select {
+	case budget <- struct{}{}:
+		return func() { <-budget }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Run runs the queued tasks and returns once every task has finished or ctx is done. The other tasks keep
+// running when one fails, so their files are in place for the next attempt; the failures are returned together
+// as a *DownloadError. If ctx carries a download budget, each attempt of a task holds one of its slots.
+func (w *DownloadWorker) Run(ctx context.Context) error {
+	w.mu.Lock()
+	tasks := w.tasks
+	w.tasks = nil
+	w.started = time.Now()
+	w.mu.Unlock()
+
+	concurrency := w.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultDownloadConcurrency
+	}
+	retries := w.Retries
+	if retries == 0 {
+		retries = defaultTaskRetries
+	}
+
+	queue := make(chan downloadTask)
+	var (
+		wg     sync.WaitGroup
+		errsMu sync.Mutex
+		errs   []error
+		failed int
+	)
+	for range min(concurrency, len(tasks)) {
+		wg.Go(func() {
+			for task := range queue {
+				if ctx.Err() != nil {
+					continue
+				}
+				if err := w.runTask(ctx, task, max(retries, 0)); err != nil {
+					if ctx.Err() != nil {
+						continue
+					}
+					slog.Error("Download task failed", "error", err)
+					errsMu.Lock()
+					failed++
+					if len(errs) < maxReportedErrors {
+						errs = append(errs, err)
+					}
+					errsMu.Unlock()
+				}
+			}
+		})
+	}
+feed:
+	for _, task := range tasks {
+		select {
+		case queue <- task:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return &DownloadError{Failed: failed, Errs: errs}
+	}
+	return nil
+}
+
+// runTask runs task until it succeeds or has used up its retries.
+func (w *DownloadWorker) runTask(ctx context.Context, task downloadTask, retries int) error {
+	for attempt := 0; ; attempt++ {
+		release, err := acquireDownloadSlot(ctx)
+		if err != nil {
+			return err
+		}
+		progress := &taskProgress{done: &w.doneBytes}
+		err = task.run(ctx, progress)
+		release()
+		if err == nil {
+			if rest := task.size - progress.n.Load(); rest > 0 {
+				w.doneBytes.Add(rest)
+			}
+			w.mu.Lock()
+			w.remain--
+			w.mu.Unlock()
+			return nil
+		}
+		// The bytes of a failed attempt are written again by the next one.
+		w.doneBytes.Add(-progress.n.Load())
+		if attempt >= retries || ctx.Err() != nil {
+			return err
+		}
+		delay := retryDelay(attempt + 1)
+		slog.Warn("Download task failed, retrying", "error", err, "attempt", attempt+1, "delay", delay)
+		if err := sleepContext(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// Stats returns the progress of the worker.
+func (w *DownloadWorker) Stats() DownloadStats {
+	w.mu.Lock()
+	stats := DownloadStats{
+		DoneTasks:  w.totalTasks - w.remain,
+		TotalTasks: w.totalTasks,
+		DoneBytes:  w.doneBytes.Load(),
+	}
+	if w.unsized == 0 {
+		stats.TotalBytes = w.totalBytes
+	}
+	started := w.started
+	w.mu.Unlock()
+
+	if elapsed := time.Since(started).Seconds(); !started.IsZero() && elapsed > 0 {
+		stats.BytesPerSecond = float64(stats.DoneBytes) / elapsed
+	}
+	if stats.BytesPerSecond > 0 && stats.TotalBytes > stats.DoneBytes {
+		stats.ETA = time.Duration(float64(stats.TotalBytes-stats.DoneBytes) / stats.BytesPerSecond * float64(time.Second))
+	}
+	return stats
+}
+
+// taskProgress counts the bytes written by one attempt of a task.
+type taskProgress struct {
+	done *atomic.Int64
+	n    atomic.Int64
+}
+
+func (p *taskProgress) Write(b []byte) (int, error) {
+	p.n.Add(int64(len(b)))
+	p.done.Add(int64(len(b)))
+	return len(b), nil
+}
+
+// DownloadStats is a snapshot of the progress of a DownloadWorker.
+type DownloadStats struct {
+	DoneTasks  int
+	TotalTasks int
+	DoneBytes  int64
+	// TotalBytes is 0 if the size of some task is unknown.
+	TotalBytes     int64
+	BytesPerSecond float64
+	// ETA is 0 if it cannot be estimated.
+	ETA time.Duration
+}
+
+// Progress returns a value between 0.0 and 1.0, by bytes if every task size is known and by tasks otherwise.
+func (s DownloadStats) Progress() float32 {
+	var p float32
+	switch {
+	case s.TotalBytes > 0:
+		p = float32(s.DoneBytes) / float32(s.TotalBytes)
+	case s.TotalTasks > 0:
+		p = float32(s.DoneTasks) / float32(s.TotalTasks)
+	}
+	return min(max(p, 0), 1)
+}
+
+// DownloadError is returned by DownloadWorker.Run when tasks failed after their retries.
+type DownloadError struct {
+	// Failed is the number of failed tasks.
+	Failed int
+	// Errs holds the errors of the first failed tasks.
+	Errs []error
+}
+
+func (e *DownloadError) Error() string {
+	msgs := make([]string, len(e.Errs))
+	for i, err := range e.Errs {
+		msgs[i] = err.Error()
+	}
+	msg := fmt.Sprintf("%d download tasks failed: %s", e.Failed, strings.Join(msgs, "; "))
+	if e.Failed > len(e.Errs) {
+		msg += fmt.Sprintf(" (and %d more)", e.Failed-len(e.Errs))
+	}
+	return msg
+}
+
+func (e *DownloadError) Unwrap() []error {
+	return e.Errs
+}
@@ -0,0 +1,130 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDownloadWorkerRetriesEachTask(t *testing.T) {
+	w := &DownloadWorker{Concurrency: 2}
+	var flakyCalls atomic.Int32
+	w.addTask(10, func(ctx context.Context, progress io.Writer) error {
+		// The bytes of the failed attempts must not be counted twice.
+		progress.Write(make([]byte, 10))
+		if flakyCalls.Add(1) < 3 {
+			return errors.New("transient")
+		}
+		return nil
+	})
+	w.addTask(5, func(ctx context.Context, progress io.Writer) error {
+		return nil
+	})
+
+	if err := w.Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if flakyCalls.Load() != 3 {
+		t.Errorf("expected the flaky task to run 3 times, got %d", flakyCalls.Load())
+	}
+	stats := w.Stats()
+	if stats.DoneBytes != 15 || stats.TotalBytes != 15 || stats.DoneTasks != 2 || w.Remain() != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if stats.Progress() != 1 {
+		t.Errorf("Progress() = %v, want 1", stats.Progress())
+	}
+}
+
+func TestDownloadWorkerAggregatesErrors(t *testing.T) {
+	errNotFound := errors.New("not found")
+	w := &DownloadWorker{Retries: -1}
+	var succeeded atomic.Int32
+	for i := range 15 {
+		w.addTask(0, func(ctx context.Context, progress io.Writer) error {
+			if i%5 == 0 {
+				succeeded.Add(1)
+				return nil
+			}
+			return fmt.Errorf("task %d: %w", i, errNotFound)
+		})
+	}
+
+	err := w.Run(context.Background())
+	var downloadErr *DownloadError
+	if !errors.As(err, &downloadErr) {
+		t.Fatalf("expected a DownloadError, got %v", err)
+	}
+	if downloadErr.Failed != 12 || len(downloadErr.Errs) != maxReportedErrors {
+		t.Errorf("expected 12 failures with %d reported, got %d with %d", maxReportedErrors, downloadErr.Failed, len(downloadErr.Errs))
+	}
+	if !errors.Is(err, errNotFound) {
+		t.Error("expected the task errors to be wrapped")
+	}
+	if succeeded.Load() != 3 || w.Remain() != 12 {
+		t.Errorf("expected the other tasks to keep running, %d succeeded and %d remain", succeeded.Load(), w.Remain())
+	}
+}
+
+func TestDownloadWorkerCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := &DownloadWorker{Concurrency: 1}
+	var calls atomic.Int32
+	for range 5 {
+		w.addTask(0, func(ctx context.Context, progress io.Writer) error {
+			calls.Add(1)
+			cancel()
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	}
+
+	if err := w.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected no task to start after cancellation, %d ran", calls.Load())
+	}
+}
+
+func TestDownloadWorkersShareBudget(t *testing.T) {
+	ctx := withDownloadBudget(context.Background(), 2)
+	var running, peak atomic.Int32
+	task := func(ctx context.Context, progress io.Writer) error {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+		return nil
+	}
+	workers := []*DownloadWorker{{Concurrency: 4}, {Concurrency: 4}}
+	for _, w := range workers {
+		for range 8 {
+			w.addTask(0, task)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, w := range workers {
+		wg.Go(func() {
+			if err := w.Run(ctx); err != nil {
+				t.Errorf("Run failed: %v", err)
+			}
+		})
+	}
+	wg.Wait()
+	if peak.Load() > 2 {
+		t.Errorf("expected at most 2 tasks at once across both workers, got %d", peak.Load())
+	}
+}
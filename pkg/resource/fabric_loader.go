@@ -35,10 +35,7 @@ func (f *FabricLoader) Progress() float32 {
 	if f.worker == nil {
 		return 0.0
 	}
-	// We don't have total count easily here without modifying worker,
-	// but we can estimate or just show remain.
-	// Actually, let's keep it simple.
-	return 0.5 // Default to 50% while downloading
+	return f.worker.Stats().Progress()
 }
 
 type FabricMetaResponse struct {
@@ -75,15 +72,23 @@ type FabricLauncherMeta struct {
 type FabricLibraryInfo struct {
 	Name string `json:"name"`
 	URL  string `json:"url"`
+	// Sha1 is empty if the metadata has no checksum; the one published by the maven repository is used then.
+	Sha1 string `json:"sha1,omitempty"`
 }
 
 // Install handles the downloading of Fabric loader and its dependencies.
 func (f *FabricLoader) Install(ctx context.Context, inst *Instance) error {
+	if f.installed(DataDir) {
+		slog.Info("Fabric is already installed", "gameVersion", f.GameVersion, "loaderVersion", f.LoaderVersion)
+		f.isInstalled = true
+		return nil
+	}
+
 	slog.Info("Installing Fabric", "gameVersion", f.GameVersion, "loaderVersion", f.LoaderVersion)
 	f.isInstalled = false
 
 	url := fmt.Sprintf("%s/%s/%s", FabricMetaURL, f.GameVersion, f.LoaderVersion)
-	resp, err := http.Get(url)
+	resp, err := MirrorGet(url)
 	if err != nil {
 		return fmt.Errorf("failed to fetch fabric meta: %w", err)
 	}
@@ -118,20 +123,20 @@ func (f *FabricLoader) Install(ctx context.Context, inst *Instance) error {
 			}
 			downloadURL := libURL + libPath
 
-			f.worker.addTask(func() error {
-				return downloadFile(downloadURL, fullPath)
+			f.worker.addTask(0, func(ctx context.Context, progress io.Writer) error {
+				return downloadFile(ctx, downloadURL, fullPath, lib.Sha1, progress)
 			})
 		}
 	}
 
 	if f.worker.Remain() > 0 {
-		if err := f.worker.Run(); err != nil {
+		if err := f.worker.Run(ctx); err != nil {
 			return fmt.Errorf("failed to download fabric libraries: %w", err)
 		}
 	}
 
 	// Save the meta for launch config generation later
-	metaPath := filepath.Join(dataPath, "versions", f.GameVersion+"-fabric-"+f.LoaderVersion, "fabric-meta.json")
+	metaPath := f.metaPath(dataPath)
 	if err := os.MkdirAll(filepath.Dir(metaPath), 0755); err != nil {
 		return err
 	}
@@ -153,16 +158,9 @@ func (f *FabricLoader) Install(ctx context.Context, inst *Instance) error {
 // GenerateLaunchConfig produces the configuration required to launch the game with Fabric.
 func (f *FabricLoader) GenerateLaunchConfig(inst *Instance, features map[string]bool, memory uint64) (*LaunchConfig, error) {
 	dataPath := DataDir
-	metaPath := filepath.Join(dataPath, "versions", f.GameVersion+"-fabric-"+f.LoaderVersion, "fabric-meta.json")
-	file, err := os.Open(metaPath)
+	meta, err := f.readMeta(dataPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open fabric meta: %w", err)
-	}
-	defer file.Close()
-
-	var meta FabricMetaResponse
-	if err := json.NewDecoder(file).Decode(&meta); err != nil {
-		return nil, fmt.Errorf("failed to decode fabric meta: %w", err)
+		return nil, err
 	}
 
 	// 1. Get Vanilla Launch Config as base
@@ -191,7 +189,43 @@ func (f *FabricLoader) GenerateLaunchConfig(inst *Instance, features map[string]
 	return config, nil
 }
 
+func (f *FabricLoader) metaPath(dataPath string) string {
+	return filepath.Join(dataPath, "versions", f.GameVersion+"-fabric-"+f.LoaderVersion, "fabric-meta.json")
+}
+
+func (f *FabricLoader) readMeta(dataPath string) (*FabricMetaResponse, error) {
+	file, err := os.Open(f.metaPath(dataPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open fabric meta: %w", err)
+	}
+	defer file.Close()
+
+	var meta FabricMetaResponse
+	if err := json.NewDecoder(file).Decode(&meta); err != nil {
+		return nil, fmt.Errorf("failed to decode fabric meta: %w", err)
+	}
+	return &meta, nil
+}
+
+// installed reports whether the saved meta and all of its libraries are on disk.
+func (f *FabricLoader) installed(dataPath string) bool {
+	meta, err := f.readMeta(dataPath)
+	if err != nil {
+		return false
+	}
+	names := []string{meta.Loader.Maven, meta.Intermediary.Maven}
+	for _, lib := range append(meta.LauncherMeta.Libraries.Common, meta.LauncherMeta.Libraries.Client...) {
+		names = append(names, lib.Name)
+	}
+	return librariesPresent(dataPath, names)
+}
+
+// mavenToPath converts maven coordinates (group:artifact:version[:classifier][@extension]) to a repository path.
 func mavenToPath(mavenName string, separator string) string {
+	mavenName, ext, ok := strings.Cut(mavenName, "@")
+	if !ok {
+		ext = "jar"
+	}
 	parts := strings.Split(mavenName, ":")
 	if len(parts) < 3 {
 		return ""
@@ -200,16 +234,28 @@ func mavenToPath(mavenName string, separator string) string {
 	artifact := parts[1]
 	version := parts[2]
 
-	filename := fmt.Sprintf("%s-%s.jar", artifact, version)
+	filename := fmt.Sprintf("%s-%s", artifact, version)
+	if len(parts) > 3 && parts[3] != "" {
+		filename += "-" + parts[3]
+	}
+	filename += "." + ext
 	return strings.Join([]string{group, artifact, version, filename}, separator)
 }
 
-func downloadFile(url, path string) error {
+// downloadFile downloads url to path, writing the received bytes to progress as well. The file is checked
+// against sum, or against the checksum the maven repository publishes next to it if sum is empty.
+func downloadFile(ctx context.Context, url, path, sum string, progress io.Writer) error {
 	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		return err
 	}
+	if sum == "" {
+		var err error
+		if sum, err = mavenSHA1(ctx, url); err != nil {
+			return fmt.Errorf("failed to fetch checksum: %w", err)
+		}
+	}
 
-	resp, err := http.Get(url)
+	resp, err := MirrorGetContext(ctx, url)
 	if err != nil {
 		return err
 	}
@@ -219,12 +265,6 @@ func downloadFile(url, path string) error {
 		return fmt.Errorf("failed to download: %s", resp.Status)
 	}
 
-	out, err := os.Create(path)
-	if err != nil {
-		return err
-	}
-	defer out.Close()
-
-	_, err = io.Copy(out, resp.Body)
+	_, err = writeVerified(resp.Body, path, sum, progress)
 	return err
 }
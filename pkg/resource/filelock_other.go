@@ -0,0 +1,22 @@
+//go:build !windows
+
+package resource
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile blocks until it holds a shared or exclusive lock on f. The lock is released when f is closed.
+func lockFile(f *os.File, exclusive bool) error {
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX
+	}
+	for {
+		err := syscall.Flock(int(f.Fd()), how)
+		if err != syscall.EINTR {
+			return err
+		}
+	}
+}
@@ -0,0 +1,27 @@
+//go:build windows
+
+package resource
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var procLockFileEx = dllkernel32.NewProc("LockFileEx")
+
+const lockfileExclusiveLock = 0x2
+
+// lockFile blocks until it holds a shared or exclusive lock on f. The lock is released when f is closed.
+func lockFile(f *os.File, exclusive bool) error {
+	var flags uintptr
+	if exclusive {
+		flags = lockfileExclusiveLock
+	}
+	var ol syscall.Overlapped
+	r, _, err := procLockFileEx.Call(f.Fd(), flags, 0, 1, 0, uintptr(unsafe.Pointer(&ol)))
+	if r == 0 {
+		return err
+	}
+	return nil
+}
@@ -1,15 +1,13 @@
 package resource
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"os"
-	"os/exec"
-	"path/filepath"
-
-	"github.com/ikafly144/sabalauncher/v2/pkg/runcmd"
+	"strings"
 )
 
 func DownloadForge(versionName, forgeDirName, dataPath string) (*DownloadWorker, string, error) {
@@ -19,23 +17,43 @@ func DownloadForge(versionName, forgeDirName, dataPath string) (*DownloadWorker,
 	if err != nil {
 		return nil, "", err
 	}
-	worker.addTask(func() error {
-		defer tmpFile.Close()
-		httpClient := &http.Client{}
-		url := fmt.Sprintf("%s/%s/forge-%s-installer.jar", ForgeMavenURL, versionName, versionName)
-		resp, err := httpClient.Get(url)
-		if err != nil {
-			return err
+	tmpFile.Close()
+	worker.addTask(0, func(ctx context.Context, progress io.Writer) error {
+		// Installers before 1.12.2 repeat the Minecraft version at the end of the maven version.
+		candidates := []string{versionName}
+		if mcVersion, _, ok := strings.Cut(versionName, "-"); ok {
+			candidates = append(candidates, versionName+"-"+mcVersion)
 		}
-		defer resp.Body.Close()
-		if resp.StatusCode != http.StatusOK {
-			return fmt.Errorf("failed to download forge jar: %s", resp.Status)
+		var resp *http.Response
+		for _, candidate := range candidates {
+			url := fmt.Sprintf("%s/%s/forge-%s-installer.jar", ForgeMavenURL, candidate, candidate)
+			r, err := MirrorGetContext(ctx, url)
+			if err != nil {
+				return err
+			}
+			if r.StatusCode == http.StatusOK {
+				resp = r
+				break
+			}
+			r.Body.Close()
+			if r.StatusCode != http.StatusNotFound {
+				return fmt.Errorf("failed to download forge jar: %s", r.Status)
+			}
+		}
+		if resp == nil {
+			return fmt.Errorf("failed to download forge jar: no installer found for %s", versionName)
 		}
+		defer resp.Body.Close()
 
-		_, err = io.Copy(tmpFile, resp.Body)
+		// Each attempt starts the file over.
+		out, err := os.Create(tmpFile.Name())
 		if err != nil {
 			return err
 		}
+		defer out.Close()
+		if _, err := io.Copy(io.MultiWriter(out, progress), resp.Body); err != nil {
+			return err
+		}
 		slog.Info("Forge installer jar downloaded", "path", tmpFile.Name())
 		return nil
 	})
@@ -43,27 +61,13 @@ func DownloadForge(versionName, forgeDirName, dataPath string) (*DownloadWorker,
 	return &worker, tmpFile.Name(), nil
 }
 
+// InstallForge installs a downloaded Forge installer jar by running its processors with the managed Java runtime.
 func InstallForge(installerPath, dataPath string) error {
 	if installerPath == "" {
 		return fmt.Errorf("installer jar path is not set")
 	}
-	profiles, err := os.Create(filepath.Join(dataPath, "launcher_profiles.json"))
-	if err != nil {
-		return err
-	}
-	defer os.Remove(profiles.Name())
-	defer profiles.Close()
-	_, err = profiles.WriteString("{\"profiles\":{}}")
-	if err != nil {
-		return err
-	}
-	cmd := exec.Command("java", "-jar", installerPath, "--installClient", dataPath)
-	cmd.Dir = filepath.Join(filepath.Dir(installerPath))
-	cmd.Stdout = slog.NewLogLogger(slog.Default().Handler(), slog.LevelInfo).Writer()
-	cmd.Stderr = slog.NewLogLogger(slog.Default().Handler(), slog.LevelInfo).Writer()
-	cmd.SysProcAttr = runcmd.GetSysProcAttr()
-	if err := cmd.Run(); err != nil {
-		return err
+	if _, err := RunInstaller(context.Background(), installerPath, dataPath); err != nil {
+		return fmt.Errorf("failed to install forge: %w", err)
 	}
 	return nil
 }
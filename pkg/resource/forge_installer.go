@@ -0,0 +1,548 @@
+package resource
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/ikafly144/sabalauncher/v2/pkg/runcmd"
+)
+
+// InstallProfile is the install_profile.json bundled in Forge and NeoForge installer jars.
+type InstallProfile struct {
+	Spec       int                           `json:"spec"`
+	Version    string                        `json:"version"`
+	JSON       string                        `json:"json"`
+	Path       string                        `json:"path"`
+	Minecraft  string                        `json:"minecraft"`
+	Data       map[string]InstallProfileData `json:"data"`
+	Processors []InstallProcessor            `json:"processors"`
+	Libraries  []Library                     `json:"libraries"`
+
+	// Install and VersionInfo are only set by the legacy format used up to Minecraft 1.12.2.
+	Install     *LegacyInstall  `json:"install,omitempty"`
+	VersionInfo json.RawMessage `json:"versionInfo,omitempty"`
+}
+
+// InstallProfileData is a processor variable with a value for each side.
+type InstallProfileData struct {
+	Client string `json:"client"`
+	Server string `json:"server"`
+}
+
+// InstallProcessor is a jar the installer runs to produce patched game files.
+type InstallProcessor struct {
+	Sides     []string          `json:"sides,omitempty"`
+	Jar       string            `json:"jar"`
+	Classpath []string          `json:"classpath"`
+	Args      []string          `json:"args"`
+	Outputs   map[string]string `json:"outputs,omitempty"`
+}
+
+// LegacyInstall describes the universal jar bundled in a legacy installer.
+type LegacyInstall struct {
+	Path      string `json:"path"`
+	FilePath  string `json:"filePath"`
+	Minecraft string `json:"minecraft"`
+	Target    string `json:"target"`
+}
+
+// runsOnClient reports whether the processor runs for client installs.
+func (p InstallProcessor) runsOnClient() bool {
+	if len(p.Sides) == 0 {
+		return true
+	}
+	for _, side := range p.Sides {
+		if side == "client" {
+			return true
+		}
+	}
+	return false
+}
+
+// RunInstaller installs a Forge or NeoForge installer jar without launching it. It installs the libraries
+// listed in install_profile.json, runs the client processors with the managed Java runtime, verifies their
+// outputs and finally writes the version manifest. It returns the ID of the installed version.
+func RunInstaller(ctx context.Context, installerPath, dataPath string) (string, error) {
+	zr, err := zip.OpenReader(installerPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open installer: %w", err)
+	}
+	defer zr.Close()
+
+	var profile InstallProfile
+	if err := readZipJSON(&zr.Reader, "install_profile.json", &profile); err != nil {
+		return "", fmt.Errorf("failed to read install profile: %w", err)
+	}
+	if profile.Install != nil {
+		return installLegacyProfile(ctx, &zr.Reader, &profile, dataPath)
+	}
+
+	versionName := strings.TrimPrefix(profile.JSON, "/")
+	if versionName == "" {
+		versionName = "version.json"
+	}
+	versionJSON, err := readZipFile(&zr.Reader, versionName)
+	if err != nil {
+		return "", fmt.Errorf("failed to read version manifest: %w", err)
+	}
+	var version ClientManifest
+	if err := json.Unmarshal(versionJSON, &version); err != nil {
+		return "", fmt.Errorf("failed to parse version manifest: %w", err)
+	}
+	if version.ID == "" {
+		return "", fmt.Errorf("version manifest has no id")
+	}
+
+	libraries := append(append([]Library(nil), profile.Libraries...), version.Libraries...)
+	if err := installInstallerLibraries(ctx, &zr.Reader, dataPath, libraries); err != nil {
+		return "", err
+	}
+
+	if len(profile.Processors) > 0 {
+		tmpDir, err := os.MkdirTemp("", "forge-installer-*")
+		if err != nil {
+			return "", err
+		}
+		defer os.RemoveAll(tmpDir)
+
+		p := &processorRunner{
+			zr:            &zr.Reader,
+			installerPath: installerPath,
+			dataPath:      dataPath,
+			tmpDir:        tmpDir,
+			minecraft:     profile.Minecraft,
+		}
+		if err := p.resolveData(profile.Data); err != nil {
+			return "", err
+		}
+		for i, proc := range profile.Processors {
+			if !proc.runsOnClient() {
+				continue
+			}
+			if err := p.run(ctx, proc); err != nil {
+				return "", fmt.Errorf("failed to run processor %d (%s): %w", i+1, proc.Jar, err)
+			}
+		}
+	}
+
+	// The manifest is written last since its presence marks the version as installed.
+	if err := writeVersionManifest(dataPath, version.ID, versionJSON); err != nil {
+		return "", err
+	}
+	slog.Info("Installed loader version", "id", version.ID)
+	return version.ID, nil
+}
+
+// installLegacyProfile installs a legacy installer, which only bundles a universal jar and a version manifest.
+func installLegacyProfile(ctx context.Context, zr *zip.Reader, profile *InstallProfile, dataPath string) (string, error) {
+	var versionInfo map[string]any
+	if err := json.Unmarshal(profile.VersionInfo, &versionInfo); err != nil {
+		return "", fmt.Errorf("failed to parse version manifest: %w", err)
+	}
+	id, _ := versionInfo["id"].(string)
+	if id == "" {
+		id = profile.Install.Target
+	}
+	if id == "" {
+		return "", fmt.Errorf("version manifest has no id")
+	}
+
+	universal, err := SafeJoin(filepath.Join(dataPath, "libraries"), mavenToPath(profile.Install.Path, "/"))
+	if err != nil {
+		return "", err
+	}
+	if err := extractZipFile(zr, profile.Install.FilePath, universal); err != nil {
+		return "", fmt.Errorf("failed to extract %s: %w", profile.Install.FilePath, err)
+	}
+
+	// Legacy manifests list maven coordinates with a repository URL instead of download entries.
+	rawLibraries, _ := versionInfo["libraries"].([]any)
+	var libraries []Library
+	for _, raw := range rawLibraries {
+		lib, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		if _, ok := lib["downloads"]; ok {
+			continue
+		}
+		// Libraries without clientreq are only needed by the server.
+		if clientreq, ok := lib["clientreq"].(bool); ok && !clientreq {
+			continue
+		}
+		name, _ := lib["name"].(string)
+		libPath := mavenToPath(name, "/")
+		if libPath == "" {
+			continue
+		}
+		baseURL, _ := lib["url"].(string)
+		if baseURL == "" {
+			baseURL = MojangLibrariesURL
+		}
+		artifact := LibraryArtifact{Path: libPath, URL: strings.TrimSuffix(baseURL, "/") + "/" + libPath}
+		if name == profile.Install.Path {
+			artifact.URL = ""
+		}
+		lib["downloads"] = map[string]any{"artifact": artifact}
+		libraries = append(libraries, Library{Name: name, Downloads: LibraryDownloads{Artifact: artifact}})
+	}
+	if err := installInstallerLibraries(ctx, zr, dataPath, libraries); err != nil {
+		return "", err
+	}
+
+	versionJSON, err := json.Marshal(versionInfo)
+	if err != nil {
+		return "", err
+	}
+	if err := writeVersionManifest(dataPath, id, versionJSON); err != nil {
+		return "", err
+	}
+	slog.Info("Installed legacy loader version", "id", id)
+	return id, nil
+}
+
+// installInstallerLibraries makes sure every library is in the libraries directory. Libraries bundled in the
+// installer's maven directory are extracted, the others are downloaded. Libraries without a URL are produced
+// by the processors and are skipped.
+func installInstallerLibraries(ctx context.Context, zr *zip.Reader, dataPath string, libraries []Library) error {
+	librariesDir := filepath.Join(dataPath, "libraries")
+	var worker DownloadWorker
+	seen := make(map[string]bool)
+	for _, lib := range libraries {
+		artifact := lib.Downloads.Artifact
+		if artifact.Path == "" {
+			artifact.Path = mavenToPath(lib.Name, "/")
+		}
+		if artifact.Path == "" || seen[artifact.Path] {
+			continue
+		}
+		seen[artifact.Path] = true
+
+		dest, err := SafeJoin(librariesDir, artifact.Path)
+		if err != nil {
+			return err
+		}
+		if fileSHA1Matches(dest, artifact.Sha1) {
+			continue
+		}
+		if f := findZipFile(zr, "maven/"+artifact.Path); f != nil {
+			if err := extractZipFile(zr, f.Name, dest); err != nil {
+				return fmt.Errorf("failed to extract library %s: %w", lib.Name, err)
+			}
+			continue
+		}
+		if artifact.URL == "" {
+			continue
+		}
+		hashes := map[string]string{}
+		if artifact.Sha1 != "" {
+			hashes["sha1"] = artifact.Sha1
+		}
+		worker.addTask(int64(artifact.Size), func(ctx context.Context, progress io.Writer) error {
+			if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+				return err
+			}
+			return downloadWithVerify(ctx, []string{artifact.URL}, dest, hashes, nil, lib.Name, "libraries")
+		})
+	}
+	if worker.Remain() == 0 {
+		return nil
+	}
+	if err := worker.Run(ctx); err != nil {
+		return fmt.Errorf("failed to download libraries: %w", err)
+	}
+	return nil
+}
+
+// processorRunner runs the processors of an install profile.
+type processorRunner struct {
+	zr            *zip.Reader
+	installerPath string
+	dataPath      string
+	tmpDir        string
+	minecraft     string
+	data          map[string]string
+	java          string
+}
+
+// resolveData resolves the client values of the profile variables along with the built-in ones.
+// Values are a maven coordinate in brackets, a literal in single quotes or a file in the installer.
+func (p *processorRunner) resolveData(data map[string]InstallProfileData) error {
+	p.data = map[string]string{
+		"SIDE":              "client",
+		"MINECRAFT_VERSION": p.minecraft,
+		"MINECRAFT_JAR":     filepath.Join(p.dataPath, "versions", p.minecraft, p.minecraft+".jar"),
+		"ROOT":              p.dataPath,
+		"INSTALLER":         p.installerPath,
+		"LIBRARY_DIR":       filepath.Join(p.dataPath, "libraries"),
+	}
+	for key, value := range data {
+		v := value.Client
+		switch {
+		case len(v) >= 2 && v[0] == '[' && v[len(v)-1] == ']':
+			path, err := p.libraryPath(v[1 : len(v)-1])
+			if err != nil {
+				return err
+			}
+			p.data[key] = path
+		case len(v) >= 2 && v[0] == '\'' && v[len(v)-1] == '\'':
+			p.data[key] = v[1 : len(v)-1]
+		case v == "":
+			p.data[key] = ""
+		default:
+			dest, err := SafeJoin(p.tmpDir, strings.TrimPrefix(v, "/"))
+			if err != nil {
+				return err
+			}
+			if err := extractZipFile(p.zr, strings.TrimPrefix(v, "/"), dest); err != nil {
+				return fmt.Errorf("failed to extract %s: %w", v, err)
+			}
+			p.data[key] = dest
+		}
+	}
+	return nil
+}
+
+// libraryPath returns the path of a maven coordinate in the libraries directory.
+func (p *processorRunner) libraryPath(name string) (string, error) {
+	rel := mavenToPath(name, "/")
+	if rel == "" {
+		return "", fmt.Errorf("invalid maven coordinate: %s", name)
+	}
+	return SafeJoin(filepath.Join(p.dataPath, "libraries"), rel)
+}
+
+// replace substitutes {KEY} variables and [maven:coordinates] in a processor argument.
+func (p *processorRunner) replace(arg string) (string, error) {
+	if len(arg) >= 2 && arg[0] == '[' && arg[len(arg)-1] == ']' {
+		return p.libraryPath(arg[1 : len(arg)-1])
+	}
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(arg, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(arg[start:], '}')
+		if end < 0 {
+			break
+		}
+		key := arg[start+1 : start+end]
+		value, ok := p.data[key]
+		if !ok {
+			return "", fmt.Errorf("unknown processor variable: %s", key)
+		}
+		b.WriteString(arg[:start])
+		b.WriteString(value)
+		arg = arg[start+end+1:]
+	}
+	b.WriteString(arg)
+	return b.String(), nil
+}
+
+// outputs resolves the files a processor declares and their expected SHA-1 hashes.
+func (p *processorRunner) outputs(proc InstallProcessor) (map[string]string, error) {
+	outputs := make(map[string]string, len(proc.Outputs))
+	for key, value := range proc.Outputs {
+		path, err := p.replace(key)
+		if err != nil {
+			return nil, err
+		}
+		sum, err := p.replace(value)
+		if err != nil {
+			return nil, err
+		}
+		outputs[path] = sum
+	}
+	return outputs, nil
+}
+
+func (p *processorRunner) run(ctx context.Context, proc InstallProcessor) error {
+	outputs, err := p.outputs(proc)
+	if err != nil {
+		return err
+	}
+	if len(outputs) > 0 && outputsMatch(outputs) {
+		slog.Info("Processor outputs are up to date", "jar", proc.Jar)
+		return nil
+	}
+
+	jar, err := p.libraryPath(proc.Jar)
+	if err != nil {
+		return err
+	}
+	mainClass, err := jarMainClass(jar)
+	if err != nil {
+		return err
+	}
+	classpath := []string{jar}
+	for _, name := range proc.Classpath {
+		path, err := p.libraryPath(name)
+		if err != nil {
+			return err
+		}
+		classpath = append(classpath, path)
+	}
+	args := []string{"-cp", strings.Join(classpath, string(os.PathListSeparator)), mainClass}
+	for _, arg := range proc.Args {
+		a, err := p.replace(arg)
+		if err != nil {
+			return err
+		}
+		args = append(args, a)
+	}
+
+	java, err := p.javaPath(ctx)
+	if err != nil {
+		return err
+	}
+	slog.Info("Running processor", "jar", proc.Jar, "main", mainClass)
+	cmd := exec.CommandContext(ctx, java, args...)
+	cmd.Dir = p.tmpDir
+	cmd.Stdout = slog.NewLogLogger(slog.Default().Handler(), slog.LevelInfo).Writer()
+	cmd.Stderr = slog.NewLogLogger(slog.Default().Handler(), slog.LevelInfo).Writer()
+	cmd.SysProcAttr = runcmd.GetSysProcAttr()
+	if err := cmd.Run(); err != nil {
+		return err
+	}
+
+	for path, sum := range outputs {
+		if !fileSHA1Matches(path, sum) {
+			for path := range outputs {
+				os.Remove(path)
+			}
+			return fmt.Errorf("processor output %s does not match sha1 %s", path, sum)
+		}
+	}
+	return nil
+}
+
+// javaPath returns the managed Java runtime of the Minecraft version, installing it if needed.
+func (p *processorRunner) javaPath(ctx context.Context) (string, error) {
+	if p.java != "" {
+		return p.java, nil
+	}
+	component := "jre-legacy"
+	if manifest, err := GetLocalClientManifest(p.dataPath, p.minecraft); err == nil && manifest.JavaVersion.Component != "" {
+		component = manifest.JavaVersion.Component
+	}
+	java, err := GetJavaExecutablePath(component, p.dataPath)
+	if err != nil {
+		var worker DownloadWorker
+		if err := installJavaRuntime(component, p.dataPath, &worker); err != nil {
+			return "", fmt.Errorf("failed to install java runtime %s: %w", component, err)
+		}
+		if err := worker.Run(ctx); err != nil {
+			return "", fmt.Errorf("failed to install java runtime %s: %w", component, err)
+		}
+		if java, err = GetJavaExecutablePath(component, p.dataPath); err != nil {
+			return "", err
+		}
+	}
+	p.java = java
+	return java, nil
+}
+
+// outputsMatch reports whether every output exists with its expected hash.
+func outputsMatch(outputs map[string]string) bool {
+	for path, sum := range outputs {
+		if !fileSHA1Matches(path, sum) {
+			return false
+		}
+	}
+	return true
+}
+
+// jarMainClass reads the Main-Class attribute from the manifest of a jar.
+func jarMainClass(jarPath string) (string, error) {
+	zr, err := zip.OpenReader(jarPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", filepath.Base(jarPath), err)
+	}
+	defer zr.Close()
+	data, err := readZipFile(&zr.Reader, "META-INF/MANIFEST.MF")
+	if err != nil {
+		return "", fmt.Errorf("failed to read manifest of %s: %w", filepath.Base(jarPath), err)
+	}
+
+	// Manifest lines are wrapped at 72 bytes, with continuation lines starting with a space.
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.HasPrefix(line, " ") && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	for _, line := range lines {
+		if name, value, ok := strings.Cut(line, ":"); ok && strings.EqualFold(name, "Main-Class") {
+			return strings.TrimSpace(value), nil
+		}
+	}
+	return "", fmt.Errorf("%s has no Main-Class", filepath.Base(jarPath))
+}
+
+// writeVersionManifest writes a version manifest to the versions directory.
+func writeVersionManifest(dataPath, id string, data []byte) error {
+	path, err := SafeJoin(filepath.Join(dataPath, "versions"), id+"/"+id+".json")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write version manifest: %w", err)
+	}
+	return nil
+}
+
+func findZipFile(zr *zip.Reader, name string) *zip.File {
+	for _, f := range zr.File {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func readZipFile(zr *zip.Reader, name string) ([]byte, error) {
+	f := findZipFile(zr, name)
+	if f == nil {
+		return nil, fmt.Errorf("%s: %w", name, os.ErrNotExist)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+func readZipJSON(zr *zip.Reader, name string, v any) error {
+	data, err := readZipFile(zr, name)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// extractZipFile extracts a single entry of a trusted archive to dest.
+func extractZipFile(zr *zip.Reader, name, dest string) error {
+	f := findZipFile(zr, name)
+	if f == nil {
+		return fmt.Errorf("%s: %w", name, os.ErrNotExist)
+	}
+	return newExtractSandbox(filepath.Dir(dest)).Extract(f, dest)
+}
@@ -0,0 +1,140 @@
+package resource
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func sha1Hex(data []byte) string {
+	h := sha1.Sum(data)
+	return hex.EncodeToString(h[:])
+}
+
+func TestRunInstaller(t *testing.T) {
+	dataPath := t.TempDir()
+	procJar := []byte("processor jar")
+	mappings := []byte("mappings")
+
+	// The processor output is already in place, so the processor is skipped and no Java is needed.
+	mappingsPath := filepath.Join(dataPath, "libraries", "de", "oceanlabs", "mcp", "mcp_config", "1.0", "mcp_config-1.0-mappings.txt")
+	if err := os.MkdirAll(filepath.Dir(mappingsPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(mappingsPath, mappings, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	profile := InstallProfile{
+		Spec:      1,
+		JSON:      "/version.json",
+		Minecraft: "1.20.1",
+		Data: map[string]InstallProfileData{
+			"MAPPINGS":     {Client: "[de.oceanlabs.mcp:mcp_config:1.0:mappings@txt]", Server: "[invalid]"},
+			"MAPPINGS_SHA": {Client: "'" + sha1Hex(mappings) + "'"},
+		},
+		Processors: []InstallProcessor{
+			{Sides: []string{"client"}, Jar: "net.test:proc:1.0", Args: []string{"--out", "{MAPPINGS}"}, Outputs: map[string]string{"{MAPPINGS}": "{MAPPINGS_SHA}"}},
+			{Sides: []string{"server"}, Jar: "net.test:missing:1.0"},
+		},
+		Libraries: []Library{{
+			Name:      "net.test:proc:1.0",
+			Downloads: LibraryDownloads{Artifact: LibraryArtifact{Path: "net/test/proc/1.0/proc-1.0.jar", Sha1: sha1Hex(procJar)}},
+		}},
+	}
+	profileJSON, _ := json.Marshal(profile)
+	versionJSON := []byte(`{"id":"1.20.1-forge-test","inheritsFrom":"1.20.1","libraries":[{"name":"net.test:client:1.0"}]}`)
+
+	installer := filepath.Join(t.TempDir(), "installer.jar")
+	writeTestZip(t, installer, map[string][]byte{
+		"install_profile.json":                 profileJSON,
+		"version.json":                         versionJSON,
+		"maven/net/test/proc/1.0/proc-1.0.jar": procJar,
+	})
+
+	id, err := RunInstaller(context.Background(), installer, dataPath)
+	if err != nil {
+		t.Fatalf("RunInstaller failed: %v", err)
+	}
+	if id != "1.20.1-forge-test" {
+		t.Errorf("expected id 1.20.1-forge-test, got %s", id)
+	}
+	if !fileSHA1Matches(filepath.Join(dataPath, "libraries", "net", "test", "proc", "1.0", "proc-1.0.jar"), sha1Hex(procJar)) {
+		t.Error("expected the bundled library to be extracted")
+	}
+	got, err := os.ReadFile(filepath.Join(dataPath, "versions", id, id+".json"))
+	if err != nil {
+		t.Fatalf("expected the version manifest to be written: %v", err)
+	}
+	if string(got) != string(versionJSON) {
+		t.Errorf("version manifest = %s, want %s", got, versionJSON)
+	}
+}
+
+func TestRunInstallerUnknownVariable(t *testing.T) {
+	dataPath := t.TempDir()
+	profile := InstallProfile{
+		JSON:      "/version.json",
+		Minecraft: "1.20.1",
+		Processors: []InstallProcessor{
+			{Jar: "net.test:proc:1.0", Outputs: map[string]string{"{UNKNOWN}": "abc"}},
+		},
+	}
+	profileJSON, _ := json.Marshal(profile)
+	installer := filepath.Join(t.TempDir(), "installer.jar")
+	writeTestZip(t, installer, map[string][]byte{
+		"install_profile.json": profileJSON,
+		"version.json":         []byte(`{"id":"broken"}`),
+	})
+
+	if _, err := RunInstaller(context.Background(), installer, dataPath); err == nil {
+		t.Fatal("expected an unknown processor variable to fail the install")
+	}
+	if _, err := os.Stat(filepath.Join(dataPath, "versions", "broken", "broken.json")); !os.IsNotExist(err) {
+		t.Error("expected no version manifest after a failed install")
+	}
+}
+
+func TestRunInstallerLegacy(t *testing.T) {
+	dataPath := t.TempDir()
+	universal := []byte("universal jar")
+	profileJSON := []byte(`{
+		"install": {"path": "net.minecraftforge:forge:1.7.10-10.13.4.1614-1.7.10", "filePath": "forge-universal.jar", "minecraft": "1.7.10", "target": "1.7.10-Forge"},
+		"versionInfo": {"id": "1.7.10-Forge", "mainClass": "net.minecraft.launchwrapper.Launch", "libraries": [
+			{"name": "net.minecraftforge:forge:1.7.10-10.13.4.1614-1.7.10", "url": "https://maven.minecraftforge.net/"},
+			{"name": "net.test:server-only:1.0", "serverreq": true, "clientreq": false}
+		]}
+	}`)
+	installer := filepath.Join(t.TempDir(), "installer.jar")
+	writeTestZip(t, installer, map[string][]byte{
+		"install_profile.json": profileJSON,
+		"forge-universal.jar":  universal,
+	})
+
+	id, err := RunInstaller(context.Background(), installer, dataPath)
+	if err != nil {
+		t.Fatalf("RunInstaller failed: %v", err)
+	}
+	libPath := "net/minecraftforge/forge/1.7.10-10.13.4.1614-1.7.10/forge-1.7.10-10.13.4.1614-1.7.10.jar"
+	if !fileSHA1Matches(filepath.Join(dataPath, "libraries", filepath.FromSlash(libPath)), sha1Hex(universal)) {
+		t.Error("expected the universal jar to be extracted")
+	}
+
+	manifest, err := GetLocalClientManifest(dataPath, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.MainClass != "net.minecraft.launchwrapper.Launch" {
+		t.Errorf("expected the main class to be kept, got %q", manifest.MainClass)
+	}
+	if len(manifest.Libraries) != 2 || manifest.Libraries[0].Downloads.Artifact.Path != libPath {
+		t.Errorf("expected download entries to be added to legacy libraries, got %+v", manifest.Libraries)
+	}
+	if manifest.Libraries[1].Downloads.Artifact.Path != "" {
+		t.Errorf("expected server-only libraries to be left alone, got %+v", manifest.Libraries[1])
+	}
+}
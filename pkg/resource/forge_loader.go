@@ -2,11 +2,12 @@ package resource
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
-	"slices"
+	"strings"
 )
 
 // ForgeLoader implements the ModLoader interface for the Forge mod loader.
@@ -28,6 +29,34 @@ func (f *ForgeLoader) Progress() float32 {
 	return f.progress
 }
 
+var ErrForgeManifestNotFound = errors.New("forge manifest not found")
+
+// findManifestID returns the ID of the installed Forge version. Installers before 1.12.2 name the version
+// differently, such as 1.7.10-Forge10.13.4.1614-1.7.10.
+func (f *ForgeLoader) findManifestID(dataPath string) (string, error) {
+	preferred := f.VanillaVersion + "-forge-" + f.ForgeVersion
+	if _, err := os.Stat(filepath.Join(dataPath, "versions", preferred, preferred+".json")); err == nil {
+		return preferred, nil
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	matches, err := filepath.Glob(filepath.Join(dataPath, "versions", f.VanillaVersion+"-*"+f.ForgeVersion+"*"))
+	if err != nil {
+		return "", err
+	}
+	for _, dir := range matches {
+		id := filepath.Base(dir)
+		if !strings.Contains(strings.ToLower(id), "forge") {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, id+".json")); err == nil {
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrForgeManifestNotFound, preferred)
+}
+
 // Install handles the downloading and installation of Forge.
 func (f *ForgeLoader) Install(ctx context.Context, inst *Instance) error {
 	slog.Info("Installing Forge", "vanillaVersion", f.VanillaVersion, "forgeVersion", f.ForgeVersion)
@@ -40,34 +69,22 @@ func (f *ForgeLoader) Install(ctx context.Context, inst *Instance) error {
 
 	dataPath := DataDir
 
-	// 1. Get Vanilla Manifest
-	ver, err := GetVersion(f.VanillaVersion)
-	if err != nil {
-		return fmt.Errorf("failed to get vanilla version: %w", err)
-	}
-	f.progress = 0.1
-	vanillaManifest, err := GetClientManifest(ver)
-	if err != nil {
-		return fmt.Errorf("failed to get vanilla manifest: %w", err)
-	}
-	_ = vanillaManifest // TODO: Use this to merge with forge manifest after installation
-
-	// 2. Download Forge Installer if not present
+	// 1. Download Forge Installer if not present
 	forgeDir := f.VanillaVersion + "-forge-" + f.ForgeVersion
 	var installerPath string
 
-	if _, err := os.Stat(filepath.Join(dataPath, "versions", forgeDir, forgeDir+".json")); os.IsNotExist(err) {
+	if _, err := f.findManifestID(dataPath); errors.Is(err, ErrForgeManifestNotFound) {
 		worker, path, err := DownloadForge(f.VanillaVersion+"-"+f.ForgeVersion, forgeDir, dataPath)
 		if err != nil {
 			return fmt.Errorf("failed to download forge: %w", err)
 		}
 		f.progress = 0.2
-		if err := worker.Run(); err != nil {
+		if err := worker.Run(ctx); err != nil {
 			return fmt.Errorf("failed to run forge download worker: %w", err)
 		}
 		installerPath = path
 
-		// 3. Install Forge
+		// 2. Install Forge
 		f.progress = 0.5
 		if err := InstallForge(installerPath, dataPath); err != nil {
 			return fmt.Errorf("failed to install forge: %w", err)
@@ -82,7 +99,10 @@ func (f *ForgeLoader) Install(ctx context.Context, inst *Instance) error {
 // GenerateLaunchConfig produces the configuration required to launch the game with Forge.
 func (f *ForgeLoader) GenerateLaunchConfig(inst *Instance, features map[string]bool, memory uint64) (*LaunchConfig, error) {
 	dataPath := DataDir
-	forgeDir := f.VanillaVersion + "-forge-" + f.ForgeVersion
+	forgeDir, err := f.findManifestID(dataPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate forge manifest: %w", err)
+	}
 
 	// 1. Load Forge Manifest recursively (handles inheritance from vanilla)
 	manifest, err := GetClientManifestRecursive(dataPath, forgeDir)
@@ -91,41 +111,9 @@ func (f *ForgeLoader) GenerateLaunchConfig(inst *Instance, features map[string]b
 	}
 
 	// 2. Generate Classpath
-	var classpath []string
-	classpath = append(classpath, filepath.Join(dataPath, "versions", manifest.ID, manifest.ID+".jar"))
-	for _, library := range manifest.Libraries {
-		if library.Downloads.Classifiers != nil {
-			for _, classifier := range library.Downloads.Classifiers {
-				classpath = append(classpath, filepath.Join(dataPath, "libraries", classifier.Path))
-			}
-		}
-		if library.Downloads.Artifact.Path != "" {
-			classpath = append(classpath, filepath.Join(dataPath, "libraries", library.Downloads.Artifact.Path))
-		}
-	}
-
-	var jvmArgs []string
-	jvmArgs = append(jvmArgs, "-Xmx"+fmt.Sprintf("%d", memory)+"M")
-	jvmArgs = append(jvmArgs, defaultJvmArgs...)
-
-	for _, arg := range manifest.Arguments.Jvm {
-		if arg == nil {
-			continue
-		}
-		switch arg := arg.(type) {
-		case JvmArgumentString:
-			jvmArgs = append(jvmArgs, arg.String())
-		case JvmArgumentRule:
-			if !slices.ContainsFunc(arg.Rules, func(rule JvmArgumentRuleType) bool {
-				return rule.Action.Allowed() != rule.OS.Matched()
-			}) {
-				continue
-			}
-			jvmArgs = append(jvmArgs, arg.Value...)
-		}
-	}
-
-	gameArgs := EvaluateGameArguments(manifest.Arguments.Game, features)
+	classpath := manifestClasspath(dataPath, manifest)
+	manifestJvmArgs, gameArgs := manifestArguments(manifest, features)
+	jvmArgs := append(baseJvmArgs(inst, memory), manifestJvmArgs...)
 
 	config := &LaunchConfig{
 		MainClass:     manifest.MainClass,
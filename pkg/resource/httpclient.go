@@ -0,0 +1,356 @@
+package resource
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ikafly144/sabalauncher/v2/pkg/buildinfo"
+)
+
+const (
+	defaultConnectTimeout  = 15 * time.Second
+	defaultResponseTimeout = 30 * time.Second
+	defaultMaxRetries      = 3
+	retryBaseDelay         = 500 * time.Millisecond
+	retryMaxDelay          = 30 * time.Second
+)
+
+// NetworkConfig configures the HTTP client shared by every upstream request.
+type NetworkConfig struct {
+	// Proxy is a proxy URL (http, https or socks5), "direct" to never use a proxy, or empty to use the
+	// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+	Proxy string `json:"proxy,omitempty"`
+	// PACURL is the URL of a proxy auto-config script. It takes precedence over Proxy.
+	PACURL string `json:"pac_url,omitempty"`
+	// CABundle is a PEM file of certificates trusted in addition to the system roots.
+	CABundle string `json:"ca_bundle,omitempty"`
+	// ConnectTimeoutSeconds limits dialing and the TLS handshake.
+	ConnectTimeoutSeconds int `json:"connect_timeout_seconds,omitempty"`
+	// ResponseTimeoutSeconds limits the wait for response headers. Bodies are not limited, so large
+	// downloads are not cut off.
+	ResponseTimeoutSeconds int `json:"response_timeout_seconds,omitempty"`
+	// MaxConcurrentPerHost limits the requests in flight to a single host. Zero means no limit.
+	MaxConcurrentPerHost int `json:"max_concurrent_per_host,omitempty"`
+	// RequestsPerSecond limits how often requests are started to a single host. Zero means no limit.
+	RequestsPerSecond float64 `json:"requests_per_second,omitempty"`
+	// MaxRetries is how often a request is retried after a 429, a 5xx or a network error. Zero uses the
+	// default and a negative value disables retries.
+	MaxRetries int `json:"max_retries,omitempty"`
+}
+
+// UserAgent is sent with every request that does not set its own.
+func UserAgent() string {
+	return fmt.Sprintf("%s/%s (+%s)", buildinfo.LauncherName, buildinfo.LauncherVersion, buildinfo.ProjectGithubURL)
+}
+
+var (
+	httpClient        atomic.Pointer[http.Client]
+	defaultHTTPClient = sync.OnceValue(func() *http.Client {
+		c, _ := NewHTTPClient(nil)
+		return c
+	})
+)
+
+// SetHTTPClient sets the client used for every upstream request. nil restores the default client.
+func SetHTTPClient(c *http.Client) {
+	httpClient.Store(c)
+}
+
+// HTTPClient returns the client set with SetHTTPClient, or a client built from the default NetworkConfig.
+func HTTPClient() *http.Client {
+	if c := httpClient.Load(); c != nil {
+		return c
+	}
+	return defaultHTTPClient()
+}
+
+// NewHTTPClient builds a client from cfg. A nil cfg uses the defaults.
+func NewHTTPClient(cfg *NetworkConfig) (*http.Client, error) {
+	if cfg == nil {
+		cfg = &NetworkConfig{}
+	}
+	proxy, err := cfg.proxyFunc()
+	if err != nil {
+		return nil, err
+	}
+	connectTimeout := secondsOr(cfg.ConnectTimeoutSeconds, defaultConnectTimeout)
+
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.Proxy = proxy
+	base.DialContext = (&net.Dialer{Timeout: connectTimeout, KeepAlive: 30 * time.Second}).DialContext
+	base.TLSHandshakeTimeout = connectTimeout
+	base.ResponseHeaderTimeout = secondsOr(cfg.ResponseTimeoutSeconds, defaultResponseTimeout)
+	if cfg.CABundle != "" {
+		pool, err := loadCABundle(cfg.CABundle)
+		if err != nil {
+			return nil, err
+		}
+		base.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	maxRetries := cfg.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = defaultMaxRetries
+	}
+	return &http.Client{Transport: &clientTransport{
+		base:       base,
+		userAgent:  UserAgent(),
+		maxRetries: max(maxRetries, 0),
+		limiter:    newHostLimiter(cfg.MaxConcurrentPerHost, cfg.RequestsPerSecond),
+	}}, nil
+}
+
+func secondsOr(seconds int, def time.Duration) time.Duration {
+	if seconds <= 0 {
+		return def
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func (cfg *NetworkConfig) proxyFunc() (func(*http.Request) (*url.URL, error), error) {
+	if cfg.PACURL != "" {
+		if err := validateBaseURL(cfg.PACURL); err != nil {
+			return nil, fmt.Errorf("invalid proxy auto-config URL %q: %w", cfg.PACURL, err)
+		}
+		return pacProxy(cfg.PACURL)
+	}
+	switch cfg.Proxy {
+	case "":
+		return http.ProxyFromEnvironment, nil
+	case "direct":
+		return nil, nil
+	}
+	u, err := url.Parse(cfg.Proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy %q: %w", cfg.Proxy, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("invalid proxy %q: unsupported scheme %q", cfg.Proxy, u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy %q: missing host", cfg.Proxy)
+	}
+	return http.ProxyURL(u), nil
+}
+
+func loadCABundle(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in CA bundle %s", path)
+	}
+	return pool, nil
+}
+
+// clientTransport sets the User-Agent, applies the per-host limits and retries failed requests.
+type clientTransport struct {
+	base       http.RoundTripper
+	userAgent  string
+	maxRetries int
+	limiter    *hostLimiter
+}
+
+func (t *clientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if req.Header.Get("User-Agent") == "" {
+		req = req.Clone(ctx)
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+	for attempt := 0; ; attempt++ {
+		r := req
+		if attempt > 0 && req.Body != nil {
+			r = req.Clone(ctx)
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r.Body = body
+		}
+
+		release, err := t.limiter.acquire(ctx, req.URL.Host)
+		if err != nil {
+			return nil, err
+		}
+		start := time.Now()
+		resp, err := t.base.RoundTrip(r)
+		if err != nil {
+			slog.Debug("HTTP request failed", "method", req.Method, "url", req.URL.Redacted(), "attempt", attempt+1, "duration", time.Since(start), "error", err)
+		} else {
+			slog.Debug("HTTP request", "method", req.Method, "url", req.URL.Redacted(), "attempt", attempt+1, "duration", time.Since(start), "status", resp.StatusCode)
+		}
+
+		if attempt >= t.maxRetries || ctx.Err() != nil || !shouldRetry(req, resp, err) {
+			if err != nil {
+				release()
+				return nil, err
+			}
+			resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+			return resp, nil
+		}
+
+		delay := retryDelay(attempt)
+		if resp != nil {
+			if d, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+				delay = min(d, retryMaxDelay)
+			}
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+			resp.Body.Close()
+		}
+		release()
+		slog.Debug("Retrying HTTP request", "url", req.URL.Redacted(), "delay", delay)
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// shouldRetry reports whether the request can be sent again after resp or err. Only idempotent requests are
+// retried after a network error or a server error; a 429 means the request was not processed at all.
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if req.Body != nil && req.GetBody == nil {
+		return false
+	}
+	idempotent := req.Method == http.MethodGet || req.Method == http.MethodHead || req.Method == http.MethodOptions
+	if err != nil {
+		return idempotent
+	}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return true
+	case resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented:
+		return idempotent
+	}
+	return false
+}
+
+// retryDelay returns an exponential backoff with jitter for the given attempt.
+func retryDelay(attempt int) time.Duration {
+	d := min(retryBaseDelay<<attempt, retryMaxDelay)
+	return d/2 + rand.N(d/2+1)
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func retryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if s, err := strconv.Atoi(v); err == nil && s >= 0 {
+		return time.Duration(s) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// releaseBody frees the host slot of a response once its body is closed.
+type releaseBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// hostLimiter limits the concurrent requests and the request rate for each host.
+type hostLimiter struct {
+	concurrency int
+	interval    time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*hostLimit
+}
+
+type hostLimit struct {
+	slots chan struct{}
+	next  time.Time
+}
+
+func newHostLimiter(concurrency int, requestsPerSecond float64) *hostLimiter {
+	l := &hostLimiter{concurrency: max(concurrency, 0), hosts: map[string]*hostLimit{}}
+	if requestsPerSecond > 0 {
+		l.interval = time.Duration(float64(time.Second) / requestsPerSecond)
+	}
+	return l
+}
+
+// acquire waits until a request to host may start and returns a function that frees its slot.
+func (l *hostLimiter) acquire(ctx context.Context, host string) (func(), error) {
+	if l.concurrency == 0 && l.interval == 0 {
+		return func() {}, nil
+	}
+
+	l.mu.Lock()
+	h, ok := l.hosts[host]
+	if !ok {
+		h = &hostLimit{}
+		if l.concurrency > 0 {
+			h.slots = make(chan struct{}, l.concurrency)
+		}
+		l.hosts[host] = h
+	}
+	var wait time.Duration
+	if l.interval > 0 {
+		now := time.Now()
+		if h.next.Before(now) {
+			h.next = now
+		}
+		wait = h.next.Sub(now)
+		h.next = h.next.Add(l.interval)
+	}
+	l.mu.Unlock()
+
+	release := func() {}
+	if h.slots != nil {
+		select {
+		case h.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		release = func() { <-h.slots }
+	}
+	if err := sleepContext(ctx, wait); err != nil {
+		release()
+		return nil, err
+	}
+	return release, nil
+}
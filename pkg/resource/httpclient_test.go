@@ -0,0 +1,129 @@
+package resource
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHTTPClientRetries(t *testing.T) {
+	var calls atomic.Int32
+	var userAgent atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent.Store(r.Header.Get("User-Agent"))
+		switch calls.Add(1) {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewHTTPClient(&NetworkConfig{Proxy: "direct"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || calls.Load() != 3 {
+		t.Errorf("expected success after 3 attempts, got %s after %d", resp.Status, calls.Load())
+	}
+	if ua := userAgent.Load().(string); !strings.HasPrefix(ua, "SabaLauncher/") {
+		t.Errorf("unexpected User-Agent %q", ua)
+	}
+
+	// Non-idempotent requests are not retried after a server error.
+	calls.Store(1)
+	resp, err = client.Post(srv.URL, "text/plain", strings.NewReader("body"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || calls.Load() != 2 {
+		t.Errorf("expected the POST to be sent once, got %s after %d", resp.Status, calls.Load()-1)
+	}
+
+	client, err = NewHTTPClient(&NetworkConfig{Proxy: "direct", MaxRetries: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls.Store(1)
+	resp, err = client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected retries to be disabled, got %s", resp.Status)
+	}
+}
+
+func TestHostLimiterConcurrency(t *testing.T) {
+	l := newHostLimiter(1, 0)
+	release, err := l.acquire(t.Context(), "a.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other, err := l.acquire(t.Context(), "b.example.com"); err != nil {
+		t.Fatalf("expected other hosts to be independent: %v", err)
+	} else {
+		other()
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		if second, err := l.acquire(t.Context(), "a.example.com"); err == nil {
+			second()
+		}
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("expected the second request to wait for the first")
+	case <-time.After(50 * time.Millisecond):
+	}
+	release()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("expected the second request to start after the first was released")
+	}
+}
+
+func TestNewHTTPClientConfig(t *testing.T) {
+	badBundle := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(badBundle, []byte("not a certificate"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]struct {
+		cfg   *NetworkConfig
+		valid bool
+	}{
+		"default":        {nil, true},
+		"direct":         {&NetworkConfig{Proxy: "direct"}, true},
+		"http proxy":     {&NetworkConfig{Proxy: "http://proxy.example.com:8080"}, true},
+		"socks5 proxy":   {&NetworkConfig{Proxy: "socks5://127.0.0.1:1080"}, true},
+		"ftp proxy":      {&NetworkConfig{Proxy: "ftp://proxy.example.com"}, false},
+		"proxy no host":  {&NetworkConfig{Proxy: "http://"}, false},
+		"invalid pac":    {&NetworkConfig{PACURL: "proxy.pac"}, false},
+		"missing bundle": {&NetworkConfig{CABundle: filepath.Join(t.TempDir(), "missing.pem")}, false},
+		"empty bundle":   {&NetworkConfig{CABundle: badBundle}, false},
+	}
+	for name, tt := range tests {
+		if _, err := NewHTTPClient(tt.cfg); (err == nil) != tt.valid {
+			t.Errorf("%s: NewHTTPClient() = %v, want valid %v", name, err, tt.valid)
+		}
+	}
+}
@@ -11,13 +11,48 @@ import (
 )
 
 var (
-	DataDir = filepath.Join(os.Getenv("APPDATA"), "SabaLauncher")
+	// DataDir is where the launcher keeps its data. It is empty if no location could be found; see CheckDataDir.
+	DataDir, dataDirErr = defaultDataDir()
 )
 
+// defaultDataDir returns the launcher directory inside APPDATA on Windows and inside the user config directory elsewhere.
+func defaultDataDir() (string, error) {
+	if appData := os.Getenv("APPDATA"); appData != "" {
+		return filepath.Join(appData, "SabaLauncher"), nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the data directory: %w", err)
+	}
+	return filepath.Join(dir, "SabaLauncher"), nil
+}
+
+// CheckDataDir returns why DataDir could not be determined, or nil if it is usable.
+func CheckDataDir() error {
+	if DataDir != "" {
+		return nil
+	}
+	return dataDirErr
+}
+
 type Upstream struct {
 	PackURL     string `json:"pack_url,omitempty"`
 	ManifestURL string `json:"manifest_url,omitempty"`
 	Version     string `json:"version"`
+	// PublisherKey is the pinned ed25519 key of the pack publisher. Updates signed by any other key are refused.
+	PublisherKey string `json:"publisher_key,omitempty"`
+	// Pinned keeps the instance at Version: no update is offered or applied until it is unpinned.
+	Pinned bool `json:"pinned,omitempty"`
+	// Channel is the release channel the instance follows. Empty means stable.
+	Channel string `json:"channel,omitempty"`
+}
+
+// ChannelName returns the release channel the instance follows.
+func (u *Upstream) ChannelName() string {
+	if u.Channel == "" {
+		return ChannelStable
+	}
+	return u.Channel
 }
 
 type Instance struct {
@@ -28,6 +63,10 @@ type Instance struct {
 	Mods            []Mod                 `json:"mods"`
 	Upstream        *Upstream             `json:"upstream,omitempty"`
 	PlayTimeSeconds int64                 `json:"play_time_seconds,omitempty"`
+	// Account is the ID of the account used to launch the instance. Empty means the active account.
+	Account string `json:"account,omitempty"`
+	// LaunchSettings overrides how the game process is started. Nil uses the launcher defaults.
+	LaunchSettings *LaunchSettings `json:"launch_settings,omitempty"`
 
 	// Internal runtime fields
 	Path string `json:"-"`
@@ -11,7 +11,19 @@ import (
 
 // SetupInstance prepares an Instance by orchestrating downloads and installations
 // based on its specified versions (vanilla, forge, fabric, etc.) and mods.
-func SetupInstance(dataPath string, inst *Instance) *SetupState {
+// If Mojang cannot be reached, it continues offline with the files already on disk.
+// Cancelling ctx stops the running downloads and fails the setup.
+func SetupInstance(ctx context.Context, dataPath string, inst *Instance) *SetupState {
+	return setupInstance(ctx, dataPath, inst, false)
+}
+
+// SetupInstanceOffline prepares an Instance without using the network. It fails with
+// ErrNotAvailableOffline if any required file has not been downloaded before.
+func SetupInstanceOffline(ctx context.Context, dataPath string, inst *Instance) *SetupState {
+	return setupInstance(ctx, dataPath, inst, true)
+}
+
+func setupInstance(ctx context.Context, dataPath string, inst *Instance, offline bool) *SetupState {
 	state := NewState(i18n.T("setup_instance_name", inst.Name), "instance_setup")
 
 	go func() {
@@ -33,25 +45,21 @@ func SetupInstance(dataPath string, inst *Instance) *SetupState {
 			return
 		}
 
-		ver, err := GetVersion(vanillaVersion)
-		if err != nil {
-			slog.Error("Failed to get vanilla version info", "error", err)
-			state.Fail(err)
-			return
-		}
-
-		m, err := GetClientManifest(ver)
+		m, offline, err := resolveClientManifest(dataPath, vanillaVersion, offline)
 		if err != nil {
 			slog.Error("Failed to get client manifest", "error", err)
 			state.Fail(err)
 			return
 		}
 
-		// 2. Add Vanilla Setup Steps
-		state.AddStep(&JavaSetupStep{manifest: m})
-		state.AddStep(&ClientDownloadStep{manifest: m})
+		// 2. Add Vanilla Setup Steps. They are independent of each other and download side by side.
+		javaStep := &JavaSetupStep{manifest: m}
+		clientStep := &ClientDownloadStep{manifest: m}
+		libraryStep := &LibraryDownloadStep{manifest: m}
+		state.AddStep(javaStep)
+		state.AddStep(clientStep)
 		state.AddStep(&AssetsDownloadStep{manifest: m})
-		state.AddStep(&LibraryDownloadStep{manifest: m})
+		state.AddStep(libraryStep)
 
 		// 3. Add Mod Loader Setup Steps
 		loader, err := GetModLoader(inst)
@@ -61,23 +69,30 @@ func SetupInstance(dataPath string, inst *Instance) *SetupState {
 			return
 		}
 
+		// The loader installers need the vanilla client and libraries, and the Forge installer runs its
+		// processors with the Java runtime.
+		var modsAfter []string
 		if loader != nil {
-			state.AddStep(&InstanceLoaderSetupStep{
+			loaderStep := &InstanceLoaderSetupStep{
 				loader: loader,
 				inst:   inst,
-			})
+			}
+			state.AddStep(loaderStep, javaStep.Name(), clientStep.Name(), libraryStep.Name())
+			modsAfter = append(modsAfter, loaderStep.Name())
 		}
 
 		// 4. Mod Installation (TODO: Download Mod instances)
 		if len(inst.Mods) > 0 {
 			state.AddStep(&InstanceModsSetupStep{
 				inst: inst,
-			})
+			}, modsAfter...)
 		}
 
 		if err := state.Do(&SetupContext{
+			ctx:         ctx,
 			dataPath:    dataPath,
 			profilePath: inst.Path,
+			offline:     offline,
 		}); err != nil {
 			slog.Error("Failed to run setup state", "error", err)
 			state.Fail(err)
@@ -108,7 +123,11 @@ func (s *InstanceLoaderSetupStep) Progress() float32 {
 }
 
 func (s *InstanceLoaderSetupStep) Do(ctx *SetupContext) error {
-	return s.loader.Install(context.Background(), s.inst)
+	err := s.loader.Install(ctx.Context(), s.inst)
+	if err != nil && ctx.offline && IsNetworkError(err) {
+		return fmt.Errorf("the mod loader is %w: %w", ErrNotAvailableOffline, err)
+	}
+	return err
 }
 
 type InstanceModsSetupStep struct {
@@ -133,8 +152,9 @@ func (s *InstanceModsSetupStep) Do(ctx *SetupContext) error {
 	return nil
 }
 
-// Helper method to resolve ClientManifest from an instance
-func GetClientManifestForInstance(inst *Instance) (*ClientManifest, error) {
+// Helper method to resolve ClientManifest from an instance.
+// Offline, or when Mojang cannot be reached, the copy saved by the last setup is used.
+func GetClientManifestForInstance(inst *Instance, offline bool) (*ClientManifest, error) {
 	var vanillaVersion string
 	for _, v := range inst.Versions {
 		if v.ID == "minecraft" {
@@ -145,9 +165,6 @@ func GetClientManifestForInstance(inst *Instance) (*ClientManifest, error) {
 	if vanillaVersion == "" {
 		return nil, fmt.Errorf("vanilla minecraft version missing")
 	}
-	ver, err := GetVersion(vanillaVersion)
-	if err != nil {
-		return nil, err
-	}
-	return GetClientManifest(ver)
+	m, _, err := resolveClientManifest(DataDir, vanillaVersion, offline)
+	return m, err
 }
@@ -1,7 +1,9 @@
 package resource
 
 import (
+	"context"
 	"crypto/sha1"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,6 +11,8 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"time"
 
 	"github.com/ulikunitz/xz/lzma"
@@ -21,7 +25,78 @@ type JavaRuntimes struct {
 	MacARM     JavaRuntimeTargets `json:"mac-os-arm64"`
 	Windows64  JavaRuntimeTargets `json:"windows-x64"`
 	Windows32  JavaRuntimeTargets `json:"windows-x86"`
-	WindowsARM JavaRuntimeTargets `json:"windows-arm"`
+	WindowsARM JavaRuntimeTargets `json:"windows-arm64"`
+}
+
+// Platform returns the runtimes for a platform key of all.json, such as "linux" or "mac-os-arm64".
+func (r JavaRuntimes) Platform(platform string) JavaRuntimeTargets {
+	switch platform {
+	case "linux":
+		return r.Linux
+	case "linux-i386":
+		return r.LinuxI386
+	case "mac-os":
+		return r.Mac
+	case "mac-os-arm64":
+		return r.MacARM
+	case "windows-x64":
+		return r.Windows64
+	case "windows-x86":
+		return r.Windows32
+	case "windows-arm64":
+		return r.WindowsARM
+	default:
+		return nil
+	}
+}
+
+// javaRuntimePlatforms returns the all.json platform keys that can run on the given osName and osArch,
+// most preferred first. ARM machines fall back to x64 runtimes through emulation, since Mojang does not
+// publish every runtime for ARM.
+func javaRuntimePlatforms(os, arch string) []string {
+	switch os {
+	case "windows":
+		switch arch {
+		case "x86_64":
+			return []string{"windows-x64", "windows-x86"}
+		case "x86":
+			return []string{"windows-x86"}
+		case "aarch64":
+			return []string{"windows-arm64", "windows-x64", "windows-x86"}
+		}
+	case "osx":
+		switch arch {
+		case "x86_64":
+			return []string{"mac-os"}
+		case "aarch64":
+			return []string{"mac-os-arm64", "mac-os"}
+		}
+	case "linux":
+		switch arch {
+		case "x86_64":
+			return []string{"linux", "linux-i386"}
+		case "x86":
+			return []string{"linux-i386"}
+		}
+	}
+	return nil
+}
+
+// javaRuntimeDir returns the directory a runtime for a platform is installed to.
+func javaRuntimeDir(dataDir, target, platform string) string {
+	return filepath.Join(dataDir, "runtime", target, platform)
+}
+
+// javaExecutable returns the path of the java executable within a runtime directory.
+func javaExecutable(runtimeDir, platform string) string {
+	switch {
+	case strings.HasPrefix(platform, "windows"):
+		return filepath.Join(runtimeDir, "bin", "java.exe")
+	case strings.HasPrefix(platform, "mac-os"):
+		return filepath.Join(runtimeDir, "jre.bundle", "Contents", "Home", "bin", "java")
+	default:
+		return filepath.Join(runtimeDir, "bin", "java")
+	}
 }
 
 type JavaRuntimeTargets map[string][]JavaRuntimeTarget
@@ -152,7 +227,7 @@ func (JLinkEntry) anyEntry() {}
 func installJavaRuntime(target string, dataDir string, worker *DownloadWorker) error {
 	slog.Info("installJavaRuntime", "target", target, "dataDir", dataDir)
 	slog.Info("all.json", "url", JavaRuntimeMetaURL)
-	resp, err := http.Get(JavaRuntimeMetaURL)
+	resp, err := MirrorGet(JavaRuntimeMetaURL)
 	if err != nil {
 		return err
 	}
@@ -164,18 +239,27 @@ func installJavaRuntime(target string, dataDir string, worker *DownloadWorker) e
 	if err := json.NewDecoder(resp.Body).Decode(&runtimes); err != nil {
 		return err
 	}
-	runtime, ok := runtimes.Windows64[target]
-	if !ok {
-		return fmt.Errorf("failed to find java runtime for target: %s", target)
+	platforms := javaRuntimePlatforms(osName(), osArch())
+	if len(platforms) == 0 {
+		return fmt.Errorf("java runtimes are not available for %s/%s", runtime.GOOS, runtime.GOARCH)
+	}
+	var platform string
+	var manifest JDownloadInfo
+	for _, p := range platforms {
+		if targets := runtimes.Platform(p)[target]; len(targets) > 0 {
+			platform = p
+			manifest = targets[0].Manifest
+			break
+		}
 	}
-	if len(runtime) == 0 {
-		return fmt.Errorf("no java runtime found for target: %s", target)
+	if platform == "" {
+		return fmt.Errorf("no java runtime found for target %s on %s", target, platforms[0])
 	}
-	manifest := runtime[0].Manifest
+	runtimeDir := javaRuntimeDir(dataDir, target, platform)
 
-	slog.Info("java runtime manifest", "manifest", manifest)
+	slog.Info("java runtime manifest", "platform", platform, "manifest", manifest)
 
-	resp, err = http.Get(manifest.URL)
+	resp, err = MirrorGet(manifest.URL)
 	if err != nil {
 		return fmt.Errorf("failed to get java runtime manifest: %s", err)
 	}
@@ -190,35 +274,22 @@ func installJavaRuntime(target string, dataDir string, worker *DownloadWorker) e
 	for name, entry := range javaRuntimeManifest.Files {
 		switch e := entry.(type) {
 		case JFileEntry:
-			path := filepath.Join(dataDir, "runtime", target, osName(), name)
+			path := filepath.Join(runtimeDir, name)
 			_ = os.MkdirAll(filepath.Dir(path), 0755)
-			if _, err := os.Stat(path); e.Downloads.Raw.Sha1 != "" && os.IsExist(err) {
-				// Verify the SHA1 checksum
-				file, err := os.Open(path)
-				if err != nil {
+			if _, err := os.Stat(path); err == nil && !fileSHA1Matches(path, e.Downloads.Raw.Sha1) {
+				slog.Info("java runtime file checksum mismatch", "file", path, "expected", e.Downloads.Raw.Sha1)
+				if err := os.Remove(path); err != nil { // Remove the file to force re-download
 					return err
 				}
-				defer file.Close()
-				hasher := sha1.New()
-				if _, err := io.Copy(hasher, file); err != nil {
-					return err
-				}
-				sum := hasher.Sum(nil)
-				if fmt.Sprintf("%x", sum) != e.Downloads.Raw.Sha1 {
-					slog.Info("java runtime file checksum mismatch", "file", path, "expected", e.Downloads.Raw.Sha1)
-					if err := os.Remove(path); err != nil { // Remove the file to force re-download
-						return err
-					}
-				}
 			}
 			if _, err := os.Stat(path); os.IsNotExist(err) {
-				worker.addTask(func() error {
+				worker.addTask(int64(e.Downloads.Raw.Size), func(ctx context.Context, progress io.Writer) error {
 					// File does not exist, download it
 					isLzma := e.Downloads.Lzma != nil
 					var reader io.Reader
 					var size int
 					if isLzma {
-						resp, err := http.Get(e.Downloads.Lzma.URL)
+						resp, err := MirrorGetContext(ctx, e.Downloads.Lzma.URL)
 						if err != nil {
 							return err
 						}
@@ -233,7 +304,7 @@ func installJavaRuntime(target string, dataDir string, worker *DownloadWorker) e
 						reader = lzmaReader
 						size = e.Downloads.Raw.Size
 					} else {
-						resp, err := http.Get(e.Downloads.Raw.URL)
+						resp, err := MirrorGetContext(ctx, e.Downloads.Raw.URL)
 						if err != nil {
 							return err
 						}
@@ -250,11 +321,17 @@ func installJavaRuntime(target string, dataDir string, worker *DownloadWorker) e
 					}
 					defer out.Close()
 
-					if wrote, err := io.Copy(out, reader); err != nil {
+					hasher := sha1.New()
+					if wrote, err := io.Copy(io.MultiWriter(out, hasher, progress), reader); err != nil {
 						return err
 					} else if wrote != int64(size) {
 						return fmt.Errorf("downloaded size mismatch: expected %d, got %d", size, wrote)
 					}
+					if sum := hex.EncodeToString(hasher.Sum(nil)); e.Downloads.Raw.Sha1 != "" && sum != e.Downloads.Raw.Sha1 {
+						out.Close()
+						_ = os.Remove(path)
+						return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", name, e.Downloads.Raw.Sha1, sum)
+					}
 					if e.Executable {
 						if err := os.Chmod(path, 0755); err != nil {
 							return fmt.Errorf("failed to set executable permission: %s", err)
@@ -264,16 +341,25 @@ func installJavaRuntime(target string, dataDir string, worker *DownloadWorker) e
 				})
 			}
 		case JLinkEntry:
-			path := filepath.Join(dataDir, "runtime", target, osName(), name)
+			path := filepath.Join(runtimeDir, name)
 			_ = os.MkdirAll(filepath.Dir(path), 0755)
-			if _, err := os.Stat(path); os.IsNotExist(err) {
-				// File does not exist, create it
-				if err := os.Symlink(e.Target, path); err != nil {
-					return fmt.Errorf("failed to create symlink: %s", err)
+			// Lstat, so a link whose target is not there (yet) is not mistaken for a missing one
+			if _, err := os.Lstat(path); err == nil {
+				if target, err := os.Readlink(path); err == nil && target == e.Target {
+					continue
 				}
+				// Replace a link to the wrong target, or a file in its place
+				if err := os.Remove(path); err != nil {
+					return fmt.Errorf("failed to replace symlink: %s", err)
+				}
+			} else if !os.IsNotExist(err) {
+				return err
+			}
+			if err := os.Symlink(e.Target, path); err != nil {
+				return fmt.Errorf("failed to create symlink: %s", err)
 			}
 		case JDirectoryEntry:
-			path := filepath.Join(dataDir, "runtime", target, osName(), name)
+			path := filepath.Join(runtimeDir, name)
 			if _, err := os.Stat(path); os.IsNotExist(err) {
 				// Directory does not exist, create it
 				if err := os.MkdirAll(path, 0755); err != nil {
@@ -289,13 +375,17 @@ func decompressLzma(reader io.Reader) (io.Reader, error) {
 	return lzma.NewReader(reader)
 }
 
+// GetJavaExecutablePath returns the java executable of an installed runtime for the current platform.
 func GetJavaExecutablePath(target string, dataDir string) (string, error) {
-	path := filepath.Join(dataDir, "runtime", target, osName(), "bin", "java")
-	if osName() == "windows" {
-		path += ".exe"
+	platforms := javaRuntimePlatforms(osName(), osArch())
+	if len(platforms) == 0 {
+		return "", fmt.Errorf("java runtimes are not available for %s/%s", runtime.GOOS, runtime.GOARCH)
 	}
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return "", fmt.Errorf("java executable not found: %s", path)
+	for _, platform := range platforms {
+		path := javaExecutable(javaRuntimeDir(dataDir, target, platform), platform)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
 	}
-	return path, nil
+	return "", fmt.Errorf("java executable not found: %s", javaExecutable(javaRuntimeDir(dataDir, target, platforms[0]), platforms[0]))
 }
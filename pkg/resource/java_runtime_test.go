@@ -0,0 +1,94 @@
+package resource
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestJavaRuntimePlatforms(t *testing.T) {
+	tests := []struct {
+		os, arch string
+		want     []string
+	}{
+		{"windows", "x86_64", []string{"windows-x64", "windows-x86"}},
+		{"windows", "aarch64", []string{"windows-arm64", "windows-x64", "windows-x86"}},
+		{"osx", "x86_64", []string{"mac-os"}},
+		{"osx", "aarch64", []string{"mac-os-arm64", "mac-os"}},
+		{"linux", "x86_64", []string{"linux", "linux-i386"}},
+		{"linux", "x86", []string{"linux-i386"}},
+		{"linux", "aarch64", nil},
+		{"", "x86_64", nil},
+	}
+	for _, tt := range tests {
+		if got := javaRuntimePlatforms(tt.os, tt.arch); !slices.Equal(got, tt.want) {
+			t.Errorf("javaRuntimePlatforms(%q, %q) = %v, want %v", tt.os, tt.arch, got, tt.want)
+		}
+	}
+}
+
+func TestJavaRuntimesPlatform(t *testing.T) {
+	// Each platform of all.json lists a target named after the platform.
+	platforms := []string{"linux", "linux-i386", "mac-os", "mac-os-arm64", "windows-x64", "windows-x86", "windows-arm64"}
+	all := map[string]JavaRuntimeTargets{}
+	for _, p := range platforms {
+		all[p] = JavaRuntimeTargets{p: {{}}}
+	}
+	data, err := json.Marshal(all)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var runtimes JavaRuntimes
+	if err := json.Unmarshal(data, &runtimes); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range platforms {
+		if _, ok := runtimes.Platform(p)[p]; !ok {
+			t.Errorf("Platform(%q) does not return the runtimes of that platform", p)
+		}
+	}
+}
+
+func TestJavaExecutable(t *testing.T) {
+	dir := filepath.Join("data", "runtime", "java-runtime-gamma")
+	tests := map[string]string{
+		"windows-x64":  filepath.Join(dir, "bin", "java.exe"),
+		"mac-os-arm64": filepath.Join(dir, "jre.bundle", "Contents", "Home", "bin", "java"),
+		"linux":        filepath.Join(dir, "bin", "java"),
+	}
+	for platform, want := range tests {
+		if got := javaExecutable(dir, platform); got != want {
+			t.Errorf("javaExecutable(%q) = %q, want %q", platform, got, want)
+		}
+	}
+}
+
+func TestGetJavaExecutablePath(t *testing.T) {
+	platforms := javaRuntimePlatforms(osName(), osArch())
+	if len(platforms) == 0 {
+		t.Skip("java runtimes are not available for this platform")
+	}
+	dataDir := t.TempDir()
+	if _, err := GetJavaExecutablePath("java-runtime-gamma", dataDir); err == nil {
+		t.Fatal("expected a missing runtime to be reported")
+	}
+
+	// A runtime installed for a fallback platform is found as well.
+	platform := platforms[len(platforms)-1]
+	want := javaExecutable(javaRuntimeDir(dataDir, "java-runtime-gamma", platform), platform)
+	if err := os.MkdirAll(filepath.Dir(want), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(want, nil, 0755); err != nil {
+		t.Fatal(err)
+	}
+	got, err := GetJavaExecutablePath("java-runtime-gamma", dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("GetJavaExecutablePath = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,149 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strconv"
+
+	"github.com/ikafly144/sabalauncher/v2/pkg/runcmd"
+)
+
+// GCPreset selects the garbage collector flags passed to the JVM.
+type GCPreset string
+
+const (
+	// GCPresetDefault uses the launcher's tuned G1 flags.
+	GCPresetDefault    GCPreset = ""
+	GCPresetG1         GCPreset = "g1"
+	GCPresetZGC        GCPreset = "zgc"
+	GCPresetShenandoah GCPreset = "shenandoah"
+	GCPresetParallel   GCPreset = "parallel"
+	// GCPresetNone passes no garbage collector flags, leaving the choice to the JVM and the extra JVM arguments.
+	GCPresetNone GCPreset = "none"
+)
+
+// GCPresets lists the selectable garbage collector presets in display order.
+var GCPresets = []GCPreset{GCPresetDefault, GCPresetG1, GCPresetZGC, GCPresetShenandoah, GCPresetParallel, GCPresetNone}
+
+var gcPresetArgs = map[GCPreset][]string{
+	GCPresetG1:         {"-XX:+UseG1GC"},
+	GCPresetZGC:        {"-XX:+UseZGC"},
+	GCPresetShenandoah: {"-XX:+UnlockExperimentalVMOptions", "-XX:+UseShenandoahGC"},
+	GCPresetParallel:   {"-XX:+UseParallelGC"},
+	GCPresetNone:       {},
+}
+
+// LaunchSettings holds the per-instance options that change how the game process is started.
+// The zero value launches the game with the launcher defaults.
+type LaunchSettings struct {
+	// JVMArgs are appended after the generated JVM arguments, so they can override them.
+	JVMArgs []string `json:"jvm_args,omitempty"`
+	// GCPreset replaces the default garbage collector flags.
+	GCPreset GCPreset `json:"gc_preset,omitempty"`
+	// JavaPath is a custom Java executable used instead of the managed runtime.
+	JavaPath string `json:"java_path,omitempty"`
+	// Width and Height set the initial window size. Zero uses the default size.
+	Width      int  `json:"width,omitempty"`
+	Height     int  `json:"height,omitempty"`
+	Fullscreen bool `json:"fullscreen,omitempty"`
+	// Env adds or overrides environment variables of the game process.
+	Env map[string]string `json:"env,omitempty"`
+	// PreLaunchCommand runs in the instance directory before the game starts. The game is not started if it fails.
+	PreLaunchCommand string `json:"pre_launch_command,omitempty"`
+	// PostExitCommand runs in the instance directory after the game exits.
+	PostExitCommand string `json:"post_exit_command,omitempty"`
+}
+
+// Validate checks that the settings can be used to launch the game.
+func (s *LaunchSettings) Validate() error {
+	if s == nil {
+		return nil
+	}
+	if s.GCPreset != GCPresetDefault {
+		if _, ok := gcPresetArgs[s.GCPreset]; !ok {
+			return fmt.Errorf("unknown gc preset: %s", s.GCPreset)
+		}
+	}
+	if s.Width < 0 || s.Height < 0 {
+		return fmt.Errorf("invalid window size: %dx%d", s.Width, s.Height)
+	}
+	for k := range s.Env {
+		if k == "" {
+			return fmt.Errorf("environment variable name is empty")
+		}
+	}
+	return nil
+}
+
+// IsZero reports whether the settings leave every launcher default unchanged.
+func (s *LaunchSettings) IsZero() bool {
+	return s == nil || (len(s.JVMArgs) == 0 && s.GCPreset == GCPresetDefault && s.JavaPath == "" &&
+		s.Width == 0 && s.Height == 0 && !s.Fullscreen && len(s.Env) == 0 &&
+		s.PreLaunchCommand == "" && s.PostExitCommand == "")
+}
+
+// baseJvmArgs returns the memory and garbage collector arguments for an instance.
+func baseJvmArgs(inst *Instance, memory uint64) []string {
+	args := []string{"-Xmx" + fmt.Sprintf("%d", memory) + "M"}
+	if inst != nil && inst.LaunchSettings != nil {
+		if gc, ok := gcPresetArgs[inst.LaunchSettings.GCPreset]; ok {
+			return append(args, gc...)
+		}
+	}
+	return append(args, defaultJvmArgs...)
+}
+
+// resolution returns the window size placeholders for the game arguments.
+func (s *LaunchSettings) resolution() (width, height string) {
+	width, height = DefaultResolutionWidth, DefaultResolutionHeight
+	if s == nil {
+		return width, height
+	}
+	if s.Width > 0 {
+		width = strconv.Itoa(s.Width)
+	}
+	if s.Height > 0 {
+		height = strconv.Itoa(s.Height)
+	}
+	return width, height
+}
+
+// environ returns the environment of the game process, or nil to inherit the launcher's.
+func (s *LaunchSettings) environ() []string {
+	if s == nil || len(s.Env) == 0 {
+		return nil
+	}
+	env := os.Environ()
+	keys := make([]string, 0, len(s.Env))
+	for k := range s.Env {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		env = append(env, k+"="+s.Env[k])
+	}
+	return env
+}
+
+// runHookCommand runs a pre-launch or post-exit command through the system shell.
+func runHookCommand(ctx context.Context, command, dir string, env []string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Dir = dir
+	cmd.Env = env
+	cmd.SysProcAttr = runcmd.GetSysProcAttr()
+	out, err := cmd.CombinedOutput()
+	if len(out) > 0 {
+		slog.Info("Launch hook output", "command", command, "output", string(out))
+	}
+	return err
+}
@@ -0,0 +1,85 @@
+package resource
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestBaseJvmArgs(t *testing.T) {
+	inst := &Instance{}
+	args := baseJvmArgs(inst, 4096)
+	if args[0] != "-Xmx4096M" {
+		t.Errorf("expected -Xmx4096M first, got %v", args)
+	}
+	if !slices.Equal(args[1:], defaultJvmArgs) {
+		t.Errorf("expected default jvm args without settings, got %v", args)
+	}
+
+	inst.LaunchSettings = &LaunchSettings{GCPreset: GCPresetZGC}
+	args = baseJvmArgs(inst, 2048)
+	if !slices.Equal(args, []string{"-Xmx2048M", "-XX:+UseZGC"}) {
+		t.Errorf("expected the zgc preset to replace the defaults, got %v", args)
+	}
+
+	inst.LaunchSettings.GCPreset = GCPresetNone
+	args = baseJvmArgs(inst, 2048)
+	if !slices.Equal(args, []string{"-Xmx2048M"}) {
+		t.Errorf("expected no gc flags, got %v", args)
+	}
+}
+
+func TestLaunchSettings(t *testing.T) {
+	var nilSettings *LaunchSettings
+	if w, h := nilSettings.resolution(); w != DefaultResolutionWidth || h != DefaultResolutionHeight {
+		t.Errorf("expected default resolution, got %sx%s", w, h)
+	}
+	if !nilSettings.IsZero() || nilSettings.environ() != nil || nilSettings.Validate() != nil {
+		t.Error("expected nil settings to use the defaults")
+	}
+
+	s := &LaunchSettings{Width: 1920, Env: map[string]string{"FOO": "bar"}}
+	if w, h := s.resolution(); w != "1920" || h != DefaultResolutionHeight {
+		t.Errorf("expected 1920x%s, got %sx%s", DefaultResolutionHeight, w, h)
+	}
+	if env := s.environ(); len(env) == 0 || env[len(env)-1] != "FOO=bar" {
+		t.Errorf("expected FOO=bar at the end of the environment, got %v", env)
+	}
+	if s.IsZero() {
+		t.Error("expected settings with a width to be non-zero")
+	}
+
+	if err := (&LaunchSettings{GCPreset: "epsilon"}).Validate(); err == nil {
+		t.Error("expected an unknown gc preset to be rejected")
+	}
+	if err := (&LaunchSettings{Height: -1}).Validate(); err == nil {
+		t.Error("expected a negative height to be rejected")
+	}
+}
+
+func TestInstanceLaunchSettingsJSON(t *testing.T) {
+	data, err := json.Marshal(&Instance{Name: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := raw["launch_settings"]; ok {
+		t.Error("expected launch_settings to be omitted when unset")
+	}
+
+	in := &Instance{Name: "test", LaunchSettings: &LaunchSettings{JVMArgs: []string{"-Dfoo=1"}, Fullscreen: true}}
+	data, err = json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out Instance
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.LaunchSettings == nil || !out.LaunchSettings.Fullscreen || !slices.Equal(out.LaunchSettings.JVMArgs, []string{"-Dfoo=1"}) {
+		t.Errorf("launch settings did not round-trip: %+v", out.LaunchSettings)
+	}
+}
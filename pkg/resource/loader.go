@@ -2,9 +2,10 @@ package resource
 
 import (
 	"archive/zip"
+	"context"
 	"fmt"
+	"io"
 	"log/slog"
-	"path/filepath"
 	"strings"
 	"time"
 )
@@ -15,7 +16,7 @@ type Loader struct {
 }
 
 func (newLoader *Loader) update(oldLoader *Loader, packZip *zip.Reader, profilePath string, worker *DownloadWorker) error {
-	worker.addTask(func() error {
+	worker.addTask(0, func(ctx context.Context, progress io.Writer) error {
 		if newLoader.Initialize != nil && (oldLoader.Initialize == nil || oldLoader.Initialize.UpdatedAt.IsZero()) {
 			slog.Info("profile initializing")
 			if err := newLoader.Initialize.update(packZip, profilePath); err != nil {
@@ -39,17 +40,21 @@ type OverridesManifest struct {
 }
 
 func (o *OverridesManifest) update(packZip *zip.Reader, profilePath string) error {
+	sandbox := newExtractSandbox(profilePath)
+	if err := sandbox.CheckArchive(packZip); err != nil {
+		return err
+	}
 	for _, file := range packZip.File {
 		slog.Info("file", "name", file.Name)
 		if !strings.HasPrefix(file.Name, o.Overrides) {
 			slog.Info("file not in overrides", "name", file.Name, "overrides", o.Overrides)
 			continue
 		}
-		name := filepath.Join(profilePath, strings.TrimPrefix(file.Name, o.Overrides))
-		if file.Mode().IsDir() {
+		name := strings.TrimPrefix(file.Name, o.Overrides)
+		if file.Mode().IsDir() || name == "" {
 			continue
 		}
-		if err := writeZipFile(name, file); err != nil {
+		if err := writeZipFile(sandbox, name, file); err != nil {
 			return fmt.Errorf("failed to write file %s: %w", name, err)
 		}
 	}
@@ -62,16 +67,20 @@ type InitializeManifest struct {
 }
 
 func (i *InitializeManifest) update(packZip *zip.Reader, profilePath string) error {
+	sandbox := newExtractSandbox(profilePath)
+	if err := sandbox.CheckArchive(packZip); err != nil {
+		return err
+	}
 	for _, file := range packZip.File {
 		if !strings.HasPrefix(file.Name, i.Initializes) {
 			slog.Info("file not in initializes", "name", file.Name, "initializes", i.Initializes)
 			continue
 		}
-		name := filepath.Join(profilePath, strings.TrimPrefix(file.Name, i.Initializes))
-		if file.Mode().IsDir() {
+		name := strings.TrimPrefix(file.Name, i.Initializes)
+		if file.Mode().IsDir() || name == "" {
 			continue
 		}
-		if err := writeZipFile(name, file); err != nil {
+		if err := writeZipFile(sandbox, name, file); err != nil {
 			return fmt.Errorf("failed to write file %s: %w", name, err)
 		}
 	}
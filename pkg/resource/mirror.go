@@ -0,0 +1,163 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+)
+
+// MirrorProfile replaces upstream servers with mirrors, such as a regional mirror, a caching proxy or a local
+// test server. Each entry of Hosts maps an upstream base URL to the base URLs tried in its place, in order.
+// List the upstream itself to keep it as the last fallback.
+type MirrorProfile struct {
+	Name  string              `json:"name,omitempty"`
+	Hosts map[string][]string `json:"hosts,omitempty"`
+	// UpdateRepository is the GitHub repository ("owner/name") launcher updates are fetched from.
+	UpdateRepository string `json:"update_repository,omitempty"`
+}
+
+var mirrorProfile atomic.Pointer[MirrorProfile]
+
+// SetMirrorProfile sets the mirror profile used for every upstream request. nil restores the upstreams.
+func SetMirrorProfile(p *MirrorProfile) {
+	mirrorProfile.Store(p)
+}
+
+// CurrentMirrorProfile returns the mirror profile set with SetMirrorProfile, or nil.
+func CurrentMirrorProfile() *MirrorProfile {
+	return mirrorProfile.Load()
+}
+
+// Validate checks that every upstream and mirror is an absolute http or https URL.
+func (p *MirrorProfile) Validate() error {
+	if p == nil {
+		return nil
+	}
+	for upstream, mirrors := range p.Hosts {
+		if err := validateBaseURL(upstream); err != nil {
+			return fmt.Errorf("invalid upstream %q: %w", upstream, err)
+		}
+		for _, mirror := range mirrors {
+			if err := validateBaseURL(mirror); err != nil {
+				return fmt.Errorf("invalid mirror %q for %s: %w", mirror, upstream, err)
+			}
+		}
+	}
+	if p.UpdateRepository != "" {
+		owner, name, ok := strings.Cut(p.UpdateRepository, "/")
+		if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+			return fmt.Errorf("invalid update repository %q: expected owner/name", p.UpdateRepository)
+		}
+	}
+	return nil
+}
+
+func validateBaseURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("not an http or https URL")
+	}
+	return nil
+}
+
+// Resolve returns the URLs to try for rawURL, in order. The upstream with the longest matching base is
+// replaced by each of its mirrors. A URL without a matching upstream is returned unchanged.
+func (p *MirrorProfile) Resolve(rawURL string) []string {
+	if p == nil {
+		return []string{rawURL}
+	}
+	var base string
+	var mirrors []string
+	for upstream, m := range p.Hosts {
+		b := strings.TrimSuffix(upstream, "/")
+		if rawURL != b && !strings.HasPrefix(rawURL, b+"/") && !strings.HasPrefix(rawURL, b+"?") {
+			continue
+		}
+		if len(b) > len(base) {
+			base, mirrors = b, m
+		}
+	}
+	if len(mirrors) == 0 {
+		return []string{rawURL}
+	}
+	rest := rawURL[len(base):]
+	urls := make([]string, 0, len(mirrors))
+	for _, mirror := range mirrors {
+		urls = append(urls, strings.TrimSuffix(mirror, "/")+rest)
+	}
+	return urls
+}
+
+// MirrorURLs returns the URLs to try for rawURL with the current mirror profile.
+func MirrorURLs(rawURL string) []string {
+	return CurrentMirrorProfile().Resolve(rawURL)
+}
+
+// MirrorGet sends a GET request for rawURL, trying its mirrors in order.
+func MirrorGet(rawURL string) (*http.Response, error) {
+	return MirrorGetContext(context.Background(), rawURL)
+}
+
+// MirrorGetContext is MirrorGet with a context.
+func MirrorGetContext(ctx context.Context, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	return mirrorDo(HTTPClient(), req)
+}
+
+// mirrorDo sends req to each mirror of its URL in order, moving on when a mirror cannot be reached or
+// answers with a server error. The response or error of the last mirror tried is returned.
+func mirrorDo(client *http.Client, req *http.Request) (*http.Response, error) {
+	urls := MirrorURLs(req.URL.String())
+	var resp *http.Response
+	var err error
+	for i, u := range urls {
+		r := req
+		if u != req.URL.String() || i > 0 {
+			if r, err = cloneRequest(req, u); err != nil {
+				return nil, err
+			}
+		}
+		resp, err = client.Do(r)
+		last := i == len(urls)-1
+		if err == nil && (resp.StatusCode < 500 || last) {
+			return resp, nil
+		}
+		if last || req.Context().Err() != nil {
+			break
+		}
+		if err == nil {
+			err = fmt.Errorf("%s", resp.Status)
+			resp.Body.Close()
+			resp = nil
+		}
+		slog.Warn("Mirror failed, trying the next one", "url", u, "error", err)
+	}
+	return resp, err
+}
+
+// cloneRequest copies req with a new URL and a fresh body.
+func cloneRequest(req *http.Request, rawURL string) (*http.Request, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	r := req.Clone(req.Context())
+	r.URL = u
+	r.Host = ""
+	if req.Body != nil && req.GetBody != nil {
+		if r.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
@@ -0,0 +1,82 @@
+package resource
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestMirrorProfileResolve(t *testing.T) {
+	p := &MirrorProfile{Hosts: map[string][]string{
+		"https://piston-meta.mojang.com":         {"https://mirror.example.com/mojang"},
+		"https://piston-meta.mojang.com/mc/game": {"https://a.example.com/game/", "https://piston-meta.mojang.com/mc/game"},
+	}}
+	tests := map[string]struct {
+		url  string
+		want []string
+	}{
+		"longest prefix": {MojangVersionManifestURL, []string{
+			"https://a.example.com/game/version_manifest_v2.json",
+			MojangVersionManifestURL,
+		}},
+		"shorter prefix": {"https://piston-meta.mojang.com/v1/packages/a.json", []string{"https://mirror.example.com/mojang/v1/packages/a.json"}},
+		"unmatched":      {FabricMetaURL, []string{FabricMetaURL}},
+		"host prefix":    {"https://piston-meta.mojang.com.evil.example/a", []string{"https://piston-meta.mojang.com.evil.example/a"}},
+	}
+	for name, tt := range tests {
+		if got := p.Resolve(tt.url); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: Resolve(%q) = %v, want %v", name, tt.url, got, tt.want)
+		}
+	}
+	var nilProfile *MirrorProfile
+	if got := nilProfile.Resolve(FabricMetaURL); !slices.Equal(got, []string{FabricMetaURL}) {
+		t.Errorf("nil profile: Resolve = %v", got)
+	}
+}
+
+func TestMirrorProfileValidate(t *testing.T) {
+	tests := map[string]struct {
+		profile *MirrorProfile
+		valid   bool
+	}{
+		"nil":               {nil, true},
+		"valid":             {&MirrorProfile{Hosts: map[string][]string{"https://a.example.com": {"http://b.example.com/x"}}, UpdateRepository: "owner/name"}, true},
+		"relative upstream": {&MirrorProfile{Hosts: map[string][]string{"a.example.com": nil}}, false},
+		"ftp mirror":        {&MirrorProfile{Hosts: map[string][]string{"https://a.example.com": {"ftp://b.example.com"}}}, false},
+		"bad repository":    {&MirrorProfile{UpdateRepository: "owner"}, false},
+		"nested repository": {&MirrorProfile{UpdateRepository: "owner/name/extra"}, false},
+	}
+	for name, tt := range tests {
+		if err := tt.profile.Validate(); (err == nil) != tt.valid {
+			t.Errorf("%s: Validate() = %v, want valid %v", name, err, tt.valid)
+		}
+	}
+}
+
+func TestMirrorGetFallback(t *testing.T) {
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer broken.Close()
+	working := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.URL.Path)
+	}))
+	defer working.Close()
+
+	SetMirrorProfile(&MirrorProfile{Hosts: map[string][]string{
+		"https://upstream.example.com": {broken.URL, working.URL + "/mirror"},
+	}})
+	t.Cleanup(func() { SetMirrorProfile(nil) })
+
+	resp, err := MirrorGet("https://upstream.example.com/file.json")
+	if err != nil {
+		t.Fatalf("MirrorGet failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "/mirror/file.json" {
+		t.Errorf("expected the second mirror to answer, got %s %q", resp.Status, body)
+	}
+}
@@ -97,41 +97,9 @@ func (v *VanillaLoader) GenerateLaunchConfig(inst *Instance, features map[string
 		}
 	}
 
-	var classpath []string
-	classpath = append(classpath, filepath.Join(dataPath, "versions", clientManifest.ID, clientManifest.ID+".jar"))
-	for _, library := range clientManifest.Libraries {
-		if library.Downloads.Classifiers != nil {
-			for _, classifier := range library.Downloads.Classifiers {
-				classpath = append(classpath, filepath.Join(dataPath, "libraries", classifier.Path))
-			}
-		}
-		if library.Downloads.Artifact.Path != "" {
-			classpath = append(classpath, filepath.Join(dataPath, "libraries", library.Downloads.Artifact.Path))
-		}
-	}
-
-	var jvmArgs []string
-	jvmArgs = append(jvmArgs, "-Xmx"+fmt.Sprintf("%d", memory)+"M")
-	jvmArgs = append(jvmArgs, defaultJvmArgs...)
-
-	for _, arg := range clientManifest.Arguments.Jvm {
-		if arg == nil {
-			continue
-		}
-		switch arg := arg.(type) {
-		case JvmArgumentString:
-			jvmArgs = append(jvmArgs, arg.String())
-		case JvmArgumentRule:
-			if !slices.ContainsFunc(arg.Rules, func(rule JvmArgumentRuleType) bool {
-				return rule.Action.Allowed() != rule.OS.Matched()
-			}) {
-				continue
-			}
-			jvmArgs = append(jvmArgs, arg.Value...)
-		}
-	}
-
-	gameArgs := EvaluateGameArguments(clientManifest.Arguments.Game, features)
+	classpath := manifestClasspath(dataPath, clientManifest)
+	manifestJvmArgs, gameArgs := manifestArguments(clientManifest, features)
+	jvmArgs := append(baseJvmArgs(inst, memory), manifestJvmArgs...)
 
 	config := &LaunchConfig{
 		MainClass:     clientManifest.MainClass,
@@ -156,6 +124,71 @@ type LaunchConfig struct {
 	Classpath     []string
 }
 
+// manifestClasspath returns the client jar and the libraries of a manifest that are used on the current OS.
+// A manifest without its own jar, such as one installed by Forge, uses the jar of the version it inherits from.
+func manifestClasspath(dataPath string, manifest *ClientManifest) []string {
+	clientJar := filepath.Join(dataPath, "versions", manifest.ID, manifest.ID+".jar")
+	if _, err := os.Stat(clientJar); err != nil && manifest.InheritsFrom != "" {
+		clientJar = filepath.Join(dataPath, "versions", manifest.InheritsFrom, manifest.InheritsFrom+".jar")
+	}
+	classpath := []string{clientJar}
+	seen := make(map[string]bool)
+	for _, library := range manifest.Libraries {
+		path := library.Downloads.Artifact.Path
+		if path == "" || !library.Allowed() || seen[path] {
+			continue
+		}
+		seen[path] = true
+		classpath = append(classpath, filepath.Join(dataPath, "libraries", filepath.FromSlash(path)))
+	}
+	return classpath
+}
+
+// manifestArguments evaluates the JVM and game arguments of a manifest. Versions before 1.13 only have
+// a minecraftArguments string, so they get the JVM arguments the official launcher uses for them.
+func manifestArguments(manifest *ClientManifest, features map[string]bool) (jvmArgs []string, gameArgs []string) {
+	for _, arg := range manifest.Arguments.Jvm {
+		if arg == nil {
+			continue
+		}
+		switch arg := arg.(type) {
+		case JvmArgumentString:
+			jvmArgs = append(jvmArgs, arg.String())
+		case JvmArgumentRule:
+			if !slices.ContainsFunc(arg.Rules, func(rule JvmArgumentRuleType) bool {
+				return rule.Action.Allowed() != rule.OS.Matched()
+			}) {
+				continue
+			}
+			jvmArgs = append(jvmArgs, arg.Value...)
+		}
+	}
+	gameArgs = EvaluateGameArguments(manifest.Arguments.Game, features)
+
+	if len(manifest.Arguments.Game) == 0 && manifest.MinecraftArguments != "" {
+		if len(manifest.Arguments.Jvm) == 0 {
+			jvmArgs = append(jvmArgs, legacyJvmArgs()...)
+		}
+		gameArgs = append(gameArgs, strings.Fields(manifest.MinecraftArguments)...)
+		if features["has_custom_resolution"] {
+			gameArgs = append(gameArgs, "--width", "${resolution_width}", "--height", "${resolution_height}")
+		}
+	}
+	return jvmArgs, gameArgs
+}
+
+// legacyJvmArgs returns the JVM arguments for versions without arguments in their manifest.
+func legacyJvmArgs() []string {
+	var args []string
+	switch osName() {
+	case "osx":
+		args = append(args, "-XstartOnFirstThread")
+	case "windows":
+		args = append(args, "-XX:HeapDumpPath=MojangTricksIntelDriversForPerformance_javaw.exe_minecraft.exe.heapdump")
+	}
+	return append(args, "-Djava.library.path=${natives_directory}")
+}
+
 type modLoader struct {
 	Loader
 	Mods []ModInstance `json:"mods"`
@@ -193,7 +226,7 @@ func (oldLoader *modLoader) loadMod(packZip *zip.Reader, newLoader *modLoader, p
 			oldMod = nil
 			slog.Info("new mod", "key", newLoader.Mods[i].key())
 		}
-		worker.addTask(func() error {
+		worker.addTask(0, func(ctx context.Context, progress io.Writer) error {
 			if err := newLoader.Mods[i].update(profilePath, oldMod); err != nil {
 				return err
 			}
@@ -214,8 +247,18 @@ func (oldLoader *modLoader) loadMod(packZip *zip.Reader, newLoader *modLoader, p
 	return worker, nil
 }
 
-func writeZipFile(name string, file *zip.File) error {
-	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+// legacyModPath returns the path of a mod jar named by a mod site, which must be a plain file name.
+func legacyModPath(profilePath, fileName string) (string, error) {
+	if strings.ContainsAny(fileName, `/\`) {
+		return "", fmt.Errorf("%w: mod file name %s", ErrUnsafePath, fileName)
+	}
+	return SafeJoin(profilePath, "mods/"+fileName)
+}
+
+// writeZipFile extracts an override entry to rel below the sandbox root. An entry named *.delete removes the file it names instead.
+func writeZipFile(sandbox *extractSandbox, rel string, file *zip.File) error {
+	name, err := sandbox.Join(rel)
+	if err != nil {
 		return err
 	}
 	if filepath.Ext(name) == ".delete" {
@@ -225,20 +268,7 @@ func writeZipFile(name string, file *zip.File) error {
 			return err
 		}
 	}
-	f, err := file.Open()
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-	out, err := os.Create(name)
-	if err != nil {
-		return err
-	}
-	defer out.Close()
-	if _, err := io.Copy(out, f); err != nil {
-		return err
-	}
-	return nil
+	return sandbox.Extract(file, name)
 }
 
 type ModInstance interface {
@@ -332,7 +362,7 @@ func (c *CurseForgeModInstance) update(profilePath string, oldInstance ModInstan
 	}
 	url := strings.ReplaceAll(CurseForgeBaseURL+CurseForgeModFilePath, "{modId}", fmt.Sprintf("%d", c.ModId))
 	url = strings.ReplaceAll(url, "{fileId}", fmt.Sprintf("%d", c.FileId))
-	httpClient := &http.Client{}
+	httpClient := HTTPClient()
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
@@ -340,7 +370,7 @@ func (c *CurseForgeModInstance) update(profilePath string, oldInstance ModInstan
 	req.Header.Set("x-api-key", CurseForgeAPIKey)
 	req.Header.Set("Accept", "application/json")
 
-	resp, err := httpClient.Do(req)
+	resp, err := mirrorDo(httpClient, req)
 	if err != nil {
 		return fmt.Errorf("failed to get mod file modId: %d, fileId: %d: %w", c.ModId, c.FileId, err)
 	}
@@ -359,13 +389,19 @@ func (c *CurseForgeModInstance) update(profilePath string, oldInstance ModInstan
 	if modFile.Data.DownloadURL == "" {
 		return fmt.Errorf("mod file url is empty")
 	}
+	modPath, err := legacyModPath(profilePath, modFile.Data.FileName)
+	if err != nil {
+		return err
+	}
 
 	if oldInstance != nil && oldInstance.getCurrentModFileName() != nil && oldInstance.(*CurseForgeModInstance).CurrentFileId != modFile.Data.ID {
 		slog.Info("removing old mod file", "fileName", *oldInstance.getCurrentModFileName())
-		_ = os.Remove(filepath.Join(profilePath, "mods", *oldInstance.getCurrentModFileName()))
+		if oldPath, err := legacyModPath(profilePath, *oldInstance.getCurrentModFileName()); err == nil {
+			_ = os.Remove(oldPath)
+		}
 	}
 	if oldInstance != nil && modFile.Data.ID == oldInstance.(*CurseForgeModInstance).CurrentFileId {
-		if _, err := os.Stat(filepath.Join(profilePath, "mods", modFile.Data.FileName)); os.IsExist(err) || err == nil {
+		if _, err := os.Stat(modPath); os.IsExist(err) || err == nil {
 			slog.Info("mod file is already up to date", "fileId", modFile.Data.ID, "fileName", modFile.Data.FileName)
 			c.CurrentFileName = modFile.Data.FileName
 			c.CurrentFileId = modFile.Data.ID
@@ -382,7 +418,7 @@ func (c *CurseForgeModInstance) update(profilePath string, oldInstance ModInstan
 	}
 	downloadRequest.Header.Set("x-api-key", CurseForgeAPIKey)
 	downloadRequest.Header.Set("Accept", "application/json")
-	downloadResponse, err := httpClient.Do(downloadRequest)
+	downloadResponse, err := mirrorDo(httpClient, downloadRequest)
 	if err != nil {
 		return fmt.Errorf("failed to get mod file download url: %w", err)
 	}
@@ -394,7 +430,7 @@ func (c *CurseForgeModInstance) update(profilePath string, oldInstance ModInstan
 	if err := os.MkdirAll(filepath.Join(profilePath, "mods"), 0755); err != nil {
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
-	out, err := os.Create(filepath.Join(profilePath, "mods", modFile.Data.FileName))
+	out, err := os.Create(modPath)
 	if err != nil {
 		return fmt.Errorf("failed to create file: %w", err)
 	}
@@ -522,7 +558,7 @@ func (m *ModrinthModInstance) update(profilePath string, oldLoader ModInstance)
 	}
 	url := strings.ReplaceAll(ModrinthBaseURL+ModrinthModFilePath, "{projectId}", m.ProjectId)
 	url = strings.ReplaceAll(url, "{versionId}", m.VersionId)
-	resp, err := http.Get(url)
+	resp, err := MirrorGet(url)
 	if err != nil {
 		return fmt.Errorf("failed to get mod file url: %s: %w", url, err)
 	}
@@ -548,13 +584,19 @@ func (m *ModrinthModInstance) update(profilePath string, oldLoader ModInstance)
 	if matchedIndex == -1 {
 		return fmt.Errorf("mod file name %s not found in mod file", m.FileName)
 	}
+	modPath, err := legacyModPath(profilePath, modFile.Files[matchedIndex].FileName)
+	if err != nil {
+		return err
+	}
 
 	if oldLoader != nil && oldLoader.getCurrentModFileName() != nil && oldLoader.(*ModrinthModInstance).VersionId != modFile.ID {
 		slog.Info("removing old mod file", "fileName", *oldLoader.getCurrentModFileName())
-		_ = os.Remove(filepath.Join(profilePath, "mods", *oldLoader.getCurrentModFileName()))
+		if oldPath, err := legacyModPath(profilePath, *oldLoader.getCurrentModFileName()); err == nil {
+			_ = os.Remove(oldPath)
+		}
 	}
 	if oldLoader != nil && modFile.ID == oldLoader.(*ModrinthModInstance).VersionId {
-		if f, err := os.OpenFile(filepath.Join(profilePath, "mods", modFile.Files[0].FileName), os.O_RDONLY, 0644); err == nil {
+		if f, err := os.OpenFile(modPath, os.O_RDONLY, 0644); err == nil {
 			defer f.Close()
 			s := sha512.New()
 			if _, err := io.Copy(s, f); err != nil {
@@ -567,7 +609,7 @@ func (m *ModrinthModInstance) update(profilePath string, oldLoader ModInstance)
 				return nil
 			} else {
 				slog.Info("mod file hash mismatch, removing old file", "versionId", m.CurrentVersionId, "fileName", modFile.Files[0].FileName)
-				_ = os.Remove(filepath.Join(profilePath, "mods", modFile.Files[0].FileName))
+				_ = os.Remove(modPath)
 			}
 		} else {
 			slog.Info("mod file not found, downloading", "versionId", m.CurrentVersionId, "fileName", modFile.Files[0].FileName)
@@ -578,7 +620,7 @@ func (m *ModrinthModInstance) update(profilePath string, oldLoader ModInstance)
 	if modFile.Files[matchedIndex].URL == "" {
 		return fmt.Errorf("mod file url is empty")
 	}
-	download, err := http.Get(modFile.Files[matchedIndex].URL)
+	download, err := MirrorGet(modFile.Files[matchedIndex].URL)
 	if err != nil {
 		return fmt.Errorf("failed to get mod file download url: %w", err)
 	}
@@ -589,7 +631,7 @@ func (m *ModrinthModInstance) update(profilePath string, oldLoader ModInstance)
 	if err := os.MkdirAll(profilePath, 0755); err != nil {
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
-	out, err := os.Create(filepath.Join(profilePath, "mods", modFile.Files[matchedIndex].FileName))
+	out, err := os.Create(modPath)
 	if err != nil {
 		return fmt.Errorf("failed to create file: %w", err)
 	}
@@ -0,0 +1,143 @@
+package resource
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	neturl "net/url"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Modrinth modpack (.mrpack) support.
+// The file entries of modrinth.index.json share their layout with SBFile, so packs convert losslessly
+// apart from the hash algorithms: Modrinth requires sha1 and sha512 for every file.
+
+const (
+	MRPackFormatVersion = 1
+	MRPackGame          = "minecraft"
+	MRPackIndexName     = "modrinth.index.json"
+
+	modrinthCDNHost = "cdn.modrinth.com"
+)
+
+// mrpackNamespace derives stable pack IDs from Modrinth version IDs, so re-importing the same .mrpack yields the same ID.
+var mrpackNamespace = uuid.MustParse("6f1c2a44-6c0e-4d9b-9a37-3f1f7d6b5e21")
+
+// MRPackIndex represents the content of modrinth.index.json
+type MRPackIndex struct {
+	FormatVersion int               `json:"formatVersion"`
+	Game          string            `json:"game"`
+	VersionID     string            `json:"versionId"`
+	Name          string            `json:"name"`
+	Summary       string            `json:"summary,omitempty"`
+	Files         []SBFile          `json:"files"`
+	Dependencies  map[string]string `json:"dependencies"`
+}
+
+// ToSBPackIndex converts a Modrinth index into the launcher's pack index.
+func (m *MRPackIndex) ToSBPackIndex() SBPackIndex {
+	return SBPackIndex{
+		FormatVersion: SBPackFormatVersion,
+		Name:          m.Name,
+		ID:            uuid.NewSHA1(mrpackNamespace, []byte(m.Name+"\x00"+m.VersionID)),
+		Properties: SBPackIndexProperties{
+			Description: m.Summary,
+		},
+		Dependencies: m.Dependencies,
+		Files:        m.Files,
+	}
+}
+
+// NewMRPackIndex converts a pack index into a Modrinth index. Files must already carry sha1 and sha512 hashes.
+func NewMRPackIndex(index SBPackIndex, versionID string) (*MRPackIndex, error) {
+	for _, f := range index.Files {
+		if f.Hashes["sha1"] == "" || f.Hashes["sha512"] == "" {
+			return nil, fmt.Errorf("file %s is missing sha1 or sha512 hash required by mrpack", f.Path)
+		}
+		if len(f.Downloads) == 0 {
+			return nil, fmt.Errorf("file %s has no download URL", f.Path)
+		}
+	}
+	return &MRPackIndex{
+		FormatVersion: MRPackFormatVersion,
+		Game:          MRPackGame,
+		VersionID:     versionID,
+		Name:          index.Name,
+		Summary:       index.Properties.Description,
+		Files:         index.Files,
+		Dependencies:  index.Dependencies,
+	}, nil
+}
+
+// ImportMRPack imports a new instance from a Modrinth .mrpack file.
+// Both "overrides/" and "client-overrides/" are extracted, the latter taking precedence.
+func ImportMRPack(ctx context.Context, packPath string, destDir string, uid uuid.UUID, observer ProgressObserver) (*Instance, error) {
+	if observer == nil {
+		observer = &NopProgressObserver{}
+	}
+	reader, err := zip.OpenReader(packPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mrpack: %w", err)
+	}
+	defer reader.Close()
+
+	index, err := readMRPackIndex(&reader.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return installPackContent(ctx, &reader.Reader, index.ToSBPackIndex(), false, []string{"overrides/", "client-overrides/"}, destDir, uid, observer)
+}
+
+func readMRPackIndex(reader *zip.Reader) (*MRPackIndex, error) {
+	for _, f := range reader.File {
+		if f.Name != MRPackIndexName {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		var index MRPackIndex
+		if err := json.Unmarshal(data, &index); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", MRPackIndexName, err)
+		}
+		if index.FormatVersion != MRPackFormatVersion {
+			return nil, fmt.Errorf("unsupported mrpack format version: %d", index.FormatVersion)
+		}
+		if index.Game != MRPackGame {
+			return nil, fmt.Errorf("unsupported mrpack game: %s", index.Game)
+		}
+		for _, file := range index.Files {
+			if file.Hashes["sha1"] == "" && file.Hashes["sha512"] == "" {
+				return nil, fmt.Errorf("file %s has no hash", file.Path)
+			}
+		}
+		return &index, nil
+	}
+	return nil, fmt.Errorf("%s not found in pack", MRPackIndexName)
+}
+
+// modrinthSourceFromURL recognizes Modrinth CDN URLs of the form
+// https://cdn.modrinth.com/data/<project>/versions/<version>/<file>.
+func modrinthSourceFromURL(rawURL string) (*ModrinthSource, bool) {
+	u, err := neturl.Parse(rawURL)
+	if err != nil || u.Host != modrinthCDNHost {
+		return nil, false
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 5 || parts[0] != "data" || parts[2] != "versions" {
+		return nil, false
+	}
+	return &ModrinthSource{ProjectID: parts[1], VersionID: parts[3]}, true
+}
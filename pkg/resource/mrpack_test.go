@@ -0,0 +1,153 @@
+package resource_test
+
+import (
+	"context"
+	"crypto/sha1"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ikafly144/sabalauncher/v2/pkg/resource"
+)
+
+func TestMRPackImport(t *testing.T) {
+	modContent := []byte("fabric_api_content")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fabric-api.jar" {
+			_, _ = w.Write(modContent)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	oldDataDir := resource.DataDir
+	resource.DataDir = t.TempDir()
+	defer func() { resource.DataDir = oldDataDir }()
+
+	sha1Sum := sha1.Sum(modContent)
+	sha512Sum := sha512.Sum512(modContent)
+
+	index := resource.MRPackIndex{
+		FormatVersion: resource.MRPackFormatVersion,
+		Game:          resource.MRPackGame,
+		VersionID:     "abc123",
+		Name:          "Modrinth Pack",
+		Summary:       "A pack from Modrinth",
+		Files: []resource.SBFile{
+			{
+				Path: "mods/fabric-api.jar",
+				Hashes: map[string]string{
+					"sha1":   hex.EncodeToString(sha1Sum[:]),
+					"sha512": hex.EncodeToString(sha512Sum[:]),
+				},
+				Downloads: []string{server.URL + "/fabric-api.jar"},
+				FileSize:  int64(len(modContent)),
+			},
+		},
+		Dependencies: map[string]string{
+			"minecraft":     "1.20.1",
+			"fabric-loader": "0.15.0",
+		},
+	}
+	indexBytes, _ := json.Marshal(index)
+
+	tempDir := t.TempDir()
+	packPath := filepath.Join(tempDir, "test.mrpack")
+	createMockZip(t, packPath, map[string][]byte{
+		resource.MRPackIndexName:               indexBytes,
+		"overrides/config/shared.txt":          []byte("common"),
+		"overrides/options.txt":                []byte("common options"),
+		"client-overrides/options.txt":         []byte("client options"),
+		"server-overrides/server.properties":   []byte("server only"),
+		"overrides/config/nested/settings.txt": []byte("nested"),
+	})
+
+	destDir := filepath.Join(tempDir, "instance")
+	inst, err := resource.ImportMRPack(context.Background(), packPath, destDir, uuid.New(), nil)
+	if err != nil {
+		t.Fatalf("ImportMRPack failed: %v", err)
+	}
+
+	if inst.Name != "Modrinth Pack" {
+		t.Errorf("expected name 'Modrinth Pack', got %s", inst.Name)
+	}
+	if inst.Properties.Description != "A pack from Modrinth" {
+		t.Errorf("expected summary to become the description, got %q", inst.Properties.Description)
+	}
+	versions := map[string]string{}
+	for _, v := range inst.Versions {
+		versions[v.ID] = v.Version
+	}
+	if versions["minecraft"] != "1.20.1" || versions["fabric-loader"] != "0.15.0" {
+		t.Errorf("unexpected versions: %v", versions)
+	}
+	if len(inst.Mods) != 1 {
+		t.Errorf("expected 1 mod, got %d", len(inst.Mods))
+	}
+
+	// Re-importing the same version must produce the same pack ID
+	again, err := resource.ImportMRPack(context.Background(), packPath, filepath.Join(tempDir, "again"), uuid.New(), nil)
+	if err != nil {
+		t.Fatalf("second ImportMRPack failed: %v", err)
+	}
+	if again.Upstream.Version != inst.Upstream.Version {
+		t.Errorf("expected stable pack ID, got %s and %s", inst.Upstream.Version, again.Upstream.Version)
+	}
+
+	checks := map[string]string{
+		"mods/fabric-api.jar":        string(modContent),
+		"config/shared.txt":          "common",
+		"config/nested/settings.txt": "nested",
+		"options.txt":                "client options",
+	}
+	for path, want := range checks {
+		got, err := os.ReadFile(filepath.Join(destDir, path))
+		if err != nil {
+			t.Errorf("failed to read %s: %v", path, err)
+			continue
+		}
+		if string(got) != want {
+			t.Errorf("%s: expected %q, got %q", path, want, got)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(destDir, "server.properties")); !os.IsNotExist(err) {
+		t.Errorf("server overrides must not be extracted")
+	}
+}
+
+func TestMRPackIndexRoundTrip(t *testing.T) {
+	id, _ := uuid.NewV7()
+	index := resource.SBPackIndex{
+		FormatVersion: resource.SBPackFormatVersion,
+		Name:          "Pack",
+		ID:            id,
+		Dependencies:  map[string]string{"minecraft": "1.21"},
+		Files: []resource.SBFile{
+			{Path: "mods/a.jar", Hashes: map[string]string{"sha256": "00"}, Downloads: []string{"https://example.com/a.jar"}},
+		},
+	}
+	if _, err := resource.NewMRPackIndex(index, "v1"); err == nil {
+		t.Fatal("expected an error for a file without sha1/sha512")
+	}
+
+	index.Files[0].Hashes["sha1"] = "11"
+	index.Files[0].Hashes["sha512"] = "22"
+	mr, err := resource.NewMRPackIndex(index, "v1")
+	if err != nil {
+		t.Fatalf("NewMRPackIndex failed: %v", err)
+	}
+	if mr.Game != resource.MRPackGame || mr.VersionID != "v1" || mr.Dependencies["minecraft"] != "1.21" {
+		t.Errorf("unexpected mrpack index: %+v", mr)
+	}
+	back := mr.ToSBPackIndex()
+	if back.Name != "Pack" || len(back.Files) != 1 || back.Files[0].Hashes["sha512"] != "22" {
+		t.Errorf("unexpected round-tripped index: %+v", back)
+	}
+}
@@ -0,0 +1,108 @@
+package resource
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// nativeClassifier returns the classifier of the natives jar for the current OS, or "" if the library has none.
+func (l Library) nativeClassifier() string {
+	classifier, ok := l.Natives[osName()]
+	if !ok {
+		return ""
+	}
+	bits := "64"
+	if runtime.GOARCH == "386" || runtime.GOARCH == "arm" {
+		bits = "32"
+	}
+	return strings.ReplaceAll(classifier, "${arch}", bits)
+}
+
+// NativeArtifact returns the natives jar of the library for the current OS, as listed in its natives map.
+func (l Library) NativeArtifact() (LibraryArtifact, bool) {
+	classifier := l.nativeClassifier()
+	if classifier == "" {
+		return LibraryArtifact{}, false
+	}
+	if artifact, ok := l.Downloads.Classifiers[classifier]; ok && artifact.Path != "" {
+		return artifact, true
+	}
+	// Legacy manifests only give maven coordinates and a repository URL.
+	path := mavenToPath(l.Name+":"+classifier, "/")
+	if path == "" {
+		return LibraryArtifact{}, false
+	}
+	baseURL := l.URL
+	if baseURL == "" {
+		baseURL = MojangLibrariesURL
+	}
+	return LibraryArtifact{Path: path, URL: strings.TrimSuffix(baseURL, "/") + "/" + path}, true
+}
+
+// NativesDir returns the directory the natives of a version are extracted to.
+func NativesDir(dataDir string, manifest *ClientManifest) string {
+	return filepath.Join(dataDir, "bin", manifest.ID)
+}
+
+// ExtractNatives extracts the natives jars of the libraries used on the current OS into NativesDir,
+// leaving out the paths listed in each library's extract.exclude. It returns the natives directory.
+func ExtractNatives(dataDir string, manifest *ClientManifest) (string, error) {
+	dir := NativesDir(dataDir, manifest)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	for _, lib := range manifest.Libraries {
+		if !lib.Allowed() {
+			continue
+		}
+		artifact, ok := lib.NativeArtifact()
+		if !ok {
+			continue
+		}
+		jar := filepath.Join(dataDir, "libraries", filepath.FromSlash(artifact.Path))
+		if err := extractNativesJar(jar, dir, lib.Extract.Exclude); err != nil {
+			return "", fmt.Errorf("failed to extract natives of %s: %w", lib.Name, err)
+		}
+	}
+	return dir, nil
+}
+
+func extractNativesJar(jar, dir string, exclude []string) error {
+	zr, err := zip.OpenReader(jar)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	sandbox := newExtractSandbox(dir)
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || isExcluded(f.Name, exclude) {
+			continue
+		}
+		dest, err := sandbox.Join(f.Name)
+		if err != nil {
+			return err
+		}
+		// A running game keeps its natives open, so files that are already in place are left alone.
+		if info, err := os.Stat(dest); err == nil && info.Size() == int64(f.UncompressedSize64) {
+			continue
+		}
+		if err := sandbox.Extract(f, dest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isExcluded(name string, exclude []string) bool {
+	for _, prefix := range exclude {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
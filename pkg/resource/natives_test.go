@@ -0,0 +1,154 @@
+package resource
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLibraryAllowed(t *testing.T) {
+	other := "windows"
+	if osName() == "windows" {
+		other = "linux"
+	}
+	tests := map[string]struct {
+		rules []LibraryRule
+		want  bool
+	}{
+		"no rules":         {nil, true},
+		"allow all":        {[]LibraryRule{{Action: RuleActionAllow}}, true},
+		"allow current os": {[]LibraryRule{{Action: RuleActionAllow, Os: JvmArgumentRuleTypeOS{Name: osName()}}}, true},
+		"allow other os":   {[]LibraryRule{{Action: RuleActionAllow, Os: JvmArgumentRuleTypeOS{Name: other}}}, false},
+		"disallow current os": {[]LibraryRule{
+			{Action: RuleActionAllow},
+			{Action: RuleActionDeny, Os: JvmArgumentRuleTypeOS{Name: osName()}},
+		}, false},
+		"disallow other os": {[]LibraryRule{
+			{Action: RuleActionAllow},
+			{Action: RuleActionDeny, Os: JvmArgumentRuleTypeOS{Name: other}},
+		}, true},
+	}
+	for name, tt := range tests {
+		if got := (Library{Rules: tt.rules}).Allowed(); got != tt.want {
+			t.Errorf("%s: Allowed() = %v, want %v", name, got, tt.want)
+		}
+	}
+}
+
+func TestExtractNatives(t *testing.T) {
+	if osName() == "" {
+		t.Skip("natives are not available for this platform")
+	}
+	dataDir := t.TempDir()
+	lib := Library{
+		Name:    "org.lwjgl.lwjgl:lwjgl-platform:2.9.1",
+		Natives: map[string]string{osName(): "natives-" + osName() + "-${arch}"},
+		Extract: LibraryExtract{Exclude: []string{"META-INF/"}},
+	}
+	artifact, ok := lib.NativeArtifact()
+	if !ok {
+		t.Fatal("expected a natives artifact for the current OS")
+	}
+	if want := "org/lwjgl/lwjgl/lwjgl-platform/2.9.1/lwjgl-platform-2.9.1-natives-" + osName() + "-64.jar"; artifact.Path != want {
+		t.Errorf("NativeArtifact().Path = %q, want %q", artifact.Path, want)
+	}
+	jar := filepath.Join(dataDir, "libraries", filepath.FromSlash(artifact.Path))
+	if err := os.MkdirAll(filepath.Dir(jar), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestZip(t, jar, map[string][]byte{
+		"liblwjgl.so":          []byte("native"),
+		"META-INF/MANIFEST.MF": []byte("Manifest-Version: 1.0"),
+	})
+
+	manifest := &ClientManifest{ID: "1.7.10", Libraries: []Library{
+		lib,
+		{Name: "org.lwjgl:denied:1.0", Natives: lib.Natives, Rules: []LibraryRule{{Action: RuleActionDeny}}},
+	}}
+	dir, err := ExtractNatives(dataDir, manifest)
+	if err != nil {
+		t.Fatalf("ExtractNatives failed: %v", err)
+	}
+	if dir != filepath.Join(dataDir, "bin", "1.7.10") {
+		t.Errorf("unexpected natives directory %s", dir)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "liblwjgl.so")); err != nil || string(data) != "native" {
+		t.Errorf("expected the native library to be extracted, got %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "META-INF")); !os.IsNotExist(err) {
+		t.Error("expected excluded paths to be skipped")
+	}
+}
+
+func TestManifestArgumentsLegacy(t *testing.T) {
+	vanilla := ClientManifest{ID: "1.7.10", MinecraftArguments: "--username ${auth_player_name} --version ${version_name}"}
+	forge := &ClientManifest{
+		ID:                 "1.7.10-Forge10.13.4.1614-1.7.10",
+		InheritsFrom:       "1.7.10",
+		MinecraftArguments: "--username ${auth_player_name} --tweakClass cpw.mods.fml.common.launcher.FMLTweaker",
+	}
+	merged, err := vanilla.InheritsMerge(forge)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jvmArgs, gameArgs := manifestArguments(merged, map[string]bool{"has_custom_resolution": true})
+	if !slices.Contains(jvmArgs, "-Djava.library.path=${natives_directory}") {
+		t.Errorf("expected the natives directory in the JVM arguments, got %v", jvmArgs)
+	}
+	want := []string{"--username", "${auth_player_name}", "--tweakClass", "cpw.mods.fml.common.launcher.FMLTweaker", "--width", "${resolution_width}", "--height", "${resolution_height}"}
+	if !slices.Equal(gameArgs, want) {
+		t.Errorf("game arguments = %v, want %v", gameArgs, want)
+	}
+
+	dataDir := t.TempDir()
+	classpath := manifestClasspath(dataDir, merged)
+	if want := filepath.Join(dataDir, "versions", "1.7.10", "1.7.10.jar"); classpath[0] != want {
+		t.Errorf("expected the inherited client jar %s, got %s", want, classpath[0])
+	}
+}
+
+func TestPrepareGameAssets(t *testing.T) {
+	dataDir := t.TempDir()
+	gameDir := t.TempDir()
+	hash := "0123456789abcdef0123456789abcdef01234567"
+	object := filepath.Join(dataDir, "assets", "objects", hash[:2], hash)
+	if err := os.MkdirAll(filepath.Dir(object), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(object, []byte("ogg"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		index string
+		want  string
+	}{
+		"modern":           {`{"objects":{"sound/a.ogg":{"hash":"` + hash + `","size":3}}}`, filepath.Join(dataDir, "assets")},
+		"virtual":          {`{"virtual":true,"objects":{"sound/a.ogg":{"hash":"` + hash + `","size":3}}}`, filepath.Join(dataDir, "assets", "virtual", "virtual")},
+		"map_to_resources": {`{"map_to_resources":true,"objects":{"sound/a.ogg":{"hash":"` + hash + `","size":3}}}`, filepath.Join(gameDir, "resources")},
+	}
+	for id, tt := range tests {
+		indexPath := filepath.Join(dataDir, "assets", "indexes", id+".json")
+		if err := os.MkdirAll(filepath.Dir(indexPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(indexPath, []byte(tt.index), 0644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := prepareGameAssets(dataDir, &ClientManifest{AssetIndex: AssetIndex{ID: id}}, gameDir)
+		if err != nil {
+			t.Fatalf("%s: %v", id, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: game_assets = %s, want %s", id, got, tt.want)
+		}
+		if id == "modern" {
+			continue
+		}
+		if data, err := os.ReadFile(filepath.Join(got, "sound", "a.ogg")); err != nil || string(data) != "ogg" {
+			t.Errorf("%s: expected the asset to be copied by name, got %q, %v", id, data, err)
+		}
+	}
+}
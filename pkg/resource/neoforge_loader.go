@@ -8,11 +8,7 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"slices"
-
-	"github.com/ikafly144/sabalauncher/v2/pkg/runcmd"
 )
 
 // NeoForgeLoader implements the ModLoader interface for the NeoForge mod loader.
@@ -80,7 +76,7 @@ func (n *NeoForgeLoader) Install(ctx context.Context, inst *Instance) error {
 	defer tmpFile.Close()
 
 	n.progress = 0.1
-	resp, err := http.Get(installerURL)
+	resp, err := MirrorGet(installerURL)
 	if err != nil {
 		return fmt.Errorf("failed to download neoforge installer: %w", err)
 	}
@@ -96,22 +92,8 @@ func (n *NeoForgeLoader) Install(ctx context.Context, inst *Instance) error {
 	tmpFile.Close()
 
 	n.progress = 0.5
-	// 2. Run Installer
-	// NeoForge installer requires a dummy launcher_profiles.json
-	dummyProfiles := filepath.Join(dataPath, "launcher_profiles.json")
-	if _, err := os.Stat(dummyProfiles); os.IsNotExist(err) {
-		if err := os.WriteFile(dummyProfiles, []byte(`{"profiles":{}}`), 0644); err != nil {
-			return err
-		}
-		defer os.Remove(dummyProfiles)
-	}
-
-	cmd := exec.Command("java", "-jar", tmpFile.Name(), "--installClient", dataPath)
-	cmd.Stdout = slog.NewLogLogger(slog.Default().Handler(), slog.LevelInfo).Writer()
-	cmd.Stderr = slog.NewLogLogger(slog.Default().Handler(), slog.LevelInfo).Writer()
-	cmd.SysProcAttr = runcmd.GetSysProcAttr()
-
-	if err := cmd.Run(); err != nil {
+	// 2. Run the installer processors
+	if _, err := RunInstaller(ctx, tmpFile.Name(), dataPath); err != nil {
 		return fmt.Errorf("neoforge installer failed: %w", err)
 	}
 
@@ -134,41 +116,9 @@ func (n *NeoForgeLoader) GenerateLaunchConfig(inst *Instance, features map[strin
 	}
 
 	// 2. Generate Classpath
-	var classpath []string
-	classpath = append(classpath, filepath.Join(dataPath, "versions", manifest.ID, manifest.ID+".jar"))
-	for _, library := range manifest.Libraries {
-		if library.Downloads.Classifiers != nil {
-			for _, classifier := range library.Downloads.Classifiers {
-				classpath = append(classpath, filepath.Join(dataPath, "libraries", classifier.Path))
-			}
-		}
-		if library.Downloads.Artifact.Path != "" {
-			classpath = append(classpath, filepath.Join(dataPath, "libraries", library.Downloads.Artifact.Path))
-		}
-	}
-
-	var jvmArgs []string
-	jvmArgs = append(jvmArgs, "-Xmx"+fmt.Sprintf("%d", memory)+"M")
-	jvmArgs = append(jvmArgs, defaultJvmArgs...)
-
-	for _, arg := range manifest.Arguments.Jvm {
-		if arg == nil {
-			continue
-		}
-		switch arg := arg.(type) {
-		case JvmArgumentString:
-			jvmArgs = append(jvmArgs, arg.String())
-		case JvmArgumentRule:
-			if !slices.ContainsFunc(arg.Rules, func(rule JvmArgumentRuleType) bool {
-				return rule.Action.Allowed() != rule.OS.Matched()
-			}) {
-				continue
-			}
-			jvmArgs = append(jvmArgs, arg.Value...)
-		}
-	}
-
-	gameArgs := EvaluateGameArguments(manifest.Arguments.Game, features)
+	classpath := manifestClasspath(dataPath, manifest)
+	manifestJvmArgs, gameArgs := manifestArguments(manifest, features)
+	jvmArgs := append(baseJvmArgs(inst, memory), manifestJvmArgs...)
 
 	config := &LaunchConfig{
 		MainClass:     manifest.MainClass,
@@ -0,0 +1,89 @@
+package resource
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+)
+
+// ErrNotAvailableOffline is returned when an offline launch needs files that have not been downloaded yet.
+var ErrNotAvailableOffline = errors.New("not available offline, connect to the internet once to download it")
+
+// IsNetworkError reports whether err was caused by a server being unreachable.
+func IsNetworkError(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// resolveClientManifest returns the client manifest of a vanilla version. Online, it is fetched from Mojang;
+// offline or when Mojang cannot be reached, the copy saved next to the client jar is used.
+// It reports whether the setup has to continue offline.
+func resolveClientManifest(dataPath, version string, offline bool) (*ClientManifest, bool, error) {
+	if !offline {
+		ver, err := GetVersion(version)
+		if err == nil {
+			m, err := GetClientManifest(ver)
+			if err == nil {
+				return m, false, nil
+			}
+			if !IsNetworkError(err) {
+				return nil, false, err
+			}
+		} else if !IsNetworkError(err) {
+			return nil, false, err
+		}
+		slog.Warn("Mojang is unreachable, continuing offline", "version", version, "error", err)
+	}
+
+	m, err := GetLocalClientManifest(dataPath, version)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, true, fmt.Errorf("minecraft %s is %w", version, ErrNotAvailableOffline)
+		}
+		return nil, true, fmt.Errorf("failed to read local client manifest: %w", err)
+	}
+	return m, true, nil
+}
+
+// checkOffline fails an offline setup step that still has files to download.
+func checkOffline(ctx *SetupContext, worker *DownloadWorker, what string) error {
+	if !ctx.offline || worker.Remain() == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d %s files are missing and are %w", worker.Remain(), what, ErrNotAvailableOffline)
+}
+
+// fileSHA1Matches reports whether the file at path exists and has the given SHA-1 hash.
+// An empty hash only checks that the file exists.
+func fileSHA1Matches(path, sum string) bool {
+	if sum == "" {
+		_, err := os.Stat(path)
+		return err == nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	h := sha1.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return false
+	}
+	return hex.EncodeToString(h.Sum(nil)) == sum
+}
+
+// librariesPresent reports whether all maven libraries are in the libraries directory.
+func librariesPresent(dataPath string, names []string) bool {
+	for _, name := range names {
+		if _, err := os.Stat(filepath.Join(dataPath, "libraries", filepath.FromSlash(mavenToPath(name, "/")))); err != nil {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,93 @@
+package resource
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveClientManifestOffline(t *testing.T) {
+	dataPath := t.TempDir()
+	if _, _, err := resolveClientManifest(dataPath, "1.20.1", true); !errors.Is(err, ErrNotAvailableOffline) {
+		t.Errorf("expected ErrNotAvailableOffline for a missing manifest, got %v", err)
+	}
+
+	dir := filepath.Join(dataPath, "versions", "1.20.1")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(ClientManifest{ID: "1.20.1", MainClass: "net.minecraft.client.main.Main"})
+	if err := os.WriteFile(filepath.Join(dir, "1.20.1.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	m, offline, err := resolveClientManifest(dataPath, "1.20.1", true)
+	if err != nil {
+		t.Fatalf("resolveClientManifest failed: %v", err)
+	}
+	if !offline || m.ID != "1.20.1" {
+		t.Errorf("unexpected result: offline=%v id=%s", offline, m.ID)
+	}
+}
+
+func TestDownloadAssetsOffline(t *testing.T) {
+	dataPath := t.TempDir()
+	index := []byte(`{"objects":{"icons/icon.png":{"hash":"aabbccdd","size":4}}}`)
+	sum := sha1.Sum(index)
+	manifest := &ClientManifest{AssetIndex: AssetIndex{ID: "17", Sha1: hex.EncodeToString(sum[:])}}
+
+	if _, err := downloadAssets(manifest, dataPath, true); !errors.Is(err, ErrNotAvailableOffline) {
+		t.Errorf("expected ErrNotAvailableOffline without an asset index, got %v", err)
+	}
+
+	indexPath := filepath.Join(dataPath, "assets", "indexes", "17.json")
+	_ = os.MkdirAll(filepath.Dir(indexPath), 0755)
+	if err := os.WriteFile(indexPath, index, 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := &SetupContext{dataPath: dataPath, offline: true}
+	worker, err := downloadAssets(manifest, dataPath, true)
+	if err != nil {
+		t.Fatalf("downloadAssets failed: %v", err)
+	}
+	if err := checkOffline(ctx, worker, "asset"); !errors.Is(err, ErrNotAvailableOffline) {
+		t.Errorf("expected a missing object to fail offline, got %v", err)
+	}
+
+	objectPath := filepath.Join(dataPath, "assets", "objects", "aa", "aabbccdd")
+	_ = os.MkdirAll(filepath.Dir(objectPath), 0755)
+	if err := os.WriteFile(objectPath, []byte("icon"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	worker, err = downloadAssets(manifest, dataPath, true)
+	if err != nil {
+		t.Fatalf("downloadAssets failed: %v", err)
+	}
+	if err := checkOffline(ctx, worker, "asset"); err != nil {
+		t.Errorf("expected nothing to download, got %v", err)
+	}
+
+	// A modified index no longer verifies
+	if err := os.WriteFile(indexPath, []byte(`{"objects":{}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := downloadAssets(manifest, dataPath, true); !errors.Is(err, ErrNotAvailableOffline) {
+		t.Errorf("expected ErrNotAvailableOffline for a modified index, got %v", err)
+	}
+}
+
+func TestIsNetworkError(t *testing.T) {
+	netErr := &url.Error{Op: "Get", URL: "https://example.com", Err: &net.DNSError{Err: "no such host", Name: "example.com"}}
+	if !IsNetworkError(fmt.Errorf("failed to fetch: %w", netErr)) {
+		t.Error("expected a wrapped url.Error to be a network error")
+	}
+	if IsNetworkError(errors.New("failed to fetch manifest")) {
+		t.Error("expected a plain error not to be a network error")
+	}
+}
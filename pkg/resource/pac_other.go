@@ -0,0 +1,13 @@
+//go:build !windows
+
+package resource
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+func pacProxy(pacURL string) (func(*http.Request) (*url.URL, error), error) {
+	return nil, fmt.Errorf("proxy auto-config is only supported on Windows, set a proxy URL instead")
+}
@@ -0,0 +1,121 @@
+//go:build windows
+
+package resource
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	dllwinhttp                = syscall.NewLazyDLL("winhttp.dll")
+	procWinHttpOpen           = dllwinhttp.NewProc("WinHttpOpen")
+	procWinHttpGetProxyForUrl = dllwinhttp.NewProc("WinHttpGetProxyForUrl")
+	dllkernel32               = syscall.NewLazyDLL("kernel32.dll")
+	procGlobalFree            = dllkernel32.NewProc("GlobalFree")
+)
+
+const (
+	winhttpAccessTypeNoProxy    = 1
+	winhttpAccessTypeNamedProxy = 3
+	winhttpAutoProxyConfigURL   = 0x2
+)
+
+type winhttpAutoProxyOptions struct {
+	dwFlags                uint32
+	dwAutoDetectFlags      uint32
+	lpszAutoConfigUrl      *uint16
+	lpvReserved            uintptr
+	dwReserved             uint32
+	fAutoLogonIfChallenged int32
+}
+
+type winhttpProxyInfo struct {
+	dwAccessType    uint32
+	lpszProxy       *uint16
+	lpszProxyBypass *uint16
+}
+
+// pacProxy evaluates the proxy auto-config script at pacURL with WinHTTP. Results are cached per scheme and host.
+func pacProxy(pacURL string) (func(*http.Request) (*url.URL, error), error) {
+	agent, err := syscall.UTF16PtrFromString(UserAgent())
+	if err != nil {
+		return nil, err
+	}
+	session, _, err := procWinHttpOpen.Call(uintptr(unsafe.Pointer(agent)), winhttpAccessTypeNoProxy, 0, 0, 0)
+	if session == 0 {
+		return nil, fmt.Errorf("WinHttpOpen failed: %w", err)
+	}
+	configURL, err := syscall.UTF16PtrFromString(pacURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var cache sync.Map
+	return func(req *http.Request) (*url.URL, error) {
+		key := req.URL.Scheme + "://" + req.URL.Host
+		if u, ok := cache.Load(key); ok {
+			return u.(*url.URL), nil
+		}
+		target, err := syscall.UTF16PtrFromString(req.URL.String())
+		if err != nil {
+			return nil, err
+		}
+		options := winhttpAutoProxyOptions{
+			dwFlags:                winhttpAutoProxyConfigURL,
+			lpszAutoConfigUrl:      configURL,
+			fAutoLogonIfChallenged: 1,
+		}
+		var info winhttpProxyInfo
+		ret, _, err := procWinHttpGetProxyForUrl.Call(session, uintptr(unsafe.Pointer(target)), uintptr(unsafe.Pointer(&options)), uintptr(unsafe.Pointer(&info)))
+		if ret == 0 {
+			return nil, fmt.Errorf("WinHttpGetProxyForUrl failed: %w", err)
+		}
+		var proxy string
+		if info.lpszProxy != nil {
+			proxy = syscall.UTF16ToString(unsafe.Slice(info.lpszProxy, wcslen(info.lpszProxy)))
+			procGlobalFree.Call(uintptr(unsafe.Pointer(info.lpszProxy)))
+		}
+		if info.lpszProxyBypass != nil {
+			procGlobalFree.Call(uintptr(unsafe.Pointer(info.lpszProxyBypass)))
+		}
+
+		var u *url.URL
+		if info.dwAccessType == winhttpAccessTypeNamedProxy {
+			if u, err = parseWinHTTPProxy(proxy); err != nil {
+				return nil, err
+			}
+		}
+		cache.Store(key, u)
+		return u, nil
+	}, nil
+}
+
+// parseWinHTTPProxy returns the first proxy of a WinHTTP proxy list such as "http=proxy:8080;proxy2:8080".
+func parseWinHTTPProxy(list string) (*url.URL, error) {
+	first, _, _ := strings.Cut(strings.TrimSpace(list), ";")
+	first, _, _ = strings.Cut(first, " ")
+	if _, addr, ok := strings.Cut(first, "="); ok {
+		first = addr
+	}
+	if first == "" {
+		return nil, nil
+	}
+	if !strings.Contains(first, "://") {
+		first = "http://" + first
+	}
+	return url.Parse(first)
+}
+
+func wcslen(p *uint16) int {
+	n := 0
+	for ptr := unsafe.Pointer(p); *(*uint16)(ptr) != 0; n++ {
+		ptr = unsafe.Add(ptr, 2)
+	}
+	return n
+}
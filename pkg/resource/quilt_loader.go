@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"os"
@@ -34,7 +35,7 @@ func (q *QuiltLoader) Progress() float32 {
 	if q.worker == nil {
 		return 0.0
 	}
-	return 0.5
+	return q.worker.Stats().Progress()
 }
 
 type QuiltLauncherMeta struct {
@@ -46,15 +47,23 @@ type QuiltLauncherMeta struct {
 type QuiltLibraryInfo struct {
 	Name string `json:"name"`
 	URL  string `json:"url"`
+	// Sha1 is empty if the metadata has no checksum; the one published by the maven repository is used then.
+	Sha1 string `json:"sha1,omitempty"`
 }
 
 // Install handles the downloading of Quilt loader and its dependencies.
 func (q *QuiltLoader) Install(ctx context.Context, inst *Instance) error {
+	if q.installed(DataDir) {
+		slog.Info("Quilt is already installed", "gameVersion", q.GameVersion, "loaderVersion", q.LoaderVersion)
+		q.isInstalled = true
+		return nil
+	}
+
 	slog.Info("Installing Quilt", "gameVersion", q.GameVersion, "loaderVersion", q.LoaderVersion)
 	q.isInstalled = false
 
 	url := fmt.Sprintf("%s/%s/%s/profile/json", QuiltMetaURL, q.GameVersion, q.LoaderVersion)
-	resp, err := http.Get(url)
+	resp, err := MirrorGet(url)
 	if err != nil {
 		return fmt.Errorf("failed to fetch quilt meta: %w", err)
 	}
@@ -84,20 +93,20 @@ func (q *QuiltLoader) Install(ctx context.Context, inst *Instance) error {
 			}
 			downloadURL := libURL + libPath
 
-			q.worker.addTask(func() error {
-				return downloadFile(downloadURL, fullPath)
+			q.worker.addTask(0, func(ctx context.Context, progress io.Writer) error {
+				return downloadFile(ctx, downloadURL, fullPath, lib.Sha1, progress)
 			})
 		}
 	}
 
 	if q.worker.Remain() > 0 {
-		if err := q.worker.Run(); err != nil {
+		if err := q.worker.Run(ctx); err != nil {
 			return fmt.Errorf("failed to download quilt libraries: %w", err)
 		}
 	}
 
 	// Save the meta for launch config generation later
-	metaPath := filepath.Join(dataPath, "versions", q.GameVersion+"-quilt-"+q.LoaderVersion, "quilt-meta.json")
+	metaPath := q.metaPath(dataPath)
 	if err := os.MkdirAll(filepath.Dir(metaPath), 0755); err != nil {
 		return err
 	}
@@ -119,17 +128,9 @@ func (q *QuiltLoader) Install(ctx context.Context, inst *Instance) error {
 // GenerateLaunchConfig produces the configuration required to launch the game with Quilt.
 func (q *QuiltLoader) GenerateLaunchConfig(inst *Instance, features map[string]bool, memory uint64) (*LaunchConfig, error) {
 	dataPath := DataDir
-	metaPath := filepath.Join(dataPath, "versions", q.GameVersion+"-quilt-"+q.LoaderVersion, "quilt-meta.json")
-
-	file, err := os.Open(metaPath)
+	meta, err := q.readMeta(dataPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open quilt meta: %w", err)
-	}
-	defer file.Close()
-
-	var meta QuiltLauncherMeta
-	if err := json.NewDecoder(file).Decode(&meta); err != nil {
-		return nil, fmt.Errorf("failed to decode quilt meta: %w", err)
+		return nil, err
 	}
 
 	// 1. Get Vanilla Launch Config as base
@@ -153,3 +154,34 @@ func (q *QuiltLoader) GenerateLaunchConfig(inst *Instance, features map[string]b
 
 	return config, nil
 }
+
+func (q *QuiltLoader) metaPath(dataPath string) string {
+	return filepath.Join(dataPath, "versions", q.GameVersion+"-quilt-"+q.LoaderVersion, "quilt-meta.json")
+}
+
+func (q *QuiltLoader) readMeta(dataPath string) (*QuiltLauncherMeta, error) {
+	file, err := os.Open(q.metaPath(dataPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open quilt meta: %w", err)
+	}
+	defer file.Close()
+
+	var meta QuiltLauncherMeta
+	if err := json.NewDecoder(file).Decode(&meta); err != nil {
+		return nil, fmt.Errorf("failed to decode quilt meta: %w", err)
+	}
+	return &meta, nil
+}
+
+// installed reports whether the saved meta and all of its libraries are on disk.
+func (q *QuiltLoader) installed(dataPath string) bool {
+	meta, err := q.readMeta(dataPath)
+	if err != nil {
+		return false
+	}
+	names := make([]string, 0, len(meta.Libraries))
+	for _, lib := range meta.Libraries {
+		names = append(names, lib.Name)
+	}
+	return librariesPresent(dataPath, names)
+}
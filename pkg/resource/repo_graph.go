@@ -0,0 +1,292 @@
+package resource
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Release channels of repository entries. An instance subscribed to a channel receives the entries of that channel
+// and of the more stable ones.
+const (
+	ChannelStable = "stable"
+	ChannelBeta   = "beta"
+	ChannelDev    = "dev"
+)
+
+// Channels lists the release channels, most stable first.
+var Channels = []string{ChannelStable, ChannelBeta, ChannelDev}
+
+// channelRank orders the channels by stability. The empty channel is stable.
+var channelRank = map[string]int{"": 0, ChannelStable: 0, ChannelBeta: 1, ChannelDev: 2}
+
+// IsValidChannel reports whether channel is a known release channel. The empty channel is stable.
+func IsValidChannel(channel string) bool {
+	_, ok := channelRank[channel]
+	return ok
+}
+
+// ChannelIncludes reports whether an instance subscribed to channel receives entries published to entryChannel.
+func ChannelIncludes(channel, entryChannel string) bool {
+	s, ok := channelRank[channel]
+	if !ok {
+		return false
+	}
+	e, ok := channelRank[entryChannel]
+	return ok && e <= s
+}
+
+// unknownPackCost is the cost of a full pack whose size the manifest does not record. It makes a chain of patches
+// of unknown size preferable to a full download.
+const unknownPackCost = 1 << 40
+
+// RepoGraph is the update graph of a repository manifest. Every entry is a version: a patch leads to it from the
+// version it is based on, and a full pack leads to it from anywhere.
+type RepoGraph struct {
+	entries []SBRepoPatch
+	byID    map[string]int
+	byIndex map[string]int
+	// node maps each entry to the first entry producing the same index, so entries of one version share a node.
+	node []int
+	// base holds the node each patch applies to, -1 for packs and -2 for patches whose base is not in the manifest.
+	base []int
+}
+
+// NewRepoGraph builds the update graph of repo.
+func NewRepoGraph(repo *SBRepository) *RepoGraph {
+	g := &RepoGraph{
+		entries: repo.Patches,
+		byID:    make(map[string]int, len(repo.Patches)),
+		byIndex: make(map[string]int, len(repo.Patches)),
+		node:    make([]int, len(repo.Patches)),
+		base:    make([]int, len(repo.Patches)),
+	}
+	for i, e := range g.entries {
+		if _, ok := g.byID[e.ID]; !ok {
+			g.byID[e.ID] = i
+		}
+		g.node[i] = i
+		if e.IndexID != "" {
+			if n, ok := g.byIndex[e.IndexID]; ok {
+				g.node[i] = n
+			} else {
+				g.byIndex[e.IndexID] = i
+			}
+		}
+	}
+	for i, e := range g.entries {
+		switch {
+		case e.Type == SBPatchTypePack:
+			g.base[i] = -1
+		case e.BaseID != "":
+			if b, ok := g.lookup(e.BaseID); ok {
+				g.base[i] = g.node[b]
+			} else {
+				g.base[i] = -2
+			}
+		case i > 0:
+			// Manifests written before base IDs were recorded list each patch after its base.
+			g.base[i] = g.node[i-1]
+		default:
+			g.base[i] = -2
+		}
+	}
+	return g
+}
+
+// lookup returns an entry of version, which is either a manifest entry ID or the index ID of an entry.
+func (g *RepoGraph) lookup(version string) (int, bool) {
+	if i, ok := g.byID[version]; ok {
+		return i, true
+	}
+	i, ok := g.byIndex[version]
+	return i, ok
+}
+
+// Latest returns the newest entry of the manifest, or nil if it is empty.
+func (g *RepoGraph) Latest() *SBRepoPatch {
+	if len(g.entries) == 0 {
+		return nil
+	}
+	return &g.entries[len(g.entries)-1]
+}
+
+// LatestIn returns the newest entry published to channel or a more stable one, or nil if there is none.
+func (g *RepoGraph) LatestIn(channel string) *SBRepoPatch {
+	for i := len(g.entries) - 1; i >= 0; i-- {
+		if ChannelIncludes(channel, g.entries[i].Channel) {
+			return &g.entries[i]
+		}
+	}
+	return nil
+}
+
+// Versions returns one entry for each version of the manifest, in manifest order. Of the entries producing the same
+// version, the first one is returned, with the most stable channel any of them is published to.
+func (g *RepoGraph) Versions() []SBRepoPatch {
+	versions := make([]SBRepoPatch, 0, len(g.entries))
+	at := make(map[int]int, len(g.entries))
+	for i, e := range g.entries {
+		if n := g.node[i]; n != i {
+			if v := &versions[at[n]]; ChannelIncludes(v.Channel, e.Channel) {
+				v.Channel = e.Channel
+			}
+			continue
+		}
+		at[i] = len(versions)
+		versions = append(versions, e)
+	}
+	return versions
+}
+
+// IsVersion reports whether version, an entry ID or an index ID, is the version the entry with the given ID
+// produces.
+func (g *RepoGraph) IsVersion(version, id string) bool {
+	if version == id {
+		return true
+	}
+	i, ok := g.byID[id]
+	if !ok {
+		return false
+	}
+	v, ok := g.lookup(version)
+	return ok && g.node[v] == g.node[i]
+}
+
+// IsNewer reports whether the entry with the given ID produces a version published after version, an entry ID or an
+// index ID. A version that is not in the manifest is older than every entry.
+func (g *RepoGraph) IsNewer(id, version string) bool {
+	i, ok := g.byID[id]
+	if !ok {
+		return false
+	}
+	v, ok := g.lookup(version)
+	return !ok || g.node[i] > g.node[v]
+}
+
+func (g *RepoGraph) cost(i int) int64 {
+	switch e := g.entries[i]; {
+	case e.Size > 0:
+		return e.Size
+	case e.Type == SBPatchTypePack:
+		return unknownPackCost
+	default:
+		return 1
+	}
+}
+
+// ResolvePath returns the entries to apply, in order, to update from version from to the entry with ID to. It is the
+// path with the smallest total download size; a full pack is used when no chain of patches leads from the
+// installed version. An empty from, or one the manifest does not know, starts from a full pack.
+//
+// Only entries an instance subscribed to channel receives are applied. A target outside channel, chosen explicitly,
+// widens it to the channel of the target.
+func (g *RepoGraph) ResolvePath(from, to, channel string) ([]SBRepoPatch, error) {
+	t, ok := g.byID[to]
+	if !ok {
+		return nil, fmt.Errorf("version '%s' not found in repository manifest", to)
+	}
+	if !ChannelIncludes(channel, g.entries[t].Channel) {
+		channel = g.entries[t].Channel
+	}
+	target := g.node[t]
+	start := -1
+	if from != "" {
+		if s, ok := g.lookup(from); ok {
+			start = g.node[s]
+		}
+	}
+	if start == target {
+		return nil, nil
+	}
+
+	// labels is indexed by node. via is the entry applied to reach the node and prev the node it was applied
+	// to, or -1 for a full pack.
+	type label struct {
+		cost    int64
+		hops    int
+		via     int
+		prev    int
+		reached bool
+	}
+	labels := make([]label, len(g.entries))
+	better := func(a, b label) bool {
+		return !b.reached || a.cost < b.cost || (a.cost == b.cost && a.hops < b.hops)
+	}
+	if start >= 0 {
+		labels[start] = label{reached: true, via: -1, prev: -1}
+	}
+	// A full pack can be applied from any version. Newer packs win ties.
+	for i := len(g.entries) - 1; i >= 0; i-- {
+		if g.base[i] != -1 || g.node[i] == start || !ChannelIncludes(channel, g.entries[i].Channel) {
+			continue
+		}
+		if l := (label{cost: g.cost(i), hops: 1, via: i, prev: -1, reached: true}); better(l, labels[g.node[i]]) {
+			labels[g.node[i]] = l
+		}
+	}
+
+	done := make([]bool, len(g.entries))
+	for {
+		u := -1
+		for n, l := range labels {
+			if l.reached && !done[n] && (u < 0 || better(l, labels[u])) {
+				u = n
+			}
+		}
+		if u < 0 || u == target {
+			break
+		}
+		done[u] = true
+		for i := range g.entries {
+			n := g.node[i]
+			if g.base[i] != u || done[n] || !ChannelIncludes(channel, g.entries[i].Channel) {
+				continue
+			}
+			l := label{cost: labels[u].cost + g.cost(i), hops: labels[u].hops + 1, via: i, prev: u, reached: true}
+			if better(l, labels[n]) {
+				labels[n] = l
+			}
+		}
+	}
+
+	if !labels[target].reached {
+		if from == "" {
+			return nil, fmt.Errorf("no sbpack leads to '%s'", to)
+		}
+		return nil, fmt.Errorf("failed to find update path from '%s' to '%s'", from, to)
+	}
+	var path []SBRepoPatch
+	for n := target; n >= 0 && labels[n].via >= 0; n = labels[n].prev {
+		path = append(path, g.entries[labels[n].via])
+	}
+	slices.Reverse(path)
+	return path, nil
+}
+
+// Validate checks that entry IDs are unique, that every entry is published to a known channel, that every patch
+// applies to a version of the manifest and that the newest version of each channel can be installed from a full pack.
+func (g *RepoGraph) Validate() error {
+	seen := make(map[string]bool, len(g.entries))
+	for i, e := range g.entries {
+		if seen[e.ID] {
+			return fmt.Errorf("duplicate entry %s", e.ID)
+		}
+		seen[e.ID] = true
+		if !IsValidChannel(e.Channel) {
+			return fmt.Errorf("unknown channel '%s' of entry %s", e.Channel, e.ID)
+		}
+		if g.base[i] == -2 {
+			return fmt.Errorf("reached dead end at %s: baseID %s not found in manifest", e.ID, e.BaseID)
+		}
+	}
+	for _, channel := range Channels {
+		latest := g.LatestIn(channel)
+		if latest == nil {
+			continue
+		}
+		if _, err := g.ResolvePath("", latest.ID, channel); err != nil {
+			return fmt.Errorf("reached dead end before finding an sbpack: %w", err)
+		}
+	}
+	return nil
+}
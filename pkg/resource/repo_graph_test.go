@@ -0,0 +1,183 @@
+package resource
+
+import (
+	"slices"
+	"testing"
+)
+
+func pathIDs(path []SBRepoPatch) []string {
+	ids := make([]string, len(path))
+	for i, p := range path {
+		ids[i] = p.ID
+	}
+	return ids
+}
+
+func TestRepoGraphResolvePath(t *testing.T) {
+	// v1 is the base pack. v2a and v2b are two branches off v1, v3 continues v2b, v4 skips ahead from v1, and v5
+	// is a full pack of the latest version next to the patch from v4.
+	repo := &SBRepository{Patches: []SBRepoPatch{
+		{ID: "v1", Type: SBPatchTypePack, IndexID: "i1", Size: 1000},
+		{ID: "v2a", Type: SBPatchTypePatch, IndexID: "i2a", BaseID: "i1", Size: 10},
+		{ID: "v2b", Type: SBPatchTypePatch, IndexID: "i2b", BaseID: "i1", Size: 10},
+		{ID: "v3", Type: SBPatchTypePatch, IndexID: "i3", BaseID: "i2b", Size: 10},
+		{ID: "v4", Type: SBPatchTypePatch, IndexID: "i4", BaseID: "i1", Size: 50},
+		{ID: "v5", Type: SBPatchTypePack, IndexID: "i5", Size: 900},
+		{ID: "v5p", Type: SBPatchTypePatch, IndexID: "i5", BaseID: "i4", Size: 5},
+	}}
+	g := NewRepoGraph(repo)
+	tests := map[string]struct {
+		from, to string
+		want     []string
+	}{
+		"branch":             {"v1", "v3", []string{"v2b", "v3"}},
+		"by index id":        {"i2b", "v3", []string{"v3"}},
+		"skip ahead":         {"v1", "v5p", []string{"v4", "v5p"}},
+		"pack when no chain": {"v2a", "v5", []string{"v5"}},
+		"unknown version":    {"gone", "v3", []string{"v1", "v2b", "v3"}},
+		"fresh install":      {"", "v5", []string{"v5"}},
+		"up to date":         {"v3", "v3", nil},
+	}
+	for name, tt := range tests {
+		path, err := g.ResolvePath(tt.from, tt.to, ChannelStable)
+		if err != nil {
+			t.Errorf("%s: ResolvePath failed: %v", name, err)
+			continue
+		}
+		if got := pathIDs(path); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: ResolvePath(%q, %q) = %v, want %v", name, tt.from, tt.to, got, tt.want)
+		}
+	}
+	if got := pathIDs(g.Versions()); !slices.Equal(got, []string{"v1", "v2a", "v2b", "v3", "v4", "v5"}) {
+		t.Errorf("Versions() = %v", got)
+	}
+	// Rolling back goes through the newest pack at or before the target
+	if path, err := g.ResolvePath("v5", "v3", ChannelStable); err != nil || !slices.Equal(pathIDs(path), []string{"v1", "v2b", "v3"}) {
+		t.Errorf("ResolvePath(v5, v3) = %v, %v", pathIDs(path), err)
+	}
+	if _, err := g.ResolvePath("v1", "missing", ChannelStable); err == nil {
+		t.Error("expected an unknown target to fail")
+	}
+	if err := g.Validate(); err != nil {
+		t.Errorf("Validate failed: %v", err)
+	}
+}
+
+func TestRepoGraphPrefersPatchesOfUnknownSize(t *testing.T) {
+	// Manifests without sizes or base IDs apply the patches in manifest order, as before.
+	repo := &SBRepository{Patches: []SBRepoPatch{
+		{ID: "v1", Type: SBPatchTypePack},
+		{ID: "v2", Type: SBPatchTypePatch},
+		{ID: "v3", Type: SBPatchTypePack},
+		{ID: "v4", Type: SBPatchTypePatch},
+		{ID: "v5", Type: SBPatchTypePatch},
+	}}
+	g := NewRepoGraph(repo)
+	path, err := g.ResolvePath("v1", "v5", ChannelStable)
+	if err != nil {
+		t.Fatalf("ResolvePath failed: %v", err)
+	}
+	// v3 is a pack, so no patch leads from v2 to it and the newest pack is used.
+	if got := pathIDs(path); !slices.Equal(got, []string{"v3", "v4", "v5"}) {
+		t.Errorf("ResolvePath = %v", got)
+	}
+	if path, _ := g.ResolvePath("v3", "v5", ChannelStable); !slices.Equal(pathIDs(path), []string{"v4", "v5"}) {
+		t.Errorf("ResolvePath from v3 = %v", pathIDs(path))
+	}
+	if !g.IsVersion("v5", g.Latest().ID) {
+		t.Error("expected v5 to be the latest version")
+	}
+}
+
+func TestRepoGraphValidate(t *testing.T) {
+	tests := map[string][]SBRepoPatch{
+		"dangling base": {
+			{ID: "v1", Type: SBPatchTypePack, IndexID: "i1"},
+			{ID: "v2", Type: SBPatchTypePatch, IndexID: "i2", BaseID: "other"},
+		},
+		"no pack": {
+			{ID: "v1", Type: SBPatchTypePatch, IndexID: "i1", BaseID: "i2"},
+			{ID: "v2", Type: SBPatchTypePatch, IndexID: "i2", BaseID: "i1"},
+		},
+		"duplicate": {
+			{ID: "v1", Type: SBPatchTypePack},
+			{ID: "v1", Type: SBPatchTypePack},
+		},
+	}
+	for name, patches := range tests {
+		if err := NewRepoGraph(&SBRepository{Patches: patches}).Validate(); err == nil {
+			t.Errorf("%s: expected Validate to fail", name)
+		}
+	}
+}
+
+func TestRepoGraphChannels(t *testing.T) {
+	// v3 is a beta build on top of the stable v2 and v4 a dev build on top of v3. v2p is a patch producing v2, only
+	// published to beta.
+	repo := &SBRepository{Patches: []SBRepoPatch{
+		{ID: "v1", Type: SBPatchTypePack, IndexID: "i1"},
+		{ID: "v2", Type: SBPatchTypePack, IndexID: "i2", Channel: ChannelStable},
+		{ID: "v2p", Type: SBPatchTypePatch, IndexID: "i2", BaseID: "i1", Channel: ChannelBeta},
+		{ID: "v3", Type: SBPatchTypePatch, IndexID: "i3", BaseID: "i2", Channel: ChannelBeta},
+		{ID: "v4", Type: SBPatchTypePatch, IndexID: "i4", BaseID: "i3", Channel: ChannelDev},
+	}}
+	g := NewRepoGraph(repo)
+	for channel, want := range map[string]string{"": "v2", ChannelStable: "v2", ChannelBeta: "v3", ChannelDev: "v4"} {
+		latest := g.LatestIn(channel)
+		if latest == nil || !g.IsVersion(latest.ID, want) {
+			t.Errorf("LatestIn(%q) = %v, want %s", channel, latest, want)
+		}
+	}
+	if latest := g.LatestIn("nightly"); latest != nil {
+		t.Errorf("expected no entry for an unknown channel, got %s", latest.ID)
+	}
+
+	// Leaving dev for stable is not an update; the newest stable version is older than v4
+	if g.IsNewer(g.LatestIn(ChannelStable).ID, "v4") || !g.IsNewer("v4", "i2") || g.IsNewer("v2p", "v2") {
+		t.Errorf("unexpected manifest order of versions")
+	}
+	if !g.IsNewer("v1", "unknown") {
+		t.Errorf("expected a version missing from the manifest to be older than every entry")
+	}
+
+	// Choosing the newest stable version explicitly rolls back to it
+	if path, err := g.ResolvePath("v4", g.LatestIn(ChannelStable).ID, ChannelStable); err != nil || !slices.Equal(pathIDs(path), []string{"v2"}) {
+		t.Errorf("ResolvePath(v4, stable) = %v, %v", pathIDs(path), err)
+	}
+
+	// The beta patch v2p is cheaper than the pack of v2, but stable instances must not apply it
+	if path, err := g.ResolvePath("v1", "v2", ChannelStable); err != nil || !slices.Equal(pathIDs(path), []string{"v2"}) {
+		t.Errorf("ResolvePath(v1, v2, stable) = %v, %v", pathIDs(path), err)
+	}
+	if path, err := g.ResolvePath("v1", "v2", ChannelBeta); err != nil || !slices.Equal(pathIDs(path), []string{"v2p"}) {
+		t.Errorf("ResolvePath(v1, v2, beta) = %v, %v", pathIDs(path), err)
+	}
+	// Choosing a dev version explicitly allows dev entries on the way
+	if path, err := g.ResolvePath("v1", "v4", ChannelStable); err != nil || !slices.Equal(pathIDs(path), []string{"v2p", "v3", "v4"}) {
+		t.Errorf("ResolvePath(v1, v4, stable) = %v, %v", pathIDs(path), err)
+	}
+
+	versions := g.Versions()
+	if got := pathIDs(versions); !slices.Equal(got, []string{"v1", "v2", "v3", "v4"}) {
+		t.Fatalf("Versions() = %v", got)
+	}
+	if versions[1].Channel != ChannelStable || versions[2].Channel != ChannelBeta {
+		t.Errorf("unexpected channels of versions: %+v", versions)
+	}
+
+	if err := g.Validate(); err != nil {
+		t.Errorf("Validate failed: %v", err)
+	}
+	// The newest stable version must be installable from stable entries alone
+	devPack := NewRepoGraph(&SBRepository{Patches: []SBRepoPatch{
+		{ID: "v1", Type: SBPatchTypePack, IndexID: "i1", Channel: ChannelDev},
+		{ID: "v2", Type: SBPatchTypePatch, IndexID: "i2", BaseID: "i1"},
+	}})
+	if err := devPack.Validate(); err == nil {
+		t.Error("expected a stable version reachable only through a dev pack to fail validation")
+	}
+	repo.Patches[4].Channel = "nightly"
+	if err := NewRepoGraph(repo).Validate(); err == nil {
+		t.Error("expected an unknown channel to fail validation")
+	}
+}
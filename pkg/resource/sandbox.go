@@ -0,0 +1,247 @@
+package resource
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// MaxExtractSize is the largest total uncompressed size extracted from a single pack or patch.
+	MaxExtractSize int64 = 8 << 30
+	// MaxExtractEntries is the largest number of entries in a single pack or patch.
+	MaxExtractEntries = 100000
+)
+
+var (
+	// ErrUnsafePath is returned when a pack refers to a file outside the instance directory.
+	ErrUnsafePath = errors.New("unsafe path")
+	// ErrExtractLimit is returned when a pack exceeds MaxExtractSize or MaxExtractEntries.
+	ErrExtractLimit = errors.New("archive exceeds extraction limits")
+)
+
+// SafeJoin joins a slash-separated path taken from a pack to root. It fails with ErrUnsafePath if the
+// path is absolute, leaves root, names a Windows device, or passes through a symlink that leads outside root.
+func SafeJoin(root, rel string) (string, error) {
+	clean, err := cleanPackPath(rel)
+	if err != nil {
+		return "", err
+	}
+	if err := checkSymlinks(root, clean); err != nil {
+		return "", err
+	}
+	return filepath.Join(root, filepath.FromSlash(clean)), nil
+}
+
+// cleanPackPath validates a path taken from a pack and returns it cleaned.
+// Paths the launcher keeps for itself in the instance directory are refused as well.
+func cleanPackPath(rel string) (string, error) {
+	if rel == "" || strings.ContainsRune(rel, 0) {
+		return "", fmt.Errorf("%w: %q", ErrUnsafePath, rel)
+	}
+	// Zip names always use slashes, but Windows also treats backslashes as separators.
+	p := strings.ReplaceAll(rel, "\\", "/")
+	if strings.HasPrefix(p, "/") || filepath.IsAbs(p) || filepath.VolumeName(p) != "" {
+		return "", fmt.Errorf("%w: %s is absolute", ErrUnsafePath, rel)
+	}
+	p = path.Clean(p)
+	if p == "." || p == ".." || strings.HasPrefix(p, "../") {
+		return "", fmt.Errorf("%w: %s leaves the instance directory", ErrUnsafePath, rel)
+	}
+	for part := range strings.SplitSeq(p, "/") {
+		// A colon makes a drive-relative path or an alternate data stream on Windows.
+		if strings.ContainsRune(part, ':') || isDeviceName(part) {
+			return "", fmt.Errorf("%w: %s is not a regular file name", ErrUnsafePath, rel)
+		}
+	}
+	if isReservedPath(p) {
+		return "", fmt.Errorf("%w: %s is reserved for the launcher", ErrUnsafePath, rel)
+	}
+	return p, nil
+}
+
+// isReservedPath reports whether a cleaned path lies in the update journal or is the installed pack index.
+// Windows ignores case and trailing dots and spaces, so they are ignored here too.
+func isReservedPath(p string) bool {
+	first, _, _ := strings.Cut(p, "/")
+	first = strings.ToLower(strings.TrimRight(first, ". "))
+	return first == updateJournalDir || first == "sb.index.json"
+}
+
+// isDeviceName reports whether a path element names a reserved Windows device such as CON or COM1.
+// Packs are shared between platforms, so these are rejected everywhere.
+func isDeviceName(part string) bool {
+	base, _, _ := strings.Cut(part, ".")
+	base = strings.ToUpper(strings.TrimRight(base, " "))
+	switch base {
+	case "CON", "PRN", "AUX", "NUL", "CONIN$", "CONOUT$":
+		return true
+	}
+	if len(base) == 4 && (strings.HasPrefix(base, "COM") || strings.HasPrefix(base, "LPT")) {
+		return base[3] >= '0' && base[3] <= '9'
+	}
+	return false
+}
+
+// checkSymlinks fails if an existing element of rel below root is a symlink that leads outside root.
+func checkSymlinks(root, rel string) error {
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to resolve %s: %w", root, err)
+	}
+	cur := root
+	for part := range strings.SplitSeq(rel, "/") {
+		cur = filepath.Join(cur, part)
+		info, err := os.Lstat(cur)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			continue
+		}
+		target, err := filepath.EvalSymlinks(cur)
+		if err != nil || !isWithin(realRoot, target) {
+			return fmt.Errorf("%w: %s escapes the instance directory through a symlink", ErrUnsafePath, rel)
+		}
+	}
+	return nil
+}
+
+func isWithin(root, p string) bool {
+	rel, err := filepath.Rel(root, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
+
+// extractSandbox writes files taken from a pack below a root directory. Every path goes through
+// SafeJoin, and the number of extracted entries and bytes is limited.
+type extractSandbox struct {
+	root       string
+	maxSize    int64
+	maxEntries int
+	size       int64
+	entries    int
+}
+
+func newExtractSandbox(root string) *extractSandbox {
+	return &extractSandbox{
+		root:       root,
+		maxSize:    MaxExtractSize,
+		maxEntries: MaxExtractEntries,
+	}
+}
+
+// Join resolves a pack path below the sandbox root.
+func (s *extractSandbox) Join(rel string) (string, error) {
+	return SafeJoin(s.root, rel)
+}
+
+// CheckArchive fails early if the entries of an archive declare more than the limits allow.
+// The limits are enforced again while extracting, since the declared sizes can lie.
+func (s *extractSandbox) CheckArchive(r *zip.Reader) error {
+	if len(r.File) > s.maxEntries {
+		return fmt.Errorf("%w: %d entries (max %d)", ErrExtractLimit, len(r.File), s.maxEntries)
+	}
+	var total uint64
+	for _, f := range r.File {
+		total += f.UncompressedSize64
+		if total > uint64(s.maxSize) {
+			return fmt.Errorf("%w: more than %d bytes uncompressed", ErrExtractLimit, s.maxSize)
+		}
+	}
+	return nil
+}
+
+// MkdirAll creates a directory taken from a pack.
+func (s *extractSandbox) MkdirAll(rel string) error {
+	dir, err := s.Join(rel)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(dir, 0755)
+}
+
+// Extract writes a zip entry to dest, which must come from Join, replacing any existing file.
+func (s *extractSandbox) Extract(f *zip.File, dest string) error {
+	if f.Mode()&os.ModeSymlink != 0 {
+		return fmt.Errorf("%w: %s is a symlink", ErrUnsafePath, f.Name)
+	}
+	if err := s.reserve(1, int64(f.UncompressedSize64)); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	out, err := createFile(dest)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	// The declared size was reserved above; refuse anything beyond it.
+	n, err := io.Copy(out, io.LimitReader(rc, int64(f.UncompressedSize64)+1))
+	if err != nil {
+		return err
+	}
+	if n > int64(f.UncompressedSize64) {
+		return fmt.Errorf("%w: %s is larger than declared", ErrExtractLimit, f.Name)
+	}
+	return nil
+}
+
+// ReservePatch checks the sizes declared in the header of a bsdiff patch of patchSize bytes before it is
+// applied, since the patcher allocates them up front. It returns a reader that yields the whole patch again.
+func (s *extractSandbox) ReservePatch(patch io.Reader, patchSize int64) (io.Reader, error) {
+	header := make([]byte, 32)
+	if _, err := io.ReadFull(patch, header); err != nil {
+		return nil, fmt.Errorf("failed to read patch header: %w", err)
+	}
+	// Sizes in a bsdiff header are sign-magnitude little-endian integers.
+	ctrlLen := binary.LittleEndian.Uint64(header[8:16])
+	diffLen := binary.LittleEndian.Uint64(header[16:24])
+	newSize := binary.LittleEndian.Uint64(header[24:32])
+	if ctrlLen > uint64(patchSize) || diffLen > uint64(patchSize)-ctrlLen {
+		return nil, fmt.Errorf("%w: patch blocks are larger than the patch", ErrExtractLimit)
+	}
+	if newSize&(1<<63) != 0 {
+		return nil, fmt.Errorf("invalid patch header: negative size")
+	}
+	if err := s.reserve(1, int64(newSize)); err != nil {
+		return nil, err
+	}
+	return io.MultiReader(bytes.NewReader(header), patch), nil
+}
+
+func (s *extractSandbox) reserve(entries int, size int64) error {
+	if size < 0 {
+		return fmt.Errorf("%w: invalid size", ErrExtractLimit)
+	}
+	if s.entries+entries > s.maxEntries {
+		return fmt.Errorf("%w: more than %d entries", ErrExtractLimit, s.maxEntries)
+	}
+	if s.size+size > s.maxSize || s.size+size < s.size {
+		return fmt.Errorf("%w: more than %d bytes uncompressed", ErrExtractLimit, s.maxSize)
+	}
+	s.entries += entries
+	s.size += size
+	return nil
+}
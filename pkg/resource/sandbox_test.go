@@ -0,0 +1,294 @@
+package resource
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestCleanPackPath(t *testing.T) {
+	valid := map[string]string{
+		"mods/a.jar":          "mods/a.jar",
+		"config//b.toml":      "config/b.toml",
+		"config/./c.toml":     "config/c.toml",
+		"config/x/../d.toml":  "config/d.toml",
+		"config\\windows.cfg": "config/windows.cfg",
+		"console.log":         "console.log",
+	}
+	for in, want := range valid {
+		got, err := cleanPackPath(in)
+		if err != nil || got != want {
+			t.Errorf("cleanPackPath(%q) = %q, %v, want %q", in, got, err, want)
+		}
+	}
+
+	invalid := []string{
+		"",
+		".",
+		"..",
+		"../evil",
+		"mods/../../evil",
+		"..\\evil",
+		"/etc/passwd",
+		"\\\\server\\share\\file",
+		"C:/Windows/evil.dll",
+		"c:evil",
+		"mods/a.jar:stream",
+		"CON",
+		"config/nul.txt",
+		"con.d/file",
+		"logs/COM1",
+		"LPT9.log",
+		"a\x00b",
+		".sbupdate/journal.jsonl",
+		".SBUpdate/backup/0/sb.index.json",
+		"config/../.sbupdate/journal.jsonl",
+		"sb.index.json",
+		"SB.Index.JSON. ",
+	}
+	for _, in := range invalid {
+		if _, err := cleanPackPath(in); !errors.Is(err, ErrUnsafePath) {
+			t.Errorf("cleanPackPath(%q) = %v, want ErrUnsafePath", in, err)
+		}
+	}
+}
+
+func TestSafeJoinSymlinkEscape(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "inside"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+		t.Skipf("symlinks are not supported: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(root, "inside"), filepath.Join(root, "alias")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := SafeJoin(root, "escape/evil.txt"); !errors.Is(err, ErrUnsafePath) {
+		t.Errorf("expected a symlink leaving the root to be rejected, got %v", err)
+	}
+	if _, err := SafeJoin(root, "escape"); !errors.Is(err, ErrUnsafePath) {
+		t.Errorf("expected a symlink target leaving the root to be rejected, got %v", err)
+	}
+	got, err := SafeJoin(root, "alias/ok.txt")
+	if err != nil {
+		t.Fatalf("expected a symlink within the root to be allowed, got %v", err)
+	}
+	if want := filepath.Join(root, "alias", "ok.txt"); got != want {
+		t.Errorf("SafeJoin = %q, want %q", got, want)
+	}
+}
+
+func openTestZip(t *testing.T, files map[string][]byte) *zip.Reader {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.zip")
+	writeTestZip(t, path, files)
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { r.Close() })
+	return &r.Reader
+}
+
+func TestExtractSandboxLimits(t *testing.T) {
+	r := openTestZip(t, map[string][]byte{
+		"a.txt": bytes.Repeat([]byte("a"), 600),
+		"b.txt": bytes.Repeat([]byte("b"), 600),
+	})
+
+	s := newExtractSandbox(t.TempDir())
+	s.maxSize = 1000
+	if err := s.CheckArchive(r); !errors.Is(err, ErrExtractLimit) {
+		t.Errorf("expected the declared size to exceed the limit, got %v", err)
+	}
+
+	s = newExtractSandbox(t.TempDir())
+	s.maxEntries = 1
+	if err := s.CheckArchive(r); !errors.Is(err, ErrExtractLimit) {
+		t.Errorf("expected the entry count to exceed the limit, got %v", err)
+	}
+
+	// The limits also hold while extracting, whatever the archive declared up front.
+	s = newExtractSandbox(t.TempDir())
+	s.maxSize = 1000
+	var errs []error
+	for _, f := range r.File {
+		dest, err := s.Join(f.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		errs = append(errs, s.Extract(f, dest))
+	}
+	if errs[0] != nil || !errors.Is(errs[1], ErrExtractLimit) {
+		t.Errorf("expected only the second entry to exceed the limit, got %v", errs)
+	}
+}
+
+func TestReservePatchLimits(t *testing.T) {
+	header := make([]byte, 32)
+	copy(header, "BSDIFF40")
+	header[24+4] = 1 // new size of 4 GiB
+
+	s := newExtractSandbox(t.TempDir())
+	s.maxSize = 1 << 30
+	if _, err := s.ReservePatch(bytes.NewReader(header), int64(len(header))); !errors.Is(err, ErrExtractLimit) {
+		t.Errorf("expected the patched size to exceed the limit, got %v", err)
+	}
+
+	header[24+4] = 0
+	header[8+4] = 1 // control block larger than the patch
+	if _, err := s.ReservePatch(bytes.NewReader(header), int64(len(header))); !errors.Is(err, ErrExtractLimit) {
+		t.Errorf("expected an oversized control block to be rejected, got %v", err)
+	}
+}
+
+func TestRepoPatchLocalPathStaysInCache(t *testing.T) {
+	oldDataDir := DataDir
+	DataDir = t.TempDir()
+	defer func() { DataDir = oldDataDir }()
+
+	for _, p := range []SBRepoPatch{
+		{ID: "local", LocalPath: "../../evil.sbpack"},
+		{ID: "absolute", LocalPath: "/etc/evil.sbpack"},
+		{ID: "hash", Hash: map[string]string{"sha256": ".."}, RemotePath: "https://example.com/../evil.sbpack"},
+	} {
+		if got, err := getRepoPatchLocalPath(p); !errors.Is(err, ErrUnsafePath) {
+			t.Errorf("%s: expected ErrUnsafePath, got %q, %v", p.ID, got, err)
+		}
+	}
+
+	got, err := getRepoPatchLocalPath(SBRepoPatch{ID: "ok", LocalPath: "packs/1.0.0.sbpack"})
+	if err != nil || got != filepath.Join(DataDir, "cache", "packs", "1.0.0.sbpack") {
+		t.Errorf("unexpected path %q, %v", got, err)
+	}
+}
+
+func TestStoreKeyRejectsMalformedDigests(t *testing.T) {
+	sha256Hex := strings.Repeat("ab", 32)
+	for _, hashes := range []map[string]string{
+		{"sha256": "../../" + sha256Hex[6:]},
+		{"sha256": sha256Hex[:62]},
+		{"sha256": strings.Repeat("zz", 32)},
+		{"sha1": sha256Hex},
+	} {
+		if key, ok := storeKey(hashes); ok {
+			t.Errorf("storeKey(%v) = %q, want no key", hashes, key)
+		}
+	}
+
+	key, ok := storeKey(map[string]string{"SHA256": strings.ToUpper(sha256Hex)})
+	if !ok || key != filepath.Join("sha256", "ab", sha256Hex) {
+		t.Errorf("unexpected key %q, %v", key, ok)
+	}
+}
+
+func TestImportSBPackRejectsTraversal(t *testing.T) {
+	index := SBPackIndex{
+		FormatVersion: SBPackFormatVersion,
+		Name:          "Evil",
+		ID:            uuid.New(),
+		Dependencies:  map[string]string{"minecraft": "1.20.1"},
+	}
+	tests := map[string]struct {
+		files map[string][]byte
+		index SBPackIndex
+	}{
+		"override": {
+			files: map[string][]byte{"overrides/../../evil.txt": []byte("evil")},
+			index: index,
+		},
+		"update journal": {
+			files: map[string][]byte{"overrides/.sbupdate/journal.jsonl": []byte(`{"op":"commit","seq":0}`)},
+			index: index,
+		},
+		"pack index": {
+			files: map[string][]byte{"overrides/sb.index.json": []byte("{}")},
+			index: index,
+		},
+		"index file": {
+			index: func() SBPackIndex {
+				i := index
+				i.Files = []SBFile{{Path: "../evil.txt", Downloads: []string{"http://127.0.0.1:0/evil"}}}
+				return i
+			}(),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			base := t.TempDir()
+			files := map[string][]byte{}
+			for k, v := range tt.files {
+				files[k] = v
+			}
+			indexBytes, _ := json.Marshal(tt.index)
+			files["sb.index.json"] = indexBytes
+			packPath := filepath.Join(base, "evil.sbpack")
+			writeTestZip(t, packPath, files)
+
+			destDir := filepath.Join(base, "instances", "evil")
+			_, err := ImportSBPack(context.Background(), packPath, destDir, uuid.New(), nil)
+			if !errors.Is(err, ErrUnsafePath) {
+				t.Fatalf("expected ErrUnsafePath, got %v", err)
+			}
+			for _, p := range []string{filepath.Join(base, "evil.txt"), filepath.Join(base, "instances", "evil.txt")} {
+				if _, err := os.Stat(p); !os.IsNotExist(err) {
+					t.Errorf("file was written outside the instance: %s", p)
+				}
+			}
+		})
+	}
+}
+
+func TestApplySBPackRejectsJournalOverride(t *testing.T) {
+	base := t.TempDir()
+	index := SBPackIndex{
+		FormatVersion: SBPackFormatVersion,
+		Name:          "Pack",
+		ID:            uuid.New(),
+		Dependencies:  map[string]string{"minecraft": "1.20.1"},
+	}
+	indexBytes, _ := json.Marshal(index)
+	packPath := filepath.Join(base, "v1.sbpack")
+	writeTestZip(t, packPath, map[string][]byte{"sb.index.json": indexBytes})
+	inst, err := ImportSBPack(context.Background(), packPath, filepath.Join(base, "instance"), uuid.New(), nil)
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	inst.Upstream.ManifestURL = "https://example.com/manifest.json"
+
+	// A forged commit record would otherwise be replayed by RecoverInstanceUpdate.
+	forged, _ := json.Marshal(journalRecord{Op: journalOpCommit, Instance: &Instance{
+		Name:     "Pack",
+		Upstream: &Upstream{ManifestURL: "https://evil.example.com/manifest.json"},
+	}})
+	index.ID = uuid.New()
+	indexBytes, _ = json.Marshal(index)
+	evilPath := filepath.Join(base, "v2.sbpack")
+	writeTestZip(t, evilPath, map[string][]byte{
+		"sb.index.json":                     indexBytes,
+		"overrides/.sbupdate/journal.jsonl": append(forged, '\n'),
+	})
+	if err := ApplySBPack(context.Background(), inst, evilPath, nil); !errors.Is(err, ErrUnsafePath) {
+		t.Fatalf("expected ErrUnsafePath, got %v", err)
+	}
+
+	changed, err := RecoverInstanceUpdate(inst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed || inst.Upstream.ManifestURL != "https://example.com/manifest.json" {
+		t.Errorf("instance was changed by the pack: changed=%v manifest=%s", changed, inst.Upstream.ManifestURL)
+	}
+}
@@ -5,12 +5,15 @@ import (
 	"context"
 	"crypto/sha1"
 	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	neturl "net/url"
 	"os"
 	"path"
 	"path/filepath"
@@ -40,6 +43,8 @@ type SBPackIndex struct {
 	Dependencies  map[string]string     `json:"dependencies"`
 	Files         []SBFile              `json:"files"`
 	Hashes        map[string]string     `json:"hashes,omitempty"`
+	// Changelog describes this version of the pack. Optional.
+	Changelog Changelog `json:"changelog,omitempty"`
 }
 
 type SBPackIndexProperties struct {
@@ -86,11 +91,16 @@ type SBPatch struct {
 	BaseID        uuid.UUID   `json:"baseID"`
 	Index         SBPackIndex `json:"index"`
 	RemovedFiles  []string    `json:"removedFiles"`
+	// Changelog describes the changes from the base version. Optional.
+	Changelog Changelog `json:"changelog,omitempty"`
 }
 
 type SBRepository struct {
 	Name    string        `json:"name"`
 	Patches []SBRepoPatch `json:"patches"`
+
+	// PublisherKey is the key that signed the manifest, set by FetchRepository. Empty if unsigned.
+	PublisherKey string `json:"-"`
 }
 
 type SBRepoPatch struct {
@@ -100,6 +110,16 @@ type SBRepoPatch struct {
 	RemotePath string            `json:"remote_path"`
 	LocalPath  string            `json:"local_path,omitempty"`
 	Timestamp  int64             `json:"timestamp"`
+	// IndexID is the ID of the index the pack or patch produces.
+	IndexID string `json:"index_id,omitempty"`
+	// BaseID is the index ID a patch applies to. Patches without it apply to the preceding entry.
+	BaseID string `json:"base_id,omitempty"`
+	// Size is the size of the file in bytes, used to pick the cheapest update path. Zero if unknown.
+	Size int64 `json:"size,omitempty"`
+	// Channel is the release channel the entry is published to. Empty means stable.
+	Channel string `json:"channel,omitempty"`
+	// Changelog holds the release notes of the pack or patch, so they can be shown before downloading it.
+	Changelog Changelog `json:"changelog,omitempty"`
 }
 
 type SBPatchType string
@@ -114,7 +134,7 @@ func FetchRepository(ctx context.Context, url string) (*SBRepository, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := mirrorDo(HTTPClient(), req)
 	if err != nil {
 		return nil, err
 	}
@@ -124,11 +144,27 @@ func FetchRepository(ctx context.Context, url string) (*SBRepository, error) {
 		return nil, fmt.Errorf("failed to fetch repository: %s", resp.Status)
 	}
 
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
 	var repo SBRepository
-	if err := json.NewDecoder(resp.Body).Decode(&repo); err != nil {
+	if err := json.Unmarshal(data, &repo); err != nil {
 		return nil, err
 	}
 
+	sig, err := fetchSignature(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch repository signature: %w", err)
+	}
+	if sig != nil {
+		if err := sig.Verify(data); err != nil {
+			return nil, fmt.Errorf("invalid repository signature: %w", err)
+		}
+		repo.PublisherKey = sig.PublicKey
+	}
+
 	// Sort patches by timestamp (stable sort)
 	slices.SortStableFunc(repo.Patches, func(a, b SBRepoPatch) int {
 		if a.Timestamp < b.Timestamp {
@@ -143,9 +179,116 @@ func FetchRepository(ctx context.Context, url string) (*SBRepository, error) {
 	return &repo, nil
 }
 
-func getRepoPatchLocalPath(p SBRepoPatch) string {
+// fetchUpstreamRepository fetches the repository of a remote instance and enforces the pinned publisher key.
+// An instance without a pinned key adopts the key of a signed repository.
+func fetchUpstreamRepository(ctx context.Context, up *Upstream) (*SBRepository, error) {
+	repo, err := FetchRepository(ctx, up.ManifestURL)
+	if err != nil {
+		return nil, err
+	}
+	if up.PublisherKey != "" && repo.PublisherKey == "" {
+		return nil, ErrUnsignedRepository
+	}
+	if err := checkPublisherKey(up.PublisherKey, repo.PublisherKey); err != nil {
+		return nil, err
+	}
+	up.PublisherKey = repo.PublisherKey
+	return repo, nil
+}
+
+// ResolveRemoteUpdate returns the repository entries UpdateInstanceRemoteTo applies to bring inst to target, in
+// order, without downloading them. It returns nothing if inst is already at target.
+func ResolveRemoteUpdate(ctx context.Context, inst *Instance, target string) ([]SBRepoPatch, error) {
+	if inst.Upstream == nil || inst.Upstream.ManifestURL == "" {
+		return nil, fmt.Errorf("instance does not have a remote manifest")
+	}
+	// Resolving does not pin the publisher key; that is left to the update.
+	upstream := *inst.Upstream
+	return resolveRemoteUpdate(ctx, &upstream, target)
+}
+
+// resolveRemoteUpdate fetches the repository of up and finds the cheapest path from the installed version to target,
+// or to the latest version of the channel of up if target is empty. Without a target, it never moves back: an instance
+// ahead of its channel stays where it is until a version is chosen explicitly.
+func resolveRemoteUpdate(ctx context.Context, up *Upstream, target string) ([]SBRepoPatch, error) {
+	repo, err := fetchUpstreamRepository(ctx, up)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(repo.Patches) == 0 {
+		return nil, nil
+	}
+
+	graph := NewRepoGraph(repo)
+	if target == "" {
+		latest := graph.LatestIn(up.Channel)
+		if latest == nil {
+			return nil, fmt.Errorf("no version published to channel '%s'", up.ChannelName())
+		}
+		if !graph.IsNewer(latest.ID, up.Version) {
+			return nil, nil
+		}
+		target = latest.ID
+	}
+	if graph.IsVersion(up.Version, target) {
+		return nil, nil
+	}
+	return graph.ResolvePath(up.Version, target, up.Channel)
+}
+
+// RemoteVersion is a version of a remote repository.
+type RemoteVersion struct {
+	SBRepoPatch
+	// Installed reports whether the instance is at this version.
+	Installed bool
+}
+
+// ListRemoteVersions returns the versions of the remote repository of inst, oldest first.
+func ListRemoteVersions(ctx context.Context, inst *Instance) ([]RemoteVersion, error) {
+	if inst.Upstream == nil || inst.Upstream.ManifestURL == "" {
+		return nil, fmt.Errorf("instance does not have a remote manifest")
+	}
+	// Listing does not pin the publisher key; that is left to the update.
+	upstream := *inst.Upstream
+	repo, err := fetchUpstreamRepository(ctx, &upstream)
+	if err != nil {
+		return nil, err
+	}
+	graph := NewRepoGraph(repo)
+	var versions []RemoteVersion
+	for _, v := range graph.Versions() {
+		versions = append(versions, RemoteVersion{SBRepoPatch: v, Installed: graph.IsVersion(inst.Upstream.Version, v.ID)})
+	}
+	return versions, nil
+}
+
+// CheckRemoteUpdate reports whether the channel inst follows has a version published after the installed one.
+// Like the update itself, it only trusts a repository that satisfies the publisher key pinned on inst.
+func CheckRemoteUpdate(ctx context.Context, inst *Instance) (bool, error) {
+	if inst.Upstream == nil || inst.Upstream.ManifestURL == "" {
+		return false, fmt.Errorf("instance does not have a remote manifest")
+	}
+	// Checking does not pin the publisher key; that is left to the update.
+	upstream := *inst.Upstream
+	repo, err := fetchUpstreamRepository(ctx, &upstream)
+	if err != nil {
+		return false, err
+	}
+	graph := NewRepoGraph(repo)
+	latest := graph.LatestIn(upstream.Channel)
+	if latest == nil {
+		return false, nil
+	}
+	return graph.IsNewer(latest.ID, upstream.Version), nil
+}
+
+// getRepoPatchLocalPath returns where the repository entry p is cached. The path comes from the manifest, so it
+// must stay inside the cache directory.
+func getRepoPatchLocalPath(p SBRepoPatch) (string, error) {
+	cacheDir := filepath.Join(DataDir, "cache")
 	if p.LocalPath != "" {
-		return filepath.Join(DataDir, "cache", filepath.FromSlash(p.LocalPath))
+		return SafeJoin(cacheDir, p.LocalPath)
 	}
 	// Automatic generation: /hash/filename
 	hash := p.Hash["sha256"]
@@ -156,11 +299,14 @@ func getRepoPatchLocalPath(p SBRepoPatch) string {
 		hash = "unknown"
 	}
 	filename := path.Base(p.RemotePath)
-	return filepath.Join(DataDir, "cache", hash, filename)
+	return SafeJoin(cacheDir, hash+"/"+filename)
 }
 
 func downloadAndVerifyRepoPatch(ctx context.Context, p SBRepoPatch, observer ProgressObserver) (string, error) {
-	localPath := getRepoPatchLocalPath(p)
+	localPath, err := getRepoPatchLocalPath(p)
+	if err != nil {
+		return "", fmt.Errorf("invalid local path for %s: %w", p.ID, err)
+	}
 	if verifyHashes(localPath, p.Hash) == nil {
 		return localPath, nil
 	}
@@ -170,7 +316,7 @@ func downloadAndVerifyRepoPatch(ctx context.Context, p SBRepoPatch, observer Pro
 	}
 
 	taskName := "Downloading " + filepath.Base(localPath)
-	if err := downloadWithVerify(ctx, p.RemotePath, localPath, p.Hash, observer, taskName, "download"); err != nil {
+	if err := downloadWithVerify(ctx, []string{p.RemotePath}, localPath, p.Hash, observer, taskName, "download"); err != nil {
 		return "", err
 	}
 
@@ -188,19 +334,18 @@ func ImportRemoteSBPack(ctx context.Context, manifestURL string, destDir string,
 		return nil, fmt.Errorf("failed to fetch repository manifest: %w", err)
 	}
 
-	// 1. Find initial sbpack. We'll start with the first one or the one matching repo structure.
-	// For simplicity, find the latest sbpack that is <= latest_patch.
-	var initialPatch *SBRepoPatch
-	for i := len(repo.Patches) - 1; i >= 0; i-- {
-		if repo.Patches[i].Type == SBPatchTypePack {
-			initialPatch = &repo.Patches[i]
-			break
-		}
+	// 1. Find the initial sbpack: the one the cheapest path to the latest stable version starts with.
+	graph := NewRepoGraph(repo)
+	latest := graph.LatestIn(ChannelStable)
+	if latest == nil {
+		return nil, fmt.Errorf("no stable version found in repository")
 	}
-
-	if initialPatch == nil {
-		return nil, fmt.Errorf("no base sbpack found in repository")
+	latestPatchID := latest.ID
+	path, err := graph.ResolvePath("", latestPatchID, ChannelStable)
+	if err != nil {
+		return nil, fmt.Errorf("no base sbpack found in repository: %w", err)
 	}
+	initialPatch := &path[0]
 
 	observer.OnProgress("Downloading initial base pack", 10, "", "main")
 	localPackPath, err := downloadAndVerifyRepoPatch(ctx, *initialPatch, observer)
@@ -213,11 +358,17 @@ func ImportRemoteSBPack(ctx context.Context, manifestURL string, destDir string,
 	if err != nil {
 		return nil, fmt.Errorf("failed to import initial sbpack: %w", err)
 	}
+	if repo.PublisherKey != "" && inst.Upstream.PublisherKey != "" {
+		if err := checkPublisherKey(repo.PublisherKey, inst.Upstream.PublisherKey); err != nil {
+			return nil, fmt.Errorf("base pack is not signed by the repository publisher: %w", err)
+		}
+	}
 	inst.Upstream.ManifestURL = manifestURL
 	inst.Upstream.Version = initialPatch.ID
+	// The repository signature decides the pinned key of remote instances.
+	inst.Upstream.PublisherKey = repo.PublisherKey
 
 	// 2. Apply patches sequentially up to latest_patch
-	latestPatchID := repo.Patches[len(repo.Patches)-1].ID
 	if inst.Upstream.Version != latestPatchID {
 		observer.OnProgress("Applying updates", 50, "", "main")
 		if err := UpdateInstanceRemote(ctx, inst, observer); err != nil {
@@ -229,7 +380,20 @@ func ImportRemoteSBPack(ctx context.Context, manifestURL string, destDir string,
 	return inst, nil
 }
 
+// UpdateInstanceRemote updates inst to the latest version of the channel it follows. Pinned instances are left at
+// their version.
 func UpdateInstanceRemote(ctx context.Context, inst *Instance, observer ProgressObserver) error {
+	if inst.Upstream != nil && inst.Upstream.Pinned {
+		slog.Info("Instance is pinned, skipping update", "name", inst.Name, "version", inst.Upstream.Version)
+		return nil
+	}
+	return UpdateInstanceRemoteTo(ctx, inst, "", observer)
+}
+
+// UpdateInstanceRemoteTo updates inst to the version of its remote repository with the given entry ID, or to the
+// latest version of the channel it follows if target is empty. An older target rolls the instance back by applying
+// the nearest full pack and the patches after it.
+func UpdateInstanceRemoteTo(ctx context.Context, inst *Instance, target string, observer ProgressObserver) error {
 	if observer == nil {
 		observer = &NopProgressObserver{}
 	}
@@ -237,38 +401,15 @@ func UpdateInstanceRemote(ctx context.Context, inst *Instance, observer Progress
 		return fmt.Errorf("instance does not have a remote manifest")
 	}
 
-	repo, err := FetchRepository(ctx, inst.Upstream.ManifestURL)
+	patchesToApply, err := resolveRemoteUpdate(ctx, inst.Upstream, target)
 	if err != nil {
 		return err
 	}
-
-	if len(repo.Patches) == 0 {
-		return nil
-	}
-
-	latestPatchID := repo.Patches[len(repo.Patches)-1].ID
-	if inst.Upstream.Version == latestPatchID {
-		slog.Info("Instance is already up to date", "name", inst.Name)
+	if len(patchesToApply) == 0 {
+		slog.Info("Instance is already at the requested version", "name", inst.Name, "version", inst.Upstream.Version)
 		return nil
 	}
-
-	// Find the chain of patches from current version to latest
-	applying := false
-	appliedCount := 0
-	patchesToApply := []SBRepoPatch{}
-	for _, p := range repo.Patches {
-		if !applying {
-			if p.ID == inst.Upstream.Version {
-				applying = true
-			}
-			continue
-		}
-		patchesToApply = append(patchesToApply, p)
-	}
-
-	if !applying {
-		return fmt.Errorf("current version '%s' not found in repository manifest", inst.Upstream.Version)
-	}
+	slog.Info("Resolved update path", "name", inst.Name, "from", inst.Upstream.Version, "to", patchesToApply[len(patchesToApply)-1].ID, "steps", len(patchesToApply))
 
 	totalPatches := len(patchesToApply)
 
@@ -309,24 +450,21 @@ func UpdateInstanceRemote(ctx context.Context, inst *Instance, observer Progress
 		progress := (float64(i) / float64(totalPatches)) * 100.0
 		observer.OnProgress(fmt.Sprintf("Applying patch %d/%d (%s)", i+1, totalPatches, p.ID), progress, "", "main")
 
-		localPath := getRepoPatchLocalPath(p)
+		localPath, err := getRepoPatchLocalPath(p)
+		if err != nil {
+			return fmt.Errorf("invalid local path for %s: %w", p.ID, err)
+		}
 
 		switch p.Type {
 		case SBPatchTypePatch:
-			if err := ApplySBPatch(ctx, inst, localPath, observer); err != nil {
+			if err := applySBPatch(ctx, inst, localPath, p.ID, observer); err != nil {
 				return err
 			}
 		case SBPatchTypePack:
-			if err := ApplySBPack(ctx, inst, localPath, observer); err != nil {
+			if err := applySBPack(ctx, inst, localPath, p.ID, observer); err != nil {
 				return err
 			}
 		}
-		inst.Upstream.Version = p.ID
-		appliedCount++
-	}
-
-	if appliedCount == 0 && inst.Upstream.Version != latestPatchID {
-		return fmt.Errorf("failed to find update path from '%s' to '%s'", inst.Upstream.Version, latestPatchID)
 	}
 
 	return nil
@@ -343,24 +481,11 @@ func ImportSBPack(ctx context.Context, packPath string, destDir string, uid uuid
 	}
 	defer reader.Close()
 
-	var index SBPackIndex
-	var indexFound bool
-
 	// Read index first
-	for _, f := range reader.File {
-		if f.Name == "sb.index.json" {
-			rc, err := f.Open()
-			if err != nil {
-				return nil, err
-			}
-			err = json.NewDecoder(rc).Decode(&index)
-			rc.Close()
-			if err != nil {
-				return nil, fmt.Errorf("failed to parse sb.index.json: %w", err)
-			}
-			indexFound = true
-			break
-		}
+	var index SBPackIndex
+	signer, indexFound, err := readArchiveJSON(&reader.Reader, "sb.index.json", &index)
+	if err != nil {
+		return nil, err
 	}
 
 	if !indexFound {
@@ -370,6 +495,19 @@ func ImportSBPack(ctx context.Context, packPath string, destDir string, uid uuid
 	if index.FormatVersion < SBPackFormatVersion {
 		return nil, fmt.Errorf("unsupported sbpack format version: %d (requires %d)", index.FormatVersion, SBPackFormatVersion)
 	}
+
+	inst, err := installPackContent(ctx, &reader.Reader, index, signer != "", []string{"overrides/"}, destDir, uid, observer)
+	if err != nil {
+		return nil, err
+	}
+	inst.Upstream.PublisherKey = signer
+	return inst, nil
+}
+
+// installPackContent creates a new instance in destDir from a pack index, extracting the given
+// override directories of the archive in order (later ones win) and fetching the index files.
+// Overrides are checked against index.Hashes; if signed is set, every override must be listed there.
+func installPackContent(ctx context.Context, reader *zip.Reader, index SBPackIndex, signed bool, overrideDirs []string, destDir string, uid uuid.UUID, observer ProgressObserver) (*Instance, error) {
 	inst := &Instance{
 		Name:       index.Name,
 		UID:        uid,
@@ -388,60 +526,55 @@ func ImportSBPack(ctx context.Context, packPath string, destDir string, uid uuid
 		})
 	}
 
+	sandbox := newExtractSandbox(destDir)
+	if err := sandbox.CheckArchive(reader); err != nil {
+		return nil, err
+	}
+
 	if err := os.MkdirAll(destDir, 0755); err != nil {
 		return nil, err
 	}
 
 	// Unzip overrides
-	overrideFiles := []string{}
-	for _, f := range reader.File {
-		if strings.HasPrefix(f.Name, "overrides/") && !f.FileInfo().IsDir() {
-			overrideFiles = append(overrideFiles, f.Name)
+	type overrideFile struct {
+		f       *zip.File
+		relPath string
+	}
+	overrideFiles := []overrideFile{}
+	for _, dir := range overrideDirs {
+		for _, f := range reader.File {
+			relPath, ok := strings.CutPrefix(f.Name, dir)
+			if !ok || relPath == "" {
+				continue
+			}
+			if f.FileInfo().IsDir() {
+				if err := sandbox.MkdirAll(relPath); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			overrideFiles = append(overrideFiles, overrideFile{f, relPath})
 		}
 	}
 	totalExtract := len(overrideFiles)
 
-	for i, fName := range overrideFiles {
-		f, _ := reader.Open(fName) // fName exists
-		relPath := strings.TrimPrefix(fName, "overrides/")
-		if relPath == "" {
-			f.Close()
-			continue
-		}
-
+	for i, o := range overrideFiles {
 		percentage := float64(i) / float64(totalExtract) * 100.0
-		filename := filepath.Base(relPath)
+		filename := filepath.Base(o.relPath)
 		if len(filename) > uiMaxFilenameLength {
 			filename = filename[:uiMaxFilenameLength] + "..."
 		}
 		observer.OnProgress("Extracting "+filename, percentage, fmt.Sprintf("%d/%d", i+1, totalExtract), "main")
 
-		destPath := filepath.Join(destDir, relPath)
-		if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
-			f.Close()
-			return nil, err
-		}
-
-		destFile, err := os.Create(destPath)
+		destPath, err := sandbox.Join(o.relPath)
 		if err != nil {
-			f.Close()
 			return nil, err
 		}
-
-		_, err = io.Copy(destFile, f)
-		destFile.Close()
-		f.Close()
-		if err != nil {
+		if err := sandbox.Extract(o.f, destPath); err != nil {
 			return nil, err
 		}
-	}
-
-	// For compatibility with previous loop structure if directories needed
-	for _, f := range reader.File {
-		if after, ok := strings.CutPrefix(f.Name, "overrides/"); ok {
-			if f.FileInfo().IsDir() && after != "" {
-				_ = os.MkdirAll(filepath.Join(destDir, after), 0755)
-			}
+		if err := checkEntryHash(index.Hashes, o.relPath, destPath, signed); err != nil {
+			return nil, err
 		}
 	}
 
@@ -452,7 +585,10 @@ func ImportSBPack(ctx context.Context, packPath string, destDir string, uid uuid
 			continue
 		}
 
-		destPath := filepath.Join(destDir, fileInfo.Path)
+		destPath, err := sandbox.Join(fileInfo.Path)
+		if err != nil {
+			return nil, err
+		}
 		if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
 			return nil, err
 		}
@@ -460,31 +596,15 @@ func ImportSBPack(ctx context.Context, packPath string, destDir string, uid uuid
 		// Download
 		if len(fileInfo.Downloads) > 0 {
 			taskName := "Downloading " + filepath.Base(fileInfo.Path)
-			if err := downloadWithVerify(ctx, fileInfo.Downloads[0], destPath, fileInfo.Hashes, observer, taskName, "main"); err != nil {
+			if err := fetchFile(ctx, fileInfo, destPath, observer, taskName, "main"); err != nil {
 				slog.Error("Failed to download file", "path", fileInfo.Path, "error", err)
 				return nil, err
 			}
 		}
 
 		// Assume all JARs in mods/ are mods
-		if strings.HasPrefix(fileInfo.Path, "mods/") && strings.HasSuffix(fileInfo.Path, ".jar") {
-			modName := filepath.Base(fileInfo.Path)
-			inst.Mods = append(inst.Mods, Mod{
-				Name:     modName,
-				File:     fileInfo.Path,
-				Version:  "unknown", // Could extract from jar if needed
-				UpdateAt: time.Now(),
-				Source: &URLSource{
-					ModURL: "",
-					FileURI: func() string {
-						if len(fileInfo.Downloads) > 0 {
-							return fileInfo.Downloads[0]
-						} else {
-							return ""
-						}
-					}(),
-				},
-			})
+		if mod, ok := modForFile(fileInfo); ok {
+			inst.Mods = append(inst.Mods, mod)
 		}
 	}
 
@@ -499,49 +619,52 @@ func ImportSBPack(ctx context.Context, packPath string, destDir string, uid uuid
 
 // ApplySBPack updates an existing instance using a full .sbpack file.
 func ApplySBPack(ctx context.Context, inst *Instance, packPath string, observer ProgressObserver) error {
+	return applySBPack(ctx, inst, packPath, "", observer)
+}
+
+// applySBPack is ApplySBPack recording version as the upstream version of inst, or the index ID if version is empty.
+// Remote updates pass the repository entry ID, so the journal commits the version the instance is saved with.
+func applySBPack(ctx context.Context, inst *Instance, packPath string, version string, observer ProgressObserver) error {
 	if observer == nil {
 		observer = &NopProgressObserver{}
 	}
 
-	backup, err := newInstanceBackup(inst.Path)
+	reader, err := zip.OpenReader(packPath)
+	if err != nil {
+		return fmt.Errorf("failed to open sbpack: %w", err)
+	}
+	defer reader.Close()
+
+	var newIndex SBPackIndex
+	signer, indexFound, err := readArchiveJSON(&reader.Reader, "sb.index.json", &newIndex)
 	if err != nil {
 		return err
 	}
-	defer backup.Cleanup()
 
-	err = func() error { // TODO: refactor to avoid this closure by making backup.Restore() more flexible
-		reader, err := zip.OpenReader(packPath)
-		if err != nil {
-			return fmt.Errorf("failed to open sbpack: %w", err)
-		}
-		defer reader.Close()
+	if !indexFound {
+		return fmt.Errorf("sb.index.json not found in pack")
+	}
 
-		var newIndex SBPackIndex
-		var indexFound bool
-		for _, f := range reader.File {
-			if f.Name == "sb.index.json" {
-				rc, err := f.Open()
-				if err != nil {
-					return err
-				}
-				err = json.NewDecoder(rc).Decode(&newIndex)
-				rc.Close()
-				if err != nil {
-					return fmt.Errorf("failed to parse sb.index.json: %w", err)
-				}
-				indexFound = true
-				break
-			}
-		}
+	if err := checkSignedUpdate(inst, signer); err != nil {
+		return err
+	}
 
-		if !indexFound {
-			return fmt.Errorf("sb.index.json not found in pack")
-		}
+	if newIndex.FormatVersion < SBPackFormatVersion {
+		return fmt.Errorf("unsupported sbpack format version: %d (requires %d)", newIndex.FormatVersion, SBPackFormatVersion)
+	}
 
-		if newIndex.FormatVersion < SBPackFormatVersion {
-			return fmt.Errorf("unsupported sbpack format version: %d (requires %d)", newIndex.FormatVersion, SBPackFormatVersion)
-		}
+	sandbox := newExtractSandbox(inst.Path)
+	if err := sandbox.CheckArchive(&reader.Reader); err != nil {
+		return err
+	}
 
+	backup, err := newInstanceBackup(inst, newIndex.ID.String())
+	if err != nil {
+		return err
+	}
+	defer backup.Cleanup()
+
+	err = func() error { // TODO: refactor to avoid this closure by making backup.Restore() more flexible
 		// Load old index to find removed files
 		var oldIndex SBPackIndex
 		oldIndexBytes, err := os.ReadFile(filepath.Join(inst.Path, "sb.index.json"))
@@ -565,27 +688,31 @@ func ApplySBPack(ctx context.Context, inst *Instance, packPath string, observer
 
 		// Perform update (similar to patch)
 		for _, removed := range removedFiles {
-			_ = backup.Backup(removed)
-			_ = os.Remove(filepath.Join(inst.Path, removed))
+			targetPath, err := sandbox.Join(removed)
+			if err != nil {
+				return err
+			}
+			if err := backup.Backup(removed); err != nil {
+				return err
+			}
+			_ = os.Remove(targetPath)
 		}
 
 		// Unzip overrides from new pack
-		overrideFiles := []string{}
+		overrideFiles := []*zip.File{}
 		for _, f := range reader.File {
 			if strings.HasPrefix(f.Name, "overrides/") && !f.FileInfo().IsDir() {
-				overrideFiles = append(overrideFiles, f.Name)
+				overrideFiles = append(overrideFiles, f)
 			}
 		}
 		totalExtract := len(overrideFiles)
 
-		for i, fName := range overrideFiles {
+		for i, f := range overrideFiles {
 			if err := ctx.Err(); err != nil {
 				return err
 			}
-			f, _ := reader.Open(fName)
-			relPath := strings.TrimPrefix(fName, "overrides/")
+			relPath := strings.TrimPrefix(f.Name, "overrides/")
 			if relPath == "" {
-				f.Close()
 				continue
 			}
 
@@ -596,23 +723,17 @@ func ApplySBPack(ctx context.Context, inst *Instance, packPath string, observer
 			}
 			observer.OnProgress("Extracting "+filename, percentage, fmt.Sprintf("%d/%d", i+1, totalExtract), "main")
 
-			_ = backup.Backup(relPath)
-			destPath := filepath.Join(inst.Path, relPath)
-			if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
-				f.Close()
+			destPath, err := sandbox.Join(relPath)
+			if err != nil {
 				return err
 			}
-
-			df, err := os.Create(destPath)
-			if err != nil {
-				f.Close()
+			if err := backup.Backup(relPath); err != nil {
 				return err
 			}
-
-			_, err = io.Copy(df, f)
-			df.Close()
-			f.Close()
-			if err != nil {
+			if err := sandbox.Extract(f, destPath); err != nil {
+				return err
+			}
+			if err := checkEntryHash(newIndex.Hashes, relPath, destPath, signer != ""); err != nil {
 				return err
 			}
 		}
@@ -621,7 +742,9 @@ func ApplySBPack(ctx context.Context, inst *Instance, packPath string, observer
 		for _, f := range reader.File {
 			if after, ok := strings.CutPrefix(f.Name, "overrides/"); ok {
 				if f.FileInfo().IsDir() && after != "" {
-					_ = os.MkdirAll(filepath.Join(inst.Path, after), 0755)
+					if err := sandbox.MkdirAll(after); err != nil {
+						return err
+					}
 				}
 			}
 		}
@@ -635,39 +758,32 @@ func ApplySBPack(ctx context.Context, inst *Instance, packPath string, observer
 			if fileInfo.Env != nil && fileInfo.Env.Client == SBEnvUnsupported {
 				continue
 			}
-			destPath := filepath.Join(inst.Path, fileInfo.Path)
+			destPath, err := sandbox.Join(fileInfo.Path)
+			if err != nil {
+				return err
+			}
 			if verifyHashes(destPath, fileInfo.Hashes) != nil && len(fileInfo.Downloads) > 0 {
-				_ = backup.Backup(fileInfo.Path)
+				if err := backup.Backup(fileInfo.Path); err != nil {
+					return err
+				}
 				if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
 					return err
 				}
 				taskName := "Downloading " + filepath.Base(fileInfo.Path)
-				if err := downloadWithVerify(ctx, fileInfo.Downloads[0], destPath, fileInfo.Hashes, observer, taskName, "main"); err != nil {
+				if err := fetchFile(ctx, fileInfo, destPath, observer, taskName, "main"); err != nil {
 					return err
 				}
 			}
-			if strings.HasPrefix(fileInfo.Path, "mods/") && strings.HasSuffix(fileInfo.Path, ".jar") {
-				modName := filepath.Base(fileInfo.Path)
-				newMods = append(newMods, Mod{
-					Name:     modName,
-					File:     fileInfo.Path,
-					Version:  "unknown",
-					UpdateAt: time.Now(),
-					Source: &URLSource{
-						FileURI: func() string {
-							if len(fileInfo.Downloads) > 0 {
-								return fileInfo.Downloads[0]
-							} else {
-								return ""
-							}
-						}(),
-					},
-				})
+			if mod, ok := modForFile(fileInfo); ok {
+				newMods = append(newMods, mod)
 			}
 		}
 
+		if version == "" {
+			version = newIndex.ID.String()
+		}
 		if inst.Upstream != nil {
-			inst.Upstream.Version = newIndex.ID.String()
+			inst.Upstream.Version = version
 		}
 		inst.Name = newIndex.Name
 		inst.Properties = newIndex.Properties
@@ -678,13 +794,15 @@ func ApplySBPack(ctx context.Context, inst *Instance, packPath string, observer
 		inst.Mods = newMods
 
 		// Save new index
-		_ = backup.Backup("sb.index.json")
+		if err := backup.Backup("sb.index.json"); err != nil {
+			return err
+		}
 		newIndexBytes, _ := json.MarshalIndent(newIndex, "", "  ")
 		if err := os.WriteFile(filepath.Join(inst.Path, "sb.index.json"), newIndexBytes, 0644); err != nil {
 			return fmt.Errorf("failed to save new index: %w", err)
 		}
 
-		return nil
+		return backup.Commit()
 	}()
 
 	if err != nil {
@@ -696,51 +814,52 @@ func ApplySBPack(ctx context.Context, inst *Instance, packPath string, observer
 
 // ApplySBPatch applies an .sbpatch file to an existing instance.
 func ApplySBPatch(ctx context.Context, inst *Instance, patchPath string, observer ProgressObserver) error {
+	return applySBPatch(ctx, inst, patchPath, "", observer)
+}
+
+// applySBPatch is ApplySBPatch recording version as the upstream version of inst, like applySBPack.
+func applySBPatch(ctx context.Context, inst *Instance, patchPath string, version string, observer ProgressObserver) error {
 	if observer == nil {
 		observer = &NopProgressObserver{}
 	}
 
-	backup, err := newInstanceBackup(inst.Path)
+	reader, err := zip.OpenReader(patchPath)
+	if err != nil {
+		return fmt.Errorf("failed to open sbpatch: %w", err)
+	}
+	defer reader.Close()
+
+	// Read patch index
+	var patch SBPatch
+	signer, patchFound, err := readArchiveJSON(&reader.Reader, "sb.patch.json", &patch)
 	if err != nil {
 		return err
 	}
-	defer backup.Cleanup()
 
-	err = func() error { // TODO: refactor to avoid this closure by making backup.Restore() more flexible
-		reader, err := zip.OpenReader(patchPath)
-		if err != nil {
-			return fmt.Errorf("failed to open sbpatch: %w", err)
-		}
-		defer reader.Close()
+	if !patchFound {
+		return fmt.Errorf("sb.patch.json not found in patch")
+	}
 
-		var patch SBPatch
-		var patchFound bool
+	if err := checkSignedUpdate(inst, signer); err != nil {
+		return err
+	}
 
-		// Read patch index
-		for _, f := range reader.File {
-			if f.Name == "sb.patch.json" {
-				rc, err := f.Open()
-				if err != nil {
-					return err
-				}
-				err = json.NewDecoder(rc).Decode(&patch)
-				rc.Close()
-				if err != nil {
-					return fmt.Errorf("failed to parse sb.patch.json: %w", err)
-				}
-				patchFound = true
-				break
-			}
-		}
+	if patch.FormatVersion < SBPatchFormatVersion {
+		return fmt.Errorf("unsupported sbpatch format version: %d (requires %d)", patch.FormatVersion, SBPatchFormatVersion)
+	}
 
-		if !patchFound {
-			return fmt.Errorf("sb.patch.json not found in patch")
-		}
+	sandbox := newExtractSandbox(inst.Path)
+	if err := sandbox.CheckArchive(&reader.Reader); err != nil {
+		return err
+	}
 
-		if patch.FormatVersion < SBPatchFormatVersion {
-			return fmt.Errorf("unsupported sbpatch format version: %d (requires %d)", patch.FormatVersion, SBPatchFormatVersion)
-		}
+	backup, err := newInstanceBackup(inst, patch.Index.ID.String())
+	if err != nil {
+		return err
+	}
+	defer backup.Cleanup()
 
+	err = func() error { // TODO: refactor to avoid this closure by making backup.Restore() more flexible
 		// Load current index from disk to check modpack version
 		var currentIndex SBPackIndex
 		currentIndexBytes, err := os.ReadFile(filepath.Join(inst.Path, "sb.index.json"))
@@ -760,8 +879,13 @@ func ApplySBPatch(ctx context.Context, inst *Instance, patchPath string, observe
 		for _, removed := range patch.RemovedFiles {
 			// Sanitize path: overrides/ in zip is extracted to instance root
 			cleanPath := strings.TrimPrefix(removed, "overrides/") // TODO: remove this hack by standardizing patch format to not include "overrides/" prefix
-			targetPath := filepath.Join(inst.Path, cleanPath)
-			_ = backup.Backup(cleanPath)
+			targetPath, err := sandbox.Join(cleanPath)
+			if err != nil {
+				return err
+			}
+			if err := backup.Backup(cleanPath); err != nil {
+				return err
+			}
 			if err := os.Remove(targetPath); err != nil && !os.IsNotExist(err) {
 				return fmt.Errorf("failed to remove file %s: %w", targetPath, err)
 			}
@@ -797,35 +921,30 @@ func ApplySBPatch(ctx context.Context, inst *Instance, patchPath string, observe
 				}
 				observer.OnProgress("Extracting "+filename, percentage, fmt.Sprintf("%d/%d", i+1, totalTasks), "main")
 
-				_ = backup.Backup(relPath)
-				destPath := filepath.Join(inst.Path, relPath)
-				if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+				destPath, err := sandbox.Join(relPath)
+				if err != nil {
 					return err
 				}
-
-				rc, err := f.Open()
-				if err != nil {
+				if err := backup.Backup(relPath); err != nil {
 					return err
 				}
-
-				destFile, err := os.Create(destPath)
-				if err != nil {
-					rc.Close()
+				if err := sandbox.Extract(f, destPath); err != nil {
 					return err
 				}
-
-				_, err = io.Copy(destFile, rc)
-				destFile.Close()
-				rc.Close()
-				if err != nil {
+				if err := checkEntryHash(patch.Index.Hashes, relPath, destPath, signer != ""); err != nil {
 					return err
 				}
 			} else {
 				relPath := strings.TrimPrefix(f.Name, "patches/")
 				observer.OnProgress("Patching "+filepath.Base(relPath), percentage, fmt.Sprintf("%d/%d", i+1, totalTasks), "main")
 
-				_ = backup.Backup(relPath)
-				targetPath := filepath.Join(inst.Path, relPath)
+				targetPath, err := sandbox.Join(relPath)
+				if err != nil {
+					return err
+				}
+				if err := backup.Backup(relPath); err != nil {
+					return err
+				}
 				if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
 					return err
 				}
@@ -835,29 +954,35 @@ func ApplySBPatch(ctx context.Context, inst *Instance, patchPath string, observe
 					return fmt.Errorf("failed to open old file for patching %s: %w", relPath, err)
 				}
 
-				patchFile, err := f.Open()
+				rc, err := f.Open()
 				if err != nil {
 					oldFile.Close()
 					return err
 				}
+				patchFile, err := sandbox.ReservePatch(rc, int64(f.UncompressedSize64))
+				if err != nil {
+					oldFile.Close()
+					rc.Close()
+					return fmt.Errorf("failed to apply binary patch to %s: %w", relPath, err)
+				}
 
 				tempFile, err := os.CreateTemp("", "sbpatch-*")
 				if err != nil {
 					oldFile.Close()
-					patchFile.Close()
+					rc.Close()
 					return err
 				}
 
 				if err := binarydist.Patch(oldFile, tempFile, patchFile); err != nil {
 					oldFile.Close()
-					patchFile.Close()
+					rc.Close()
 					tempFile.Close()
 					_ = os.Remove(tempFile.Name())
 					return fmt.Errorf("failed to apply binary patch to %s: %w", relPath, err)
 				}
 
 				oldFile.Close()
-				patchFile.Close()
+				rc.Close()
 				tempFile.Close()
 
 				if err := os.Remove(targetPath); err != nil {
@@ -867,6 +992,10 @@ func ApplySBPatch(ctx context.Context, inst *Instance, patchPath string, observe
 				if err := os.Rename(tempFile.Name(), targetPath); err != nil {
 					return err
 				}
+				// The result is checked rather than the binary patch, which the metadata does not describe
+				if err := checkEntryHash(patch.Index.Hashes, relPath, targetPath, signer != ""); err != nil {
+					return err
+				}
 			}
 		}
 
@@ -874,7 +1003,9 @@ func ApplySBPatch(ctx context.Context, inst *Instance, patchPath string, observe
 		for _, f := range reader.File {
 			if after, ok := strings.CutPrefix(f.Name, "overrides/"); ok {
 				if f.FileInfo().IsDir() && after != "" {
-					_ = os.MkdirAll(filepath.Join(inst.Path, after), 0755)
+					if err := sandbox.MkdirAll(after); err != nil {
+						return err
+					}
 				}
 			}
 		}
@@ -889,47 +1020,39 @@ func ApplySBPatch(ctx context.Context, inst *Instance, patchPath string, observe
 				continue
 			}
 
-			destPath := filepath.Join(inst.Path, fileInfo.Path)
+			destPath, err := sandbox.Join(fileInfo.Path)
+			if err != nil {
+				return err
+			}
 
 			// Check if file already exists and hashes match
 			if verifyHashes(destPath, fileInfo.Hashes) == nil {
 				// Already up to date
 			} else if len(fileInfo.Downloads) > 0 {
-				_ = backup.Backup(fileInfo.Path)
+				if err := backup.Backup(fileInfo.Path); err != nil {
+					return err
+				}
 				if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
 					return err
 				}
 				taskName := "Downloading " + filepath.Base(fileInfo.Path)
-				if err := downloadWithVerify(ctx, fileInfo.Downloads[0], destPath, fileInfo.Hashes, observer, taskName, "main"); err != nil {
+				if err := fetchFile(ctx, fileInfo, destPath, observer, taskName, "main"); err != nil {
 					slog.Error("Failed to download file during patch", "path", fileInfo.Path, "error", err)
 					return err
 				}
 			}
 
-			if strings.HasPrefix(fileInfo.Path, "mods/") && strings.HasSuffix(fileInfo.Path, ".jar") {
-				modName := filepath.Base(fileInfo.Path)
-				newMods = append(newMods, Mod{
-					Name:     modName,
-					File:     fileInfo.Path,
-					Version:  "unknown",
-					UpdateAt: time.Now(),
-					Source: &URLSource{
-						ModURL: "",
-						FileURI: func() string {
-							if len(fileInfo.Downloads) > 0 {
-								return fileInfo.Downloads[0]
-							} else {
-								return ""
-							}
-						}(),
-					},
-				})
+			if mod, ok := modForFile(fileInfo); ok {
+				newMods = append(newMods, mod)
 			}
 		}
 
 		// Update instance state
+		if version == "" {
+			version = patch.Index.ID.String()
+		}
 		if inst.Upstream != nil {
-			inst.Upstream.Version = patch.Index.ID.String()
+			inst.Upstream.Version = version
 		}
 		inst.Name = patch.Index.Name
 		inst.Properties = patch.Index.Properties
@@ -943,13 +1066,15 @@ func ApplySBPatch(ctx context.Context, inst *Instance, patchPath string, observe
 		inst.Mods = newMods
 
 		// Save new index
-		_ = backup.Backup("sb.index.json")
+		if err := backup.Backup("sb.index.json"); err != nil {
+			return err
+		}
 		newIndexBytes, _ := json.MarshalIndent(patch.Index, "", "  ")
 		if err := os.WriteFile(filepath.Join(inst.Path, "sb.index.json"), newIndexBytes, 0644); err != nil {
 			return fmt.Errorf("failed to save new index: %w", err)
 		}
 
-		return nil
+		return backup.Commit()
 	}()
 
 	if err != nil {
@@ -959,45 +1084,195 @@ func ApplySBPatch(ctx context.Context, inst *Instance, patchPath string, observe
 	return nil
 }
 
-func downloadWithVerify(ctx context.Context, url, dest string, hashes map[string]string, observer ProgressObserver, taskName string, category string) error {
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+// modForFile describes a mod jar of a pack index as an instance mod. Other files are not mods.
+func modForFile(fileInfo SBFile) (Mod, bool) {
+	if !strings.HasPrefix(fileInfo.Path, "mods/") || !strings.HasSuffix(fileInfo.Path, ".jar") {
+		return Mod{}, false
+	}
+	var fileURL string
+	if len(fileInfo.Downloads) > 0 {
+		fileURL = fileInfo.Downloads[0]
+	}
+	var source Source = &URLSource{FileURI: fileURL}
+	if mr, ok := modrinthSourceFromURL(fileURL); ok {
+		source = mr
+	}
+	return Mod{
+		Name:     filepath.Base(fileInfo.Path),
+		File:     fileInfo.Path,
+		Version:  "unknown", // Could extract from jar if needed
+		UpdateAt: time.Now(),
+		Source:   source,
+	}, true
+}
+
+// checkSignedUpdate refuses pack metadata signed by a key other than the one pinned on the instance.
+// Once a key is pinned, unsigned metadata is refused as well.
+func checkSignedUpdate(inst *Instance, signer string) error {
+	if inst.Upstream == nil {
+		return nil
+	}
+	if signer == "" && inst.Upstream.PublisherKey != "" {
+		return ErrUnsignedPack
+	}
+	return checkPublisherKey(inst.Upstream.PublisherKey, signer)
+}
+
+// partSuffix is appended to the destination of an in-progress download.
+// A leftover part file is resumed with an HTTP Range request on the next attempt.
+const partSuffix = ".part"
+
+// downloadWithVerify downloads a file from the first mirror that yields content matching hashes.
+// Data is written to dest+".part" and only moved to dest once verified, so an interrupted download
+// resumes where it stopped. Mirrors are tried in order on network errors and hash mismatches.
+func downloadWithVerify(ctx context.Context, mirrors []string, dest string, hashes map[string]string, observer ProgressObserver, taskName string, category string) error {
+	if observer == nil {
+		observer = &NopProgressObserver{}
+	}
+	if len(mirrors) == 0 {
+		return fmt.Errorf("no download source for %s", filepath.Base(dest))
+	}
+
+	// Each source expands to the mirrors of the current mirror profile.
+	var sources []string
+	for _, m := range mirrors {
+		sources = append(sources, MirrorURLs(m)...)
+	}
+	mirrors = sources
+
+	partPath := dest + partSuffix
+	var errs []error
+	for i, mirror := range mirrors {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		host := mirrorHost(mirror)
+		if i > 0 {
+			observer.OnProgress(taskName, 0, "Switching to mirror "+host, category)
+		}
+
+		err := downloadVerifiedPart(ctx, mirror, partPath, hashes, observer, taskName, category, host)
+		if errors.Is(err, errStalePart) {
+			// The part was left by an earlier, different version of the file; fetch it from scratch once.
+			slog.Info("Resumed download did not verify, starting over", "url", mirror, "error", err)
+			err = downloadVerifiedPart(ctx, mirror, partPath, hashes, observer, taskName, category, host)
+		}
+		if err == nil {
+			if err := os.Remove(dest); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			return os.Rename(partPath, dest)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		slog.Warn("Download from mirror failed", "url", mirror, "error", err)
+		errs = append(errs, fmt.Errorf("%s: %w", host, err))
+	}
+	return fmt.Errorf("all mirrors failed for %s: %w", filepath.Base(dest), errors.Join(errs...))
+}
+
+// errStalePart is returned by downloadVerifiedPart when a download resumed from an existing part did not verify.
+// The part has been removed by then.
+var errStalePart = errors.New("resumed download does not match the expected hashes")
+
+// downloadVerifiedPart fetches url into partPath and verifies it against hashes. A part that does not verify is
+// removed, so the next attempt starts from zero.
+func downloadVerifiedPart(ctx context.Context, url, partPath string, hashes map[string]string, observer ProgressObserver, taskName, category, host string) error {
+	offset, err := downloadPart(ctx, url, partPath, observer, taskName, category, host)
 	if err != nil {
 		return err
 	}
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
+	if err := verifyHashes(partPath, hashes); err != nil {
+		// The part is corrupt or comes from a different file; never resume from it.
+		_ = os.Remove(partPath)
+		if offset > 0 {
+			return fmt.Errorf("%w: %w", errStalePart, err)
+		}
 		return err
 	}
-	defer resp.Body.Close()
+	return nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("bad status: %s", resp.Status)
+// downloadPart fetches url into partPath, continuing from the existing size of partPath when the server supports ranges.
+// It returns the offset the download resumed from, zero if it started over.
+func downloadPart(ctx context.Context, url, partPath string, observer ProgressObserver, taskName, category, host string) (int64, error) {
+	var offset int64
+	if info, err := os.Stat(partPath); err == nil {
+		offset = info.Size()
 	}
 
-	out, err := os.Create(dest)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return err
+		return 0, err
 	}
-	defer out.Close()
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := HTTPClient().Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
 
-	if observer == nil {
-		observer = &NopProgressObserver{}
+	flags := os.O_CREATE | os.O_WRONLY
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && contentRangeStart(resp.Header.Get("Content-Range")) == offset:
+		flags |= os.O_APPEND
+	case resp.StatusCode == http.StatusOK:
+		// Server ignored the range request; start over.
+		offset = 0
+		flags |= os.O_TRUNC
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// The part file is already complete (or longer than the remote file); let verification decide.
+		return offset, nil
+	case resp.StatusCode == http.StatusPartialContent:
+		_ = os.Remove(partPath)
+		return 0, fmt.Errorf("unexpected content range: %s", resp.Header.Get("Content-Range"))
+	default:
+		return 0, fmt.Errorf("bad status: %s", resp.Status)
+	}
+
+	out, err := os.OpenFile(partPath, flags, 0644)
+	if err != nil {
+		return 0, err
 	}
+	defer out.Close()
 
 	total := resp.ContentLength
+	if total > 0 {
+		total += offset
+	}
 	pw := &progressWriter{
 		total:    total,
+		current:  offset,
 		observer: observer,
 		taskName: taskName,
 		category: category,
+		source:   host,
 		writer:   out,
 	}
 
-	if _, err := io.Copy(pw, resp.Body); err != nil {
-		return err
+	_, err = io.Copy(pw, resp.Body)
+	return offset, err
+}
+
+// contentRangeStart parses the first byte position of a "bytes start-end/size" header, or -1.
+func contentRangeStart(header string) int64 {
+	var start, end int64
+	var size string
+	if _, err := fmt.Sscanf(header, "bytes %d-%d/%s", &start, &end, &size); err != nil {
+		return -1
 	}
+	return start
+}
 
-	return verifyHashes(dest, hashes)
+func mirrorHost(rawURL string) string {
+	u, err := neturl.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	return u.Host
 }
 
 type progressWriter struct {
@@ -1006,6 +1281,7 @@ type progressWriter struct {
 	observer ProgressObserver
 	taskName string
 	category string
+	source   string
 	writer   io.Writer
 }
 
@@ -1017,7 +1293,11 @@ func (pw *progressWriter) Write(p []byte) (int, error) {
 	pw.current += int64(n)
 	if pw.total > 0 {
 		percentage := float64(pw.current) / float64(pw.total) * 100.0
-		pw.observer.OnProgress(pw.taskName, percentage, fmt.Sprintf("%.1f%%", percentage), pw.category)
+		status := fmt.Sprintf("%.1f%%", percentage)
+		if pw.source != "" {
+			status += " (" + pw.source + ")"
+		}
+		pw.observer.OnProgress(pw.taskName, percentage, status, pw.category)
 	}
 	return n, nil
 }
@@ -1048,6 +1328,12 @@ func verifyHashes(path string, hashes map[string]string) error {
 				return err
 			}
 			actualHash = hex.EncodeToString(h.Sum(nil))
+		case "sha512":
+			h := sha512.New()
+			if _, err := io.Copy(h, f); err != nil {
+				return err
+			}
+			actualHash = hex.EncodeToString(h.Sum(nil))
 		default:
 			// Unsupported algorithm, skip or error
 			continue
@@ -1079,6 +1365,7 @@ func RepairInstance(ctx context.Context, inst *Instance, observer ProgressObserv
 	}
 
 	// 2. Identify corrupted/missing files from index
+	sandbox := newExtractSandbox(inst.Path)
 	toRepair := []SBFile{}
 	totalVerify := len(index.Files) + len(index.Hashes)
 	verifiedCount := 0
@@ -1088,7 +1375,10 @@ func RepairInstance(ctx context.Context, inst *Instance, observer ProgressObserv
 		percentage := float64(verifiedCount) / float64(totalVerify) * 100.0
 		observer.OnProgress(fmt.Sprintf("Verifying %s", filepath.Base(f.Path)), percentage, "", "main")
 
-		targetPath := filepath.Join(inst.Path, f.Path)
+		targetPath, err := sandbox.Join(f.Path)
+		if err != nil {
+			return err
+		}
 		if _, err := os.Stat(targetPath); os.IsNotExist(err) {
 			toRepair = append(toRepair, f)
 			continue
@@ -1106,7 +1396,10 @@ func RepairInstance(ctx context.Context, inst *Instance, observer ProgressObserv
 		percentage := float64(verifiedCount) / float64(totalVerify) * 100.0
 		observer.OnProgress(fmt.Sprintf("Verifying %s", filepath.Base(rel)), percentage, "", "main")
 
-		targetPath := filepath.Join(inst.Path, rel)
+		targetPath, err := sandbox.Join(rel)
+		if err != nil {
+			return err
+		}
 		if _, err := os.Stat(targetPath); os.IsNotExist(err) {
 			corruptedOverrides = append(corruptedOverrides, rel)
 			continue
@@ -1144,17 +1437,14 @@ func RepairInstance(ctx context.Context, inst *Instance, observer ProgressObserv
 			percentage := float64(i) / float64(totalRepair) * 100.0
 			observer.OnProgress(fmt.Sprintf("Repairing mod %d/%d: %s", i+1, totalRepair, filepath.Base(f.Path)), percentage, "", "main")
 
-			targetPath := filepath.Join(inst.Path, f.Path)
-			_ = os.MkdirAll(filepath.Dir(targetPath), 0755)
-
-			var downloadErr error
-			for _, url := range f.Downloads {
-				downloadErr = downloadWithVerify(ctx, url, targetPath, f.Hashes, observer, "Downloading "+filepath.Base(f.Path), "repair")
-				if downloadErr == nil {
-					break
-				}
+			targetPath, err := sandbox.Join(f.Path)
+			if err != nil {
+				repairErrs <- err
+				return
 			}
+			_ = os.MkdirAll(filepath.Dir(targetPath), 0755)
 
+			downloadErr := fetchFile(ctx, f, targetPath, observer, "Downloading "+filepath.Base(f.Path), "repair")
 			if downloadErr != nil {
 				repairErrs <- fmt.Errorf("failed to repair file %s: %w", f.Path, downloadErr)
 			}
@@ -1175,7 +1465,7 @@ func RepairInstance(ctx context.Context, inst *Instance, observer ProgressObserv
 			slog.Warn("Cannot repair local overrides without a remote repository", "count", len(corruptedOverrides))
 		} else {
 			observer.OnProgress("Repairing local files from repository", 0, "", "main")
-			repo, err := FetchRepository(ctx, inst.Upstream.ManifestURL)
+			repo, err := fetchUpstreamRepository(ctx, inst.Upstream)
 			if err != nil {
 				return fmt.Errorf("failed to fetch repository for repair: %w", err)
 			}
@@ -1209,6 +1499,13 @@ func RepairInstance(ctx context.Context, inst *Instance, observer ProgressObserv
 					}
 					defer reader.Close()
 
+					// Each patch is extracted with its own limits, since they run concurrently.
+					patchSandbox := newExtractSandbox(inst.Path)
+					if err := patchSandbox.CheckArchive(&reader.Reader); err != nil {
+						patchErrs <- err
+						return
+					}
+
 					for _, zf := range reader.File {
 						var rel string
 						if name, ok := strings.CutPrefix(zf.Name, "overrides/"); ok {
@@ -1218,27 +1515,15 @@ func RepairInstance(ctx context.Context, inst *Instance, observer ProgressObserv
 						}
 
 						if slices.Contains(corruptedOverrides, rel) {
-							targetPath := filepath.Join(inst.Path, rel)
-							_ = os.MkdirAll(filepath.Dir(targetPath), 0755)
-							rc, err := zf.Open()
-							if err != nil {
-								slog.Error("Failed to open file for repaired override", "path", rel, "patch", p.ID, "err", err)
-								continue
-							}
-							out, err := os.Create(targetPath)
+							targetPath, err := patchSandbox.Join(rel)
 							if err != nil {
-								slog.Error("Failed to create file for repaired override", "path", rel, "patch", p.ID, "err", err)
-								rc.Close()
+								slog.Error("Refusing to repair override", "path", rel, "patch", p.ID, "err", err)
 								continue
 							}
-							if _, err := io.Copy(out, rc); err != nil {
-								slog.Error("Failed to copy repaired override", "path", rel, "patch", p.ID, "err", err)
-								out.Close()
-								rc.Close()
+							if err := patchSandbox.Extract(zf, targetPath); err != nil {
+								slog.Error("Failed to extract repaired override", "path", rel, "patch", p.ID, "err", err)
 								continue
 							}
-							rc.Close()
-							out.Close()
 							slog.Info("Repaired override from patch", "path", rel, "patch", p.ID)
 						}
 					}
@@ -4,16 +4,21 @@ import (
 	"archive/zip"
 	"bytes"
 	"context"
+	"crypto/ed25519"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/ikafly144/sabalauncher/v2/pkg/resource"
@@ -65,6 +70,10 @@ func TestSBPackImportAndUpdate(t *testing.T) {
 	}))
 	defer server.Close()
 
+	oldDataDir := resource.DataDir
+	resource.DataDir = t.TempDir()
+	defer func() { resource.DataDir = oldDataDir }()
+
 	tempDir := t.TempDir()
 	packPath := filepath.Join(tempDir, "test.sbpack")
 	patchPath := filepath.Join(tempDir, "test.sbpatch")
@@ -190,6 +199,10 @@ func TestSBPackImportAndUpdate(t *testing.T) {
 }
 
 func TestSBPatchBinaryPatch(t *testing.T) {
+	oldDataDir := resource.DataDir
+	resource.DataDir = t.TempDir()
+	defer func() { resource.DataDir = oldDataDir }()
+
 	tempDir := t.TempDir()
 	destDir := filepath.Join(tempDir, "instance")
 	_ = os.MkdirAll(destDir, 0755)
@@ -400,4 +413,390 @@ func TestImportRemoteSBPack(t *testing.T) {
 	if _, err := os.Stat(filepath.Join(destDir, "mods/mod2.jar")); err != nil {
 		t.Errorf("mod2.jar missing: %v", err)
 	}
+
+	versions, err := resource.ListRemoteVersions(context.Background(), inst)
+	if err != nil {
+		t.Fatalf("ListRemoteVersions failed: %v", err)
+	}
+	if len(versions) != 2 || versions[0].Installed || !versions[1].Installed {
+		t.Errorf("expected the latest of two versions to be installed, got %+v", versions)
+	}
+
+	steps, err := resource.ResolveRemoteUpdate(context.Background(), inst, v1ID.String())
+	if err != nil || len(steps) != 1 || steps[0].ID != v1ID.String() {
+		t.Errorf("ResolveRemoteUpdate = %v, %v", steps, err)
+	}
+
+	// Rolling back applies the full pack of the older version
+	if err := resource.UpdateInstanceRemoteTo(context.Background(), inst, v1ID.String(), nil); err != nil {
+		t.Fatalf("rollback failed: %v", err)
+	}
+	if inst.Upstream.Version != v1ID.String() {
+		t.Errorf("expected version %s after rollback, got %s", v1ID.String(), inst.Upstream.Version)
+	}
+	if _, err := os.Stat(filepath.Join(destDir, "mods/mod1.jar")); err != nil {
+		t.Errorf("mod1.jar missing after rollback: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(destDir, "mods/mod2.jar")); !os.IsNotExist(err) {
+		t.Errorf("mod2.jar should be removed after rollback: %v", err)
+	}
+
+	inst.Upstream.Pinned = true
+	if err := resource.UpdateInstanceRemote(context.Background(), inst, nil); err != nil {
+		t.Fatalf("UpdateInstanceRemote failed: %v", err)
+	}
+	if inst.Upstream.Version != v1ID.String() {
+		t.Errorf("pinned instance must not be updated, got %s", inst.Upstream.Version)
+	}
+
+	inst.Upstream.Pinned = false
+	if err := resource.UpdateInstanceRemote(context.Background(), inst, nil); err != nil {
+		t.Fatalf("UpdateInstanceRemote failed: %v", err)
+	}
+	if inst.Upstream.Version != v2ID.String() {
+		t.Errorf("expected version %s after unpinning, got %s", v2ID.String(), inst.Upstream.Version)
+	}
+}
+
+func TestRemoteSBPackPublisherKeyPinning(t *testing.T) {
+	_, key1, _ := ed25519.GenerateKey(nil)
+	_, key2, _ := ed25519.GenerateKey(nil)
+	signingKey := key1
+
+	v1ID, _ := uuid.NewV7()
+	indexB, _ := json.Marshal(resource.SBPackIndex{
+		FormatVersion: resource.SBPackFormatVersion,
+		Name:          "Signed Pack",
+		ID:            v1ID,
+		Dependencies:  map[string]string{"minecraft": "1.20.1"},
+	})
+	indexSig, _ := resource.SignJSON(key1, indexB)
+	indexSigB, _ := json.Marshal(indexSig)
+	packPath := filepath.Join(t.TempDir(), "v1.sbpack")
+	createMockZip(t, packPath, map[string][]byte{
+		"sb.index.json":     indexB,
+		"sb.index.json.sig": indexSigB,
+	})
+	packB, _ := os.ReadFile(packPath)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		repo := resource.SBRepository{
+			Name: "Signed Pack",
+			Patches: []resource.SBRepoPatch{{
+				ID:         v1ID.String(),
+				Type:       resource.SBPatchTypePack,
+				Hash:       map[string]string{"sha256": calculateSHA256(packB)},
+				RemotePath: fmt.Sprintf("http://%s/repo/v1.sbpack", r.Host),
+				Timestamp:  100,
+			}},
+		}
+		repoB, _ := json.Marshal(repo)
+		switch r.URL.Path {
+		case "/repo/manifest.json":
+			_, _ = w.Write(repoB)
+		case "/repo/manifest.json.sig":
+			if signingKey == nil {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			sig, _ := resource.SignJSON(signingKey, repoB)
+			_ = json.NewEncoder(w).Encode(sig)
+		case "/repo/v1.sbpack":
+			_, _ = w.Write(packB)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	oldDataDir := resource.DataDir
+	resource.DataDir = t.TempDir()
+	defer func() { resource.DataDir = oldDataDir }()
+
+	inst, err := resource.ImportRemoteSBPack(context.Background(), server.URL+"/repo/manifest.json", filepath.Join(t.TempDir(), "inst"), uuid.New(), nil)
+	if err != nil {
+		t.Fatalf("ImportRemoteSBPack failed: %v", err)
+	}
+	pinned := resource.EncodePublicKey(key1.Public().(ed25519.PublicKey))
+	if inst.Upstream.PublisherKey != pinned {
+		t.Fatalf("expected publisher key %s to be pinned, got %q", pinned, inst.Upstream.PublisherKey)
+	}
+
+	if err := resource.UpdateInstanceRemote(context.Background(), inst, nil); err != nil {
+		t.Errorf("update signed by the pinned key should succeed: %v", err)
+	}
+
+	// Once a key is pinned, pack metadata must be signed by it too
+	v2ID, _ := uuid.NewV7()
+	unsignedB, _ := json.Marshal(resource.SBPackIndex{
+		FormatVersion: resource.SBPackFormatVersion,
+		Name:          "Signed Pack",
+		ID:            v2ID,
+		Dependencies:  map[string]string{"minecraft": "1.20.1"},
+	})
+	unsignedPath := filepath.Join(t.TempDir(), "v2.sbpack")
+	createMockZip(t, unsignedPath, map[string][]byte{"sb.index.json": unsignedB})
+	if err := resource.ApplySBPack(context.Background(), inst, unsignedPath, nil); !errors.Is(err, resource.ErrUnsignedPack) {
+		t.Errorf("expected ErrUnsignedPack, got %v", err)
+	}
+
+	signingKey = key2
+	if err := resource.UpdateInstanceRemote(context.Background(), inst, nil); !errors.Is(err, resource.ErrPublisherKeyMismatch) {
+		t.Errorf("expected ErrPublisherKeyMismatch, got %v", err)
+	}
+	if _, err := resource.CheckRemoteUpdate(context.Background(), inst); !errors.Is(err, resource.ErrPublisherKeyMismatch) {
+		t.Errorf("expected the update check to refuse the manifest, got %v", err)
+	}
+
+	signingKey = nil
+	if err := resource.UpdateInstanceRemote(context.Background(), inst, nil); !errors.Is(err, resource.ErrUnsignedRepository) {
+		t.Errorf("expected ErrUnsignedRepository, got %v", err)
+	}
+	if _, err := resource.CheckRemoteUpdate(context.Background(), inst); !errors.Is(err, resource.ErrUnsignedRepository) {
+		t.Errorf("expected the update check to refuse the unsigned manifest, got %v", err)
+	}
+
+	if inst.Upstream.PublisherKey != pinned {
+		t.Errorf("pinned key must not change after refused updates")
+	}
+}
+
+func TestSignatureTamperDetection(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(nil)
+	doc := []byte(`{"name": "Pack", "patches": []}`)
+	sig, err := resource.SignJSON(priv, doc)
+	if err != nil {
+		t.Fatalf("SignJSON failed: %v", err)
+	}
+
+	// Formatting changes keep the signature valid
+	if err := sig.Verify([]byte("{\n  \"patches\": [],\n  \"name\": \"Pack\"\n}")); err != nil {
+		t.Errorf("reformatted document should verify: %v", err)
+	}
+	if err := sig.Verify([]byte(`{"name": "Evil", "patches": []}`)); !errors.Is(err, resource.ErrSignatureInvalid) {
+		t.Errorf("expected ErrSignatureInvalid, got %v", err)
+	}
+}
+
+// createSignedPack writes a pack signed by priv whose index lists hashes for the overrides.
+func createSignedPack(t *testing.T, path string, priv ed25519.PrivateKey, index resource.SBPackIndex, files map[string][]byte) {
+	t.Helper()
+	indexB, _ := json.Marshal(index)
+	sig, err := resource.SignJSON(priv, indexB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigB, _ := json.Marshal(sig)
+	entries := map[string][]byte{"sb.index.json": indexB, "sb.index.json.sig": sigB}
+	for name, data := range files {
+		entries[name] = data
+	}
+	createMockZip(t, path, entries)
+}
+
+func TestSignedArchiveEntriesMustBeHashed(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(nil)
+	dir := t.TempDir()
+	v1ID, _ := uuid.NewV7()
+	v1 := resource.SBPackIndex{
+		FormatVersion: resource.SBPackFormatVersion,
+		Name:          "Signed Pack",
+		ID:            v1ID,
+		Hashes:        map[string]string{"config.txt": calculateSHA256([]byte("alpha"))},
+	}
+	v1Path := filepath.Join(dir, "v1.sbpack")
+	createSignedPack(t, v1Path, priv, v1, map[string][]byte{"overrides/config.txt": []byte("alpha")})
+
+	instDir := filepath.Join(dir, "inst")
+	inst, err := resource.ImportSBPack(context.Background(), v1Path, instDir, uuid.New(), nil)
+	if err != nil {
+		t.Fatalf("ImportSBPack failed: %v", err)
+	}
+
+	// An override the signed index does not list is refused
+	v2ID, _ := uuid.NewV7()
+	v2 := v1
+	v2.ID = v2ID
+	extraPath := filepath.Join(dir, "extra.sbpack")
+	createSignedPack(t, extraPath, priv, v2, map[string][]byte{
+		"overrides/config.txt": []byte("alpha"),
+		"overrides/extra.txt":  []byte("injected"),
+	})
+	if err := resource.ApplySBPack(context.Background(), inst, extraPath, nil); !errors.Is(err, resource.ErrUnsignedEntry) {
+		t.Errorf("expected ErrUnsignedEntry, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(instDir, "extra.txt")); !os.IsNotExist(err) {
+		t.Errorf("refused override must be rolled back")
+	}
+
+	// So is an override that differs from its signed hash
+	tamperedPath := filepath.Join(dir, "tampered.sbpack")
+	createSignedPack(t, tamperedPath, priv, v2, map[string][]byte{"overrides/config.txt": []byte("evil")})
+	if err := resource.ApplySBPack(context.Background(), inst, tamperedPath, nil); err == nil {
+		t.Errorf("expected a tampered override to be refused")
+	}
+	if got, _ := os.ReadFile(filepath.Join(instDir, "config.txt")); string(got) != "alpha" {
+		t.Errorf("config.txt = %q after a refused update", got)
+	}
+
+	// A binary patch is checked by the file it produces
+	var diff bytes.Buffer
+	if err := binarydist.Diff(bytes.NewReader([]byte("alpha")), bytes.NewReader([]byte("alpha beta")), &diff); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name   string
+		result string
+		ok     bool
+	}{
+		{"wrong result", "alpha gamma", false},
+		{"signed result", "alpha beta", true},
+	} {
+		v2.Hashes = map[string]string{"config.txt": calculateSHA256([]byte(tt.result))}
+		patchB, _ := json.Marshal(resource.SBPatch{FormatVersion: resource.SBPatchFormatVersion, BaseID: v1ID, Index: v2})
+		sig, _ := resource.SignJSON(priv, patchB)
+		sigB, _ := json.Marshal(sig)
+		patchPath := filepath.Join(dir, "v2.sbpatch")
+		createMockZip(t, patchPath, map[string][]byte{
+			"sb.patch.json":      patchB,
+			"sb.patch.json.sig":  sigB,
+			"patches/config.txt": diff.Bytes(),
+		})
+		err := resource.ApplySBPatch(context.Background(), inst, patchPath, nil)
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: ApplySBPatch = %v", tt.name, err)
+		}
+	}
+	if got, _ := os.ReadFile(filepath.Join(instDir, "config.txt")); string(got) != "alpha beta" {
+		t.Errorf("config.txt = %q after the signed patch", got)
+	}
+}
+
+type recordingObserver struct {
+	mu       sync.Mutex
+	statuses []string
+}
+
+func (o *recordingObserver) OnProgress(taskName string, percentage float64, status string, category string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.statuses = append(o.statuses, status)
+}
+
+func TestSBPackDownloadResumeAndMirrors(t *testing.T) {
+	modContent := bytes.Repeat([]byte("resumable-mod-content-"), 4096)
+	half := int64(len(modContent) / 2)
+
+	var rangeHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/broken/mod.jar":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/corrupt/mod.jar":
+			_, _ = w.Write([]byte("not the mod"))
+		case "/good/mod.jar":
+			rangeHeader = r.Header.Get("Range")
+			http.ServeContent(w, r, "mod.jar", time.Time{}, bytes.NewReader(modContent))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	id, _ := uuid.NewV7()
+	index := resource.SBPackIndex{
+		FormatVersion: resource.SBPackFormatVersion,
+		Name:          "Mirrors",
+		ID:            id,
+		Files: []resource.SBFile{{
+			Path:   "mods/mod.jar",
+			Hashes: map[string]string{"sha256": calculateSHA256(modContent)},
+			Downloads: []string{
+				server.URL + "/broken/mod.jar",
+				server.URL + "/corrupt/mod.jar",
+				server.URL + "/good/mod.jar",
+			},
+			FileSize: int64(len(modContent)),
+		}},
+	}
+	indexB, _ := json.Marshal(index)
+	packPath := filepath.Join(t.TempDir(), "mirrors.sbpack")
+	createMockZip(t, packPath, map[string][]byte{"sb.index.json": indexB})
+
+	oldDataDir := resource.DataDir
+	resource.DataDir = t.TempDir()
+	defer func() { resource.DataDir = oldDataDir }()
+
+	// Downloads are staged in the shared store
+	hash := calculateSHA256(modContent)
+	partPath := filepath.Join(resource.StoreDir(), "sha256", hash[:2], hash+".part")
+
+	destDir := filepath.Join(t.TempDir(), "inst")
+	// Simulate an interrupted download; the corrupt mirror must not poison it permanently.
+	_ = os.MkdirAll(filepath.Dir(partPath), 0755)
+	_ = os.WriteFile(partPath, modContent[:half], 0644)
+
+	observer := &recordingObserver{}
+	if _, err := resource.ImportSBPack(context.Background(), packPath, destDir, uuid.New(), observer); err != nil {
+		t.Fatalf("ImportSBPack failed: %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(destDir, "mods", "mod.jar"))
+	if err != nil || !bytes.Equal(got, modContent) {
+		t.Fatalf("mod.jar not downloaded correctly: %v", err)
+	}
+	if _, err := os.Stat(partPath); !os.IsNotExist(err) {
+		t.Errorf("part file should be removed after a successful download")
+	}
+	if rangeHeader != "" {
+		// The corrupt mirror discards the partial data, so the good mirror must restart from zero.
+		t.Errorf("expected a fresh download after hash mismatch, got Range %q", rangeHeader)
+	}
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	reported := false
+	for _, s := range observer.statuses {
+		if strings.Contains(s, host) {
+			reported = true
+		}
+	}
+	if !reported {
+		t.Errorf("mirror host was not reported through the observer: %v", observer.statuses)
+	}
+
+	// Resume from a partial file against a range-capable mirror
+	_ = os.Remove(filepath.Join(destDir, "mods", "mod.jar"))
+	_ = os.RemoveAll(resource.StoreDir())
+	_ = os.MkdirAll(filepath.Dir(partPath), 0755)
+	_ = os.WriteFile(partPath, modContent[:half], 0644)
+	index.Files[0].Downloads = []string{server.URL + "/good/mod.jar"}
+	inst := &resource.Instance{Path: destDir}
+	indexB, _ = json.Marshal(index)
+	_ = os.WriteFile(filepath.Join(destDir, "sb.index.json"), indexB, 0644)
+	if err := resource.RepairInstance(context.Background(), inst, nil); err != nil {
+		t.Fatalf("RepairInstance failed: %v", err)
+	}
+	if want := fmt.Sprintf("bytes=%d-", half); rangeHeader != want {
+		t.Errorf("expected resume with Range %q, got %q", want, rangeHeader)
+	}
+	got, _ = os.ReadFile(filepath.Join(destDir, "mods", "mod.jar"))
+	if !bytes.Equal(got, modContent) {
+		t.Errorf("resumed file content mismatch")
+	}
+
+	// A stale part from a different file must not fail the only mirror; it is fetched again from zero
+	_ = os.Remove(filepath.Join(destDir, "mods", "mod.jar"))
+	_ = os.RemoveAll(resource.StoreDir())
+	_ = os.MkdirAll(filepath.Dir(partPath), 0755)
+	_ = os.WriteFile(partPath, bytes.Repeat([]byte("x"), int(half)), 0644)
+	if err := resource.RepairInstance(context.Background(), inst, nil); err != nil {
+		t.Fatalf("RepairInstance with a stale part failed: %v", err)
+	}
+	if rangeHeader != "" {
+		t.Errorf("expected the retry to start from zero, got Range %q", rangeHeader)
+	}
+	got, _ = os.ReadFile(filepath.Join(destDir, "mods", "mod.jar"))
+	if !bytes.Equal(got, modContent) {
+		t.Errorf("file content mismatch after a stale part")
+	}
 }
@@ -2,10 +2,16 @@ package resource
 
 import (
 	"archive/zip"
+	"context"
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/ikafly144/sabalauncher/v2/pkg/i18n"
 )
@@ -16,107 +22,309 @@ func NewState(friendlyName, name string) *SetupState {
 		name:         name,
 		Steps:        []Step{},
 		StepCount:    0,
+		deps:         map[string][]string{},
+		finished:     map[string]bool{},
 	}
 }
 
+// SetupState runs its steps as a dependency graph: every step starts as soon as the steps it depends on have
+// finished, so independent downloads run side by side under a shared download budget.
 type SetupState struct {
 	friendlyName string
 	name         string
-	currentStep  Step
 	Steps        []Step
-	DoneCount    int // Count of steps added to the setup state
+	DoneCount    int // Count of finished steps
 	StepCount    int
-	done         bool // Indicates if the setup state is done
-	err          error
+
+	mu       sync.Mutex
+	deps     map[string][]string // Names of the steps each step waits for
+	running  []Step
+	finished map[string]bool
+	done     bool // Indicates if the setup state is done
+	err      error
 }
 
-func (s *SetupState) AddStep(step Step) {
+// AddStep adds a step that starts once the steps named by after have finished. A step without dependencies
+// starts right away.
+func (s *SetupState) AddStep(step Step, after ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.Steps = append(s.Steps, step)
 	s.StepCount++
+	s.deps[step.Name()] = after
 }
 
 func (s *SetupState) IsDone() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	return s.done
 }
 
 func (s *SetupState) Fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.err = err
 	s.done = true
 }
 
 func (s *SetupState) Error() error {
-	return s.err // Return the error of the setup state if no step is being processed
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
 }
 
 func (s *SetupState) FriendlyName() string {
-	if s.currentStep != nil {
-		// If a step is currently being processed, return its friendly name
-		return s.currentStep.FriendlyName()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.running) == 1 {
+		// If a single step is being processed, return its friendly name
+		return s.running[0].FriendlyName()
 	}
 	return s.friendlyName
 }
 
 func (s *SetupState) Name() string {
-	if s.currentStep != nil {
-		// If a step is currently being processed, return its name
-		return s.currentStep.Name()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.running) == 1 {
+		return s.running[0].Name()
 	}
 	return s.name
 }
 
+// Do runs the steps and returns the error of the first step that fails. The other running steps are cancelled
+// then, and no further steps are started.
 func (s *SetupState) Do(ctx *SetupContext) error {
+	s.mu.Lock()
 	if s.done {
+		s.mu.Unlock()
 		return nil // If the setup state is already done, do nothing
 	}
-	defer func() {
-		s.done = true
-	}()
-	for _, step := range s.Steps {
-		if s.currentStep != nil && step.Name() == s.currentStep.Name() {
-			// Skip the current step if it's already being processed
-			continue
+	steps := slices.Clone(s.Steps)
+	s.mu.Unlock()
+
+	err := s.run(ctx, steps)
+	s.mu.Lock()
+	s.err = err
+	s.done = true
+	s.mu.Unlock()
+	return err
+}
+
+func (s *SetupState) run(ctx *SetupContext, steps []Step) error {
+	if err := s.validate(steps); err != nil {
+		return err
+	}
+
+	runCtx, cancel := context.WithCancel(ctx.Context())
+	defer cancel()
+	if !hasDownloadBudget(runCtx) {
+		runCtx = withDownloadBudget(runCtx, DefaultDownloadConcurrency)
+	}
+	stepCtx := *ctx
+	stepCtx.ctx = runCtx
+
+	type result struct {
+		step Step
+		err  error
+	}
+	results := make(chan result)
+	started := map[string]bool{}
+	var firstErr error
+	for running := 0; ; running-- {
+		if firstErr == nil {
+			firstErr = runCtx.Err()
 		}
-		s.currentStep = step
-		if err := step.Do(ctx); err != nil {
-			s.err = err
-			return err
+		if firstErr == nil {
+			for _, step := range steps {
+				if started[step.Name()] || !s.ready(step) {
+					continue
+				}
+				started[step.Name()] = true
+				s.mu.Lock()
+				s.running = append(s.running, step)
+				s.mu.Unlock()
+				running++
+				go func() {
+					results <- result{step: step, err: step.Do(&stepCtx)}
+				}()
+			}
+		}
+		if running == 0 {
+			break
+		}
+
+		r := <-results
+		s.mu.Lock()
+		s.running = slices.DeleteFunc(s.running, func(step Step) bool { return step == r.step })
+		if r.err == nil {
+			s.finished[r.step.Name()] = true
+			s.DoneCount++
+		}
+		s.mu.Unlock()
+		if r.err != nil && firstErr == nil {
+			firstErr = r.err
+			cancel()
+		}
+	}
+	return firstErr
+}
+
+// ready reports whether the dependencies of step have finished.
+func (s *SetupState) ready(step Step) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, dep := range s.deps[step.Name()] {
+		if !s.finished[dep] {
+			return false
+		}
+	}
+	return true
+}
+
+// validate checks that step names are unique and that the dependencies name steps of the state without forming
+// a cycle.
+func (s *SetupState) validate(steps []Step) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pending := map[string][]string{}
+	for _, step := range steps {
+		if _, ok := pending[step.Name()]; ok {
+			return fmt.Errorf("duplicate setup step %q", step.Name())
+		}
+		pending[step.Name()] = s.deps[step.Name()]
+	}
+	for name, deps := range pending {
+		for _, dep := range deps {
+			if _, ok := pending[dep]; !ok {
+				return fmt.Errorf("setup step %q depends on unknown step %q", name, dep)
+			}
+		}
+	}
+	// Remove steps whose dependencies are resolved until nothing changes; whatever remains is a cycle.
+	resolved := map[string]bool{}
+	for len(resolved) < len(pending) {
+		progressed := false
+		for name, deps := range pending {
+			if resolved[name] {
+				continue
+			}
+			if !slices.ContainsFunc(deps, func(dep string) bool { return !resolved[dep] }) {
+				resolved[name] = true
+				progressed = true
+			}
+		}
+		if !progressed {
+			var cycle []string
+			for name := range pending {
+				if !resolved[name] {
+					cycle = append(cycle, name)
+				}
+			}
+			slices.Sort(cycle)
+			return fmt.Errorf("setup steps %v depend on each other", cycle)
 		}
-		s.DoneCount++
 	}
-	s.currentStep = nil // Reset current step after all steps are done
 	return nil
 }
 
 func (s *SetupState) Progress() float32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	if s.StepCount == 0 {
 		return 0.0
 	}
 	totalProgress := float32(s.DoneCount)
-	if s.currentStep != nil {
-		totalProgress += s.currentStep.Progress()
-	}
-	p := totalProgress / float32(s.StepCount)
-	if p < 0.0 {
-		return 0.0
-	}
-	if p > 1.0 {
-		return 1.0
+	for _, step := range s.running {
+		totalProgress += min(max(step.Progress(), 0), 1)
 	}
-	return p
+	return min(max(totalProgress/float32(s.StepCount), 0), 1)
 }
 
+// CurrentProgress returns the average progress of the running steps.
 func (s *SetupState) CurrentProgress() float32 {
-	if s.currentStep == nil {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.running) == 0 {
 		return 0.0 // No step is currently being processed
 	}
-	p := s.currentStep.Progress()
-	if p < 0.0 {
-		return 0.0
+	var p float32
+	for _, step := range s.running {
+		p += min(max(step.Progress(), 0), 1)
 	}
-	if p > 1.0 {
-		return 1.0
+	return p / float32(len(s.running))
+}
+
+// DownloadStats returns the combined download progress of the running steps, if any of them is downloading.
+func (s *SetupState) DownloadStats() (DownloadStats, bool) {
+	s.mu.Lock()
+	running := slices.Clone(s.running)
+	s.mu.Unlock()
+	var total DownloadStats
+	var found, unsized bool
+	for _, step := range running {
+		d, ok := step.(downloadStep)
+		if !ok {
+			continue
+		}
+		stats, ok := d.DownloadStats()
+		if !ok {
+			continue
+		}
+		found = true
+		total.DoneTasks += stats.DoneTasks
+		total.TotalTasks += stats.TotalTasks
+		total.DoneBytes += stats.DoneBytes
+		total.TotalBytes += stats.TotalBytes
+		unsized = unsized || stats.TotalBytes == 0
+		total.BytesPerSecond += stats.BytesPerSecond
+	}
+	if unsized {
+		total.TotalBytes = 0
+	}
+	if total.BytesPerSecond > 0 && total.TotalBytes > total.DoneBytes {
+		total.ETA = time.Duration(float64(total.TotalBytes-total.DoneBytes) / total.BytesPerSecond * float64(time.Second))
+	}
+	return total, found
+}
+
+// StepStatus is the progress of a single step of a SetupState.
+type StepStatus struct {
+	Name         string
+	FriendlyName string
+	Running      bool
+	Done         bool
+	Progress     float32
+	// Download is set if the step is downloading.
+	Download *DownloadStats
+}
+
+// StepStatuses returns the progress of each step in the order they were added.
+func (s *SetupState) StepStatuses() []StepStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	statuses := make([]StepStatus, 0, len(s.Steps))
+	for _, step := range s.Steps {
+		status := StepStatus{
+			Name:         step.Name(),
+			FriendlyName: step.FriendlyName(),
+			Running:      slices.Contains(s.running, step),
+			Done:         s.finished[step.Name()],
+		}
+		switch {
+		case status.Done:
+			status.Progress = 1.0
+		case status.Running:
+			status.Progress = min(max(step.Progress(), 0), 1)
+			if d, ok := step.(downloadStep); ok {
+				if stats, ok := d.DownloadStats(); ok {
+					status.Download = &stats
+				}
+			}
+		}
+		statuses = append(statuses, status)
 	}
-	return p
+	return statuses
 }
 
 type Step interface {
@@ -128,14 +336,56 @@ type Step interface {
 }
 
 type SetupContext struct {
+	ctx         context.Context
 	dataPath    string
 	profilePath string
+	// offline makes the steps use only files already on disk
+	offline bool
+}
+
+// Context returns the context that cancels the setup.
+func (c *SetupContext) Context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+// downloadProgress is embedded in steps that run a DownloadWorker and reports its progress.
+type downloadProgress struct {
+	worker atomic.Pointer[DownloadWorker]
+}
+
+func (d *downloadProgress) run(ctx *SetupContext, worker *DownloadWorker) error {
+	d.worker.Store(worker)
+	return worker.Run(ctx.Context())
+}
+
+func (d *downloadProgress) Progress() float32 {
+	worker := d.worker.Load()
+	if worker == nil {
+		return 0.0
+	}
+	return worker.Stats().Progress()
+}
+
+// DownloadStats returns the progress of the worker of the step, if it has started one.
+func (d *downloadProgress) DownloadStats() (DownloadStats, bool) {
+	worker := d.worker.Load()
+	if worker == nil {
+		return DownloadStats{}, false
+	}
+	return worker.Stats(), true
+}
+
+// downloadStep is implemented by steps that report byte-level download progress.
+type downloadStep interface {
+	DownloadStats() (DownloadStats, bool)
 }
 
 type JavaSetupStep struct {
 	manifest *ClientManifest
-	worker   *DownloadWorker
-	total    int
+	downloadProgress
 }
 
 var _ Step = (*JavaSetupStep)(nil)
@@ -149,29 +399,25 @@ func (j *JavaSetupStep) Name() string {
 }
 
 func (j *JavaSetupStep) Do(ctx *SetupContext) error {
+	if ctx.offline {
+		if _, err := GetJavaExecutablePath(j.manifest.JavaVersion.Component, ctx.dataPath); err != nil {
+			return fmt.Errorf("java runtime %s is %w", j.manifest.JavaVersion.Component, ErrNotAvailableOffline)
+		}
+		return nil
+	}
 	worker, err := DownloadJVM(j.manifest, ctx.dataPath)
 	if err != nil {
 		return err
 	}
-	j.worker = worker
-	j.total = j.worker.Remain()
-	if err := j.worker.Run(); err != nil {
+	if err := j.run(ctx, worker); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (j *JavaSetupStep) Progress() float32 {
-	if j.worker == nil || j.total == 0 {
-		return 0.0
-	}
-	return 1 - float32(j.worker.Remain())/float32(j.total)
-}
-
 type ClientDownloadStep struct {
 	manifest *ClientManifest
-	worker   *DownloadWorker
-	total    int
+	downloadProgress
 }
 
 var _ Step = (*ClientDownloadStep)(nil)
@@ -189,25 +435,18 @@ func (c *ClientDownloadStep) Do(ctx *SetupContext) error {
 	if err != nil {
 		return err
 	}
-	c.worker = worker
-	c.total = c.worker.Remain()
-	if err := c.worker.Run(); err != nil {
+	if err := checkOffline(ctx, worker, "client"); err != nil {
 		return err
 	}
-	return nil
-}
-
-func (c *ClientDownloadStep) Progress() float32 {
-	if c.worker == nil || c.total == 0 {
-		return 0.0
+	if err := c.run(ctx, worker); err != nil {
+		return err
 	}
-	return 1 - float32(c.worker.Remain())/float32(c.total)
+	return nil
 }
 
 type AssetsDownloadStep struct {
 	manifest *ClientManifest
-	worker   *DownloadWorker
-	total    int
+	downloadProgress
 }
 
 var _ Step = (*AssetsDownloadStep)(nil)
@@ -221,29 +460,22 @@ func (a *AssetsDownloadStep) Name() string {
 }
 
 func (a *AssetsDownloadStep) Do(ctx *SetupContext) error {
-	worker, err := DownloadAssets(a.manifest, ctx.dataPath)
+	worker, err := downloadAssets(a.manifest, ctx.dataPath, ctx.offline)
 	if err != nil {
 		return err
 	}
-	a.worker = worker
-	a.total = a.worker.Remain()
-	if err := a.worker.Run(); err != nil {
+	if err := checkOffline(ctx, worker, "asset"); err != nil {
 		return err
 	}
-	return nil
-}
-
-func (a *AssetsDownloadStep) Progress() float32 {
-	if a.worker == nil || a.total == 0 {
-		return 0.0
+	if err := a.run(ctx, worker); err != nil {
+		return err
 	}
-	return 1 - float32(a.worker.Remain())/float32(a.total)
+	return nil
 }
 
 type LibraryDownloadStep struct {
 	manifest *ClientManifest
-	worker   *DownloadWorker
-	total    int
+	downloadProgress
 }
 
 var _ Step = (*LibraryDownloadStep)(nil)
@@ -261,19 +493,13 @@ func (l *LibraryDownloadStep) Do(ctx *SetupContext) error {
 	if err != nil {
 		return err
 	}
-	l.worker = worker
-	l.total = l.worker.Remain()
-	if err := l.worker.Run(); err != nil {
+	if err := checkOffline(ctx, worker, "library"); err != nil {
 		return err
 	}
-	return nil
-}
-
-func (l *LibraryDownloadStep) Progress() float32 {
-	if l.worker == nil || l.total == 0 {
-		return 0.0
+	if err := l.run(ctx, worker); err != nil {
+		return err
 	}
-	return 1 - float32(l.worker.Remain())/float32(l.total)
+	return nil
 }
 
 func NewForgeSetupStep(vanillaVersionName, forgeVersionName string, vanillaManifest *ClientManifest, manifest *ClientManifest) Step {
@@ -287,7 +513,7 @@ func NewForgeSetupStep(vanillaVersionName, forgeVersionName string, vanillaManif
 		downloadStep:    step,
 		vanillaManifest: vanillaManifest,
 		manifest:        manifest,
-	})
+	}, step.Name())
 	return state
 }
 
@@ -295,8 +521,7 @@ type ForgeDownloadStep struct {
 	vanillaVersionName string
 	forgeVersionName   string
 	installerPath      *string // Path to the downloaded Forge installer
-	worker             *DownloadWorker
-	total              int
+	downloadProgress
 }
 
 var _ Step = (*ForgeDownloadStep)(nil)
@@ -319,22 +544,13 @@ func (f *ForgeDownloadStep) Do(ctx *SetupContext) error {
 	if err != nil {
 		return err
 	}
-	f.worker = worker
-	f.total = f.worker.Remain()
-	if err := f.worker.Run(); err != nil {
+	if err := f.run(ctx, worker); err != nil {
 		return err
 	}
 	f.installerPath = &path
 	return nil
 }
 
-func (f *ForgeDownloadStep) Progress() float32 {
-	if f.worker == nil || f.total == 0 {
-		return 0.0
-	}
-	return 1 - float32(f.worker.Remain())/float32(f.total)
-}
-
 type ForgeInstallStep struct {
 	downloadStep    *ForgeDownloadStep
 	progress        float32
@@ -399,8 +615,7 @@ type ModDownloadStep struct {
 	zipReader *zip.Reader
 	oldMods   *modLoader
 	newMods   *modLoader
-	worker    *DownloadWorker
-	total     int
+	downloadProgress
 }
 
 var _ Step = (*ModDownloadStep)(nil)
@@ -418,9 +633,7 @@ func (m *ModDownloadStep) Do(ctx *SetupContext) error {
 	if err != nil {
 		return nil
 	}
-	m.worker = worker
-	m.total = m.worker.Remain()
-	if err := m.worker.Run(); err != nil {
+	if err := m.run(ctx, worker); err != nil {
 		return err
 	}
 	f, err := os.Create(filepath.Join(ctx.profilePath, "manifest.json"))
@@ -433,10 +646,3 @@ func (m *ModDownloadStep) Do(ctx *SetupContext) error {
 	}
 	return nil
 }
-
-func (m *ModDownloadStep) Progress() float32 {
-	if m.worker == nil || m.total == 0 {
-		return 0.0
-	}
-	return 1 - float32(m.worker.Remain())/float32(m.total)
-}
@@ -0,0 +1,147 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeStep records when it runs and blocks until release is closed, if set.
+type fakeStep struct {
+	name    string
+	release chan struct{}
+	err     error
+	started chan struct{}
+	log     *stepLog
+}
+
+type stepLog struct {
+	mu    sync.Mutex
+	order []string
+}
+
+func (l *stepLog) add(s string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.order = append(l.order, s)
+}
+
+func newFakeStep(name string, log *stepLog) *fakeStep {
+	return &fakeStep{name: name, started: make(chan struct{}), log: log}
+}
+
+func (f *fakeStep) FriendlyName() string { return f.name }
+func (f *fakeStep) Name() string         { return f.name }
+func (f *fakeStep) Progress() float32    { return 0.5 }
+
+func (f *fakeStep) Do(ctx *SetupContext) error {
+	f.log.add("start " + f.name)
+	close(f.started)
+	if f.release != nil {
+		select {
+		case <-f.release:
+		case <-ctx.Context().Done():
+			f.log.add("cancel " + f.name)
+			return ctx.Context().Err()
+		}
+	}
+	f.log.add("end " + f.name)
+	return f.err
+}
+
+func TestSetupStateRunsIndependentStepsConcurrently(t *testing.T) {
+	log := &stepLog{}
+	a, b := newFakeStep("a", log), newFakeStep("b", log)
+	a.release, b.release = make(chan struct{}), make(chan struct{})
+	c := newFakeStep("c", log)
+
+	state := NewState("setup", "setup")
+	state.AddStep(c, "a", "b")
+	state.AddStep(a)
+	state.AddStep(b)
+
+	done := make(chan error)
+	go func() { done <- state.Do(&SetupContext{ctx: t.Context()}) }()
+
+	// a and b have no dependencies, so both start before either finishes.
+	for _, step := range []*fakeStep{a, b} {
+		select {
+		case <-step.started:
+		case <-time.After(time.Second):
+			t.Fatalf("expected %s to start", step.name)
+		}
+	}
+	if statuses := state.StepStatuses(); !statuses[1].Running || !statuses[2].Running || statuses[0].Running {
+		t.Errorf("unexpected step statuses while a and b run: %+v", statuses)
+	}
+	if got := state.Progress(); got != 1.0/3.0 {
+		t.Errorf("Progress() = %v, want %v", got, 1.0/3.0)
+	}
+	close(a.release)
+	select {
+	case <-c.started:
+		t.Fatal("expected c to wait for b")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(b.release)
+	if err := <-done; err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+	if !state.IsDone() || state.DoneCount != 3 || state.Progress() != 1.0 {
+		t.Errorf("expected every step to finish, got %d done", state.DoneCount)
+	}
+	if last := log.order[len(log.order)-1]; last != "end c" {
+		t.Errorf("expected c to run last, got %v", log.order)
+	}
+}
+
+func TestSetupStateFailureCancelsRunningSteps(t *testing.T) {
+	log := &stepLog{}
+	slow := newFakeStep("slow", log)
+	slow.release = make(chan struct{})
+	failing := newFakeStep("failing", log)
+	failing.err = errors.New("boom")
+	after := newFakeStep("after", log)
+
+	state := NewState("setup", "setup")
+	state.AddStep(slow)
+	state.AddStep(failing)
+	state.AddStep(after, "failing")
+
+	err := state.Do(&SetupContext{ctx: t.Context()})
+	if !errors.Is(err, failing.err) || !errors.Is(state.Error(), failing.err) {
+		t.Fatalf("expected the failure of the step, got %v", err)
+	}
+	if !slices.Contains(log.order, "cancel slow") {
+		t.Errorf("expected the running step to be cancelled, got %v", log.order)
+	}
+	if slices.Contains(log.order, "start after") {
+		t.Errorf("expected the dependent step not to start, got %v", log.order)
+	}
+}
+
+func TestSetupStateValidatesDependencies(t *testing.T) {
+	tests := map[string]func(s *SetupState){
+		"unknown": func(s *SetupState) {
+			s.AddStep(newFakeStep("a", &stepLog{}), "missing")
+		},
+		"cycle": func(s *SetupState) {
+			s.AddStep(newFakeStep("a", &stepLog{}), "b")
+			s.AddStep(newFakeStep("b", &stepLog{}), "a")
+		},
+		"duplicate": func(s *SetupState) {
+			s.AddStep(newFakeStep("a", &stepLog{}))
+			s.AddStep(newFakeStep("a", &stepLog{}))
+		},
+	}
+	for name, add := range tests {
+		state := NewState("setup", "setup")
+		add(state)
+		if err := state.Do(&SetupContext{ctx: context.Background()}); err == nil {
+			t.Errorf("%s: expected Do to fail", name)
+		}
+	}
+}
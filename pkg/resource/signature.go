@@ -0,0 +1,204 @@
+package resource
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const (
+	// SignatureSuffix is appended to the name of a signed document to locate its detached signature.
+	// The repository manifest "manifest.json" is signed by "manifest.json.sig", and the pack metadata
+	// "sb.index.json" / "sb.patch.json" by a ".sig" entry of the same name inside the archive.
+	SignatureSuffix = ".sig"
+
+	SignatureAlgorithmEd25519 = "ed25519"
+)
+
+var (
+	ErrSignatureInvalid     = errors.New("signature verification failed")
+	ErrPublisherKeyMismatch = errors.New("publisher key does not match the pinned key")
+	ErrUnsignedRepository   = errors.New("repository is not signed but the instance has a pinned publisher key")
+	ErrUnsignedPack         = errors.New("pack is not signed but the instance has a pinned publisher key")
+	ErrUnsignedEntry        = errors.New("archive entry is not covered by the signed metadata")
+)
+
+// SBSignature is a detached signature over the canonical JSON form of a document.
+type SBSignature struct {
+	Algorithm string `json:"algorithm"`
+	// Base64 encoded public key of the publisher.
+	PublicKey string `json:"publicKey"`
+	// Base64 encoded signature.
+	Signature string `json:"signature"`
+}
+
+// EncodePublicKey returns the textual form of a publisher key as stored in signatures and Upstream.
+func EncodePublicKey(pub ed25519.PublicKey) string {
+	return base64.StdEncoding.EncodeToString(pub)
+}
+
+// DecodePublicKey parses a publisher key produced by EncodePublicKey.
+func DecodePublicKey(s string) (ed25519.PublicKey, error) {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode public key: %w", err)
+	}
+	if len(b) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key size: %d", len(b))
+	}
+	return ed25519.PublicKey(b), nil
+}
+
+// CanonicalJSON re-encodes a JSON document with sorted object keys and no insignificant whitespace,
+// so that formatting changes do not invalidate a signature. Unknown fields are preserved.
+func CanonicalJSON(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected trailing data after JSON document")
+	}
+	return json.Marshal(v)
+}
+
+// SignJSON signs the canonical form of a JSON document.
+func SignJSON(priv ed25519.PrivateKey, data []byte) (*SBSignature, error) {
+	canonical, err := CanonicalJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to canonicalize document: %w", err)
+	}
+	return &SBSignature{
+		Algorithm: SignatureAlgorithmEd25519,
+		PublicKey: EncodePublicKey(priv.Public().(ed25519.PublicKey)),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(priv, canonical)),
+	}, nil
+}
+
+// Verify checks the signature against a JSON document.
+func (s *SBSignature) Verify(data []byte) error {
+	if s.Algorithm != SignatureAlgorithmEd25519 {
+		return fmt.Errorf("unsupported signature algorithm: %s", s.Algorithm)
+	}
+	pub, err := DecodePublicKey(s.PublicKey)
+	if err != nil {
+		return err
+	}
+	sig, err := base64.StdEncoding.DecodeString(s.Signature)
+	if err != nil {
+		return fmt.Errorf("failed to decode signature: %w", err)
+	}
+	canonical, err := CanonicalJSON(data)
+	if err != nil {
+		return fmt.Errorf("failed to canonicalize document: %w", err)
+	}
+	if !ed25519.Verify(pub, canonical, sig) {
+		return ErrSignatureInvalid
+	}
+	return nil
+}
+
+// checkPublisherKey enforces the key pinned on an instance. An empty pinned key accepts anything.
+func checkPublisherKey(pinned, actual string) error {
+	if pinned == "" || pinned == actual {
+		return nil
+	}
+	return fmt.Errorf("%w: expected %s, got %s", ErrPublisherKeyMismatch, pinned, actual)
+}
+
+// fetchSignature downloads the detached signature of a remote document.
+// A missing signature (404) is reported as nil without error.
+func fetchSignature(ctx context.Context, url string) (*SBSignature, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url+SignatureSuffix, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := mirrorDo(HTTPClient(), req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch signature: %s", resp.Status)
+	}
+
+	var sig SBSignature
+	if err := json.NewDecoder(resp.Body).Decode(&sig); err != nil {
+		return nil, fmt.Errorf("failed to parse signature: %w", err)
+	}
+	return &sig, nil
+}
+
+// readArchiveJSON decodes a metadata entry (sb.index.json or sb.patch.json) from a pack archive
+// and verifies its ".sig" entry when present. It returns the signer's public key, or "" if unsigned.
+func readArchiveJSON(reader *zip.Reader, name string, v any) (signer string, found bool, err error) {
+	var data, sigData []byte
+	for _, f := range reader.File {
+		if f.Name != name && f.Name != name+SignatureSuffix {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return "", false, err
+		}
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return "", false, err
+		}
+		if f.Name == name {
+			data = b
+			found = true
+		} else {
+			sigData = b
+		}
+	}
+	if !found {
+		return "", false, nil
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return "", true, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+
+	if sigData == nil {
+		return "", true, nil
+	}
+	var sig SBSignature
+	if err := json.Unmarshal(sigData, &sig); err != nil {
+		return "", true, fmt.Errorf("failed to parse %s: %w", name+SignatureSuffix, err)
+	}
+	if err := sig.Verify(data); err != nil {
+		return "", true, fmt.Errorf("invalid signature on %s: %w", name, err)
+	}
+	return sig.PublicKey, true, nil
+}
+
+// checkEntryHash verifies a file extracted from a pack archive to path against the SHA-256 the pack metadata records
+// for rel in hashes. The signature only covers the metadata, so signed archives must record a hash for every entry.
+func checkEntryHash(hashes map[string]string, rel, path string, signed bool) error {
+	expected, ok := hashes[rel]
+	if !ok {
+		if signed {
+			return fmt.Errorf("%w: %s", ErrUnsignedEntry, rel)
+		}
+		return nil
+	}
+	if err := verifyHashes(path, map[string]string{"sha256": expected}); err != nil {
+		return fmt.Errorf("%s does not match the pack metadata: %w", rel, err)
+	}
+	return nil
+}
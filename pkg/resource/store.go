@@ -0,0 +1,222 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// The shared store keeps one copy of every file listed in an SBPackIndex, addressed by its hash.
+// Instances hardlink (or copy, when linking is not possible) their files from the store, so packs
+// shipping the same jar only take the space once.
+var (
+	// storeMu is held for reading while blobs are fetched and linked, and for writing by the garbage collector.
+	storeMu sync.RWMutex
+	// storeKeyLocks serializes downloads of the same blob.
+	storeKeyLocks sync.Map
+)
+
+// storeHashAlgorithms lists the hash algorithms usable as a store key, strongest first.
+var storeHashAlgorithms = []string{"sha256", "sha512", "sha1"}
+
+// storeHashLengths is the length of a hex digest of each algorithm in storeHashAlgorithms.
+var storeHashLengths = map[string]int{"sha256": 64, "sha512": 128, "sha1": 40}
+
+// storeLockName is the file in StoreDir that processes lock to keep garbage collection away from blobs in use.
+const storeLockName = ".lock"
+
+// StoreDir returns the root directory of the shared content-addressed store.
+func StoreDir() string {
+	return filepath.Join(DataDir, "store")
+}
+
+// storeKey returns the store path of a file with the given hashes, relative to StoreDir.
+// Only well-formed hex digests are used, so a key never leaves its directory.
+func storeKey(hashes map[string]string) (string, bool) {
+	for _, algo := range storeHashAlgorithms {
+		for k, v := range hashes {
+			v = strings.ToLower(v)
+			if strings.ToLower(k) != algo || !isHexDigest(v, storeHashLengths[algo]) {
+				continue
+			}
+			return filepath.Join(algo, v[:2], v), true
+		}
+	}
+	return "", false
+}
+
+// isHexDigest reports whether v consists of exactly n lowercase hex digits.
+func isHexDigest(v string, n int) bool {
+	if len(v) != n {
+		return false
+	}
+	for _, c := range v {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// LockStore takes a shared lock on the shared store, which keeps GarbageCollectStore in any process from running
+// until the returned function is called. Hold it while linking blobs into an instance whose sb.index.json does not
+// list them yet.
+func LockStore() (func(), error) {
+	return lockStore(false)
+}
+
+func lockStore(exclusive bool) (func(), error) {
+	if err := os.MkdirAll(StoreDir(), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(StoreDir(), storeLockName), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f, exclusive); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock the shared store: %w", err)
+	}
+	return func() { f.Close() }, nil
+}
+
+// fetchFile places an index file at dest, downloading it into the shared store first if needed.
+// Files without a usable hash bypass the store.
+func fetchFile(ctx context.Context, file SBFile, dest string, observer ProgressObserver, taskName string, category string) error {
+	key, ok := storeKey(file.Hashes)
+	if !ok {
+		return downloadWithVerify(ctx, file.Downloads, dest, file.Hashes, observer, taskName, category)
+	}
+
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+
+	lock, _ := storeKeyLocks.LoadOrStore(key, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	blob := filepath.Join(StoreDir(), key)
+	if verifyHashes(blob, file.Hashes) != nil {
+		if err := os.MkdirAll(filepath.Dir(blob), 0755); err != nil {
+			return err
+		}
+		if err := downloadWithVerify(ctx, file.Downloads, blob, file.Hashes, observer, taskName, category); err != nil {
+			return err
+		}
+	}
+
+	return linkOrCopy(blob, dest)
+}
+
+// linkOrCopy replaces dest with a hardlink to src, falling back to a copy across volumes.
+func linkOrCopy(src, dest string) error {
+	if err := os.Remove(dest); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Link(src, dest); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// createFile truncates or creates a file for writing without touching the content of other links to it.
+// Files in an instance may be hardlinked to the shared store, so they must never be rewritten in place.
+func createFile(path string) (*os.File, error) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return os.Create(path)
+}
+
+// GarbageCollectStore removes blobs from the shared store that no sb.index.json in instanceDirs refers to.
+// It returns the number of removed blobs and the bytes freed.
+// It waits for every LockStore holder, in this process or another one, to let go.
+func GarbageCollectStore(instanceDirs []string) (int, int64, error) {
+	// Lock the file first: holders of the shared lock may still need storeMu to finish.
+	unlock, err := lockStore(true)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer unlock()
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	referenced := make(map[string]bool)
+	for _, dir := range instanceDirs {
+		b, err := os.ReadFile(filepath.Join(dir, "sb.index.json"))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return 0, 0, fmt.Errorf("failed to read index of %s: %w", dir, err)
+		}
+		var index SBPackIndex
+		if err := json.Unmarshal(b, &index); err != nil {
+			// Refuse to collect anything rather than dropping blobs of an instance we cannot read.
+			return 0, 0, fmt.Errorf("failed to parse index of %s: %w", dir, err)
+		}
+		for _, f := range index.Files {
+			if key, ok := storeKey(f.Hashes); ok {
+				referenced[key] = true
+			}
+		}
+	}
+
+	root := StoreDir()
+	removed := 0
+	var freed int64
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if rel == storeLockName || referenced[strings.TrimSuffix(rel, partSuffix)] {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(path); err != nil {
+			slog.Warn("Failed to remove unreferenced blob", "path", path, "error", err)
+			return nil
+		}
+		removed++
+		freed += info.Size()
+		return nil
+	})
+	if err != nil {
+		return removed, freed, err
+	}
+	return removed, freed, nil
+}
@@ -0,0 +1,130 @@
+package resource_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ikafly144/sabalauncher/v2/pkg/resource"
+)
+
+func TestSharedStoreDeduplicatesAndCollects(t *testing.T) {
+	modContent := []byte("shared_mod_content")
+	var downloads atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/shared.jar" {
+			downloads.Add(1)
+			_, _ = w.Write(modContent)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	oldDataDir := resource.DataDir
+	resource.DataDir = t.TempDir()
+	defer func() { resource.DataDir = oldDataDir }()
+
+	id, _ := uuid.NewV7()
+	indexB, _ := json.Marshal(resource.SBPackIndex{
+		FormatVersion: resource.SBPackFormatVersion,
+		Name:          "Shared",
+		ID:            id,
+		Files: []resource.SBFile{{
+			Path:      "mods/shared.jar",
+			Hashes:    map[string]string{"sha256": calculateSHA256(modContent)},
+			Downloads: []string{server.URL + "/shared.jar"},
+			FileSize:  int64(len(modContent)),
+		}},
+	})
+	packPath := filepath.Join(t.TempDir(), "shared.sbpack")
+	createMockZip(t, packPath, map[string][]byte{"sb.index.json": indexB})
+
+	dir1 := filepath.Join(resource.DataDir, "instances", "a")
+	dir2 := filepath.Join(resource.DataDir, "instances", "b")
+	for _, dir := range []string{dir1, dir2} {
+		if _, err := resource.ImportSBPack(context.Background(), packPath, dir, uuid.New(), nil); err != nil {
+			t.Fatalf("ImportSBPack failed: %v", err)
+		}
+	}
+
+	if n := downloads.Load(); n != 1 {
+		t.Errorf("expected the shared file to be downloaded once, got %d", n)
+	}
+
+	blob := filepath.Join(resource.StoreDir(), "sha256", calculateSHA256(modContent)[:2], calculateSHA256(modContent))
+	if _, err := os.Stat(blob); err != nil {
+		t.Fatalf("blob missing from store: %v", err)
+	}
+	for _, dir := range []string{dir1, dir2} {
+		got, err := os.ReadFile(filepath.Join(dir, "mods", "shared.jar"))
+		if err != nil || string(got) != string(modContent) {
+			t.Errorf("instance file in %s not materialized: %v", dir, err)
+		}
+	}
+
+	// A corrupted instance file is repaired without touching the other instance
+	_ = os.Remove(filepath.Join(dir1, "mods", "shared.jar"))
+	if err := resource.RepairInstance(context.Background(), &resource.Instance{Path: dir1}, nil); err != nil {
+		t.Fatalf("RepairInstance failed: %v", err)
+	}
+	if n := downloads.Load(); n != 1 {
+		t.Errorf("repair should reuse the stored blob, got %d downloads", n)
+	}
+
+	removed, _, err := resource.GarbageCollectStore([]string{dir2})
+	if err != nil {
+		t.Fatalf("GarbageCollectStore failed: %v", err)
+	}
+	if removed != 0 {
+		t.Errorf("referenced blob must be kept, removed %d", removed)
+	}
+
+	removed, _, err = resource.GarbageCollectStore(nil)
+	if err != nil {
+		t.Fatalf("GarbageCollectStore failed: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("expected unreferenced blob to be removed, removed %d", removed)
+	}
+	if _, err := os.Stat(blob); !os.IsNotExist(err) {
+		t.Errorf("blob should be gone after collection")
+	}
+	if _, err := os.ReadFile(filepath.Join(dir2, "mods", "shared.jar")); err != nil {
+		t.Errorf("collecting the store must not affect instance files: %v", err)
+	}
+}
+
+func TestGarbageCollectStoreWaitsForLock(t *testing.T) {
+	oldDataDir := resource.DataDir
+	resource.DataDir = t.TempDir()
+	defer func() { resource.DataDir = oldDataDir }()
+
+	unlock, err := resource.LockStore()
+	if err != nil {
+		t.Fatalf("LockStore failed: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := resource.GarbageCollectStore(nil)
+		done <- err
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("GarbageCollectStore ran while the store was locked")
+	case <-time.After(100 * time.Millisecond):
+	}
+	unlock()
+	if err := <-done; err != nil {
+		t.Fatalf("GarbageCollectStore failed: %v", err)
+	}
+}
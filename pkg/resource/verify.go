@@ -0,0 +1,397 @@
+package resource
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ikafly144/sabalauncher/v2/pkg/i18n"
+)
+
+// VerifyReport summarizes a VerifyGameFiles run.
+type VerifyReport struct {
+	// Checked is the number of files checked.
+	Checked int
+	// Repaired lists the files that were missing or corrupt and have been downloaded again,
+	// relative to the data directory.
+	Repaired []string
+	// Unrepairable lists corrupt files without a download source, such as libraries generated by the
+	// Forge installer. Reinstalling the mod loader restores them.
+	Unrepairable []string
+}
+
+// gameFile is a shared game file and the hash it must have.
+type gameFile struct {
+	path string
+	sha1 string
+	// size is 0 if unknown.
+	size int64
+	url  string
+	// maven is set for maven artifacts whose sha1 is looked up from the repository when empty.
+	maven bool
+}
+
+// valid reports whether the file exists with the expected size and hash.
+func (f gameFile) valid() bool {
+	info, err := os.Stat(f.path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	if f.size > 0 && info.Size() != f.size {
+		return false
+	}
+	return fileSHA1Matches(f.path, f.sha1)
+}
+
+// VerifyGameFiles checks the files shared by all instances — assets, libraries, client jars and Java
+// runtimes — against the version manifests in dataDir and downloads anything missing or corrupt again.
+// Antivirus software regularly quarantines or truncates these files.
+func VerifyGameFiles(ctx context.Context, dataDir string, observer ProgressObserver) (*VerifyReport, error) {
+	if observer == nil {
+		observer = &NopProgressObserver{}
+	}
+	manifests, err := localClientManifests(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	v := &gameFileVerifier{dataDir: dataDir, observer: observer, report: &VerifyReport{}, seen: map[string]bool{}}
+
+	var components []string
+	for _, m := range manifests {
+		v.addManifest(m)
+		if c := m.JavaVersion.Component; c != "" && !slices.Contains(components, c) {
+			components = append(components, c)
+		}
+	}
+	if err := v.addLoaderLibraries(); err != nil {
+		return v.report, err
+	}
+	if err := v.run(ctx, i18n.T("verify_files")); err != nil {
+		return v.report, err
+	}
+
+	// The asset indexes are in place now, so the objects they list can be checked.
+	for _, m := range manifests {
+		if err := v.addAssetObjects(m); err != nil {
+			return v.report, err
+		}
+	}
+	if err := v.run(ctx, i18n.T("verify_assets")); err != nil {
+		return v.report, err
+	}
+
+	for _, component := range components {
+		if err := v.verifyRuntime(ctx, component); err != nil {
+			return v.report, err
+		}
+	}
+	observer.OnProgress(i18n.T("verify_done"), 100, "", "main")
+	return v.report, nil
+}
+
+// localClientManifests returns the manifests of every version in the versions directory.
+func localClientManifests(dataDir string) ([]*ClientManifest, error) {
+	entries, err := os.ReadDir(filepath.Join(dataDir, "versions"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read versions directory: %w", err)
+	}
+	var manifests []*ClientManifest
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		m, err := GetLocalClientManifest(dataDir, e.Name())
+		if err != nil {
+			if !os.IsNotExist(err) {
+				slog.Warn("Skipping unreadable version manifest", "version", e.Name(), "error", err)
+			}
+			continue
+		}
+		manifests = append(manifests, m)
+	}
+	return manifests, nil
+}
+
+type gameFileVerifier struct {
+	dataDir  string
+	observer ProgressObserver
+	report   *VerifyReport
+	seen     map[string]bool
+	files    []gameFile
+	mu       sync.Mutex
+}
+
+func (v *gameFileVerifier) add(f gameFile) {
+	if v.seen[f.path] {
+		return
+	}
+	v.seen[f.path] = true
+	v.files = append(v.files, f)
+}
+
+func (v *gameFileVerifier) addManifest(m *ClientManifest) {
+	if c := m.Downloads.Client; c.Sha1 != "" {
+		v.add(gameFile{
+			path: filepath.Join(v.dataDir, "versions", m.ID, m.ID+".jar"),
+			sha1: c.Sha1, size: int64(c.Size), url: c.URL,
+		})
+	}
+	for _, lib := range m.Libraries {
+		if !lib.Allowed() {
+			continue
+		}
+		artifacts := []LibraryArtifact{lib.Downloads.Artifact}
+		if native, ok := lib.NativeArtifact(); ok {
+			artifacts = append(artifacts, native)
+		}
+		for _, a := range artifacts {
+			if a.Path == "" || a.Sha1 == "" {
+				continue
+			}
+			v.add(gameFile{
+				path: filepath.Join(v.dataDir, "libraries", filepath.FromSlash(a.Path)),
+				sha1: a.Sha1, size: int64(a.Size), url: a.URL,
+			})
+		}
+	}
+	if idx := m.AssetIndex; idx.ID != "" && idx.Sha1 != "" {
+		v.add(gameFile{
+			path: filepath.Join(v.dataDir, "assets", "indexes", idx.ID+".json"),
+			sha1: idx.Sha1, size: int64(idx.Size), url: idx.URL,
+		})
+	}
+	if f := m.Logging.Client.File; f.ID != "" && f.Sha1 != "" {
+		v.add(gameFile{
+			path: filepath.Join(v.dataDir, "assets", "log_configs", f.ID),
+			sha1: f.Sha1, size: int64(f.Size), url: f.URL,
+		})
+	}
+}
+
+// addLoaderLibraries adds the libraries of the Fabric and Quilt loaders installed in the versions directory.
+// Their metadata usually has no hashes, so the checksums published next to the artifacts are used.
+func (v *gameFileVerifier) addLoaderLibraries() error {
+	metas, err := filepath.Glob(filepath.Join(v.dataDir, "versions", "*", "*-meta.json"))
+	if err != nil {
+		return err
+	}
+	for _, metaPath := range metas {
+		var libs []FabricLibraryInfo
+		switch filepath.Base(metaPath) {
+		case "fabric-meta.json":
+			var meta FabricMetaResponse
+			if err := readJSONFile(metaPath, &meta); err != nil {
+				slog.Warn("Skipping unreadable loader metadata", "path", metaPath, "error", err)
+				continue
+			}
+			libs = append(meta.LauncherMeta.Libraries.Common, meta.LauncherMeta.Libraries.Client...)
+			libs = append(libs, FabricLibraryInfo{Name: meta.Loader.Maven, URL: FabricMavenURL}, FabricLibraryInfo{Name: meta.Intermediary.Maven, URL: FabricMavenURL})
+		case "quilt-meta.json":
+			var meta QuiltLauncherMeta
+			if err := readJSONFile(metaPath, &meta); err != nil {
+				slog.Warn("Skipping unreadable loader metadata", "path", metaPath, "error", err)
+				continue
+			}
+			for _, lib := range meta.Libraries {
+				libs = append(libs, FabricLibraryInfo{Name: lib.Name, URL: lib.URL, Sha1: lib.Sha1})
+			}
+		default:
+			continue
+		}
+		for _, lib := range libs {
+			libPath := mavenToPath(lib.Name, "/")
+			if libPath == "" {
+				continue
+			}
+			path := filepath.Join(v.dataDir, "libraries", filepath.FromSlash(libPath))
+			url := strings.TrimSuffix(lib.URL, "/") + "/" + libPath
+			v.add(gameFile{path: path, sha1: lib.Sha1, url: url, maven: true})
+		}
+	}
+	return nil
+}
+
+// addAssetObjects adds the objects listed by the asset index of m.
+func (v *gameFileVerifier) addAssetObjects(m *ClientManifest) error {
+	if m.AssetIndex.ID == "" {
+		return nil
+	}
+	var assets Assets
+	if err := readJSONFile(filepath.Join(v.dataDir, "assets", "indexes", m.AssetIndex.ID+".json"), &assets); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read asset index %s: %w", m.AssetIndex.ID, err)
+	}
+	for _, asset := range assets.Objects {
+		if len(asset.Hash) < 2 {
+			continue
+		}
+		v.add(gameFile{
+			path: filepath.Join(v.dataDir, "assets", "objects", asset.Hash[:2], asset.Hash),
+			sha1: asset.Hash, size: int64(asset.Size),
+			url: MojangAssetResourceURL + asset.Hash[:2] + "/" + asset.Hash,
+		})
+	}
+	return nil
+}
+
+// run checks the queued files and downloads the invalid ones again.
+func (v *gameFileVerifier) run(ctx context.Context, taskName string) error {
+	files := v.files
+	v.files = nil
+	var worker DownloadWorker
+	var checked atomic.Int64
+	for _, f := range files {
+		worker.addTask(0, func(ctx context.Context, progress io.Writer) error {
+			defer func() {
+				n := checked.Add(1)
+				v.observer.OnProgress(taskName, float64(n)/float64(len(files))*100, fmt.Sprintf("%d / %d", n, len(files)), "main")
+			}()
+			if f.sha1 == "" && f.maven {
+				sum, err := mavenSHA1(ctx, f.url)
+				if err != nil {
+					return fmt.Errorf("failed to fetch checksum of %s: %w", f.url, err)
+				}
+				f.sha1 = sum
+			}
+			if f.valid() {
+				return nil
+			}
+			rel, _ := filepath.Rel(v.dataDir, f.path)
+			if f.url == "" {
+				slog.Warn("Corrupt game file has no download source", "path", f.path)
+				v.mu.Lock()
+				v.report.Unrepairable = append(v.report.Unrepairable, rel)
+				v.mu.Unlock()
+				return nil
+			}
+			slog.Info("Downloading corrupt game file again", "path", f.path)
+			if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+				return err
+			}
+			hashes := map[string]string{}
+			if f.sha1 != "" {
+				hashes["sha1"] = f.sha1
+			}
+			if err := downloadWithVerify(ctx, []string{f.url}, f.path, hashes, nil, rel, "verify"); err != nil {
+				return fmt.Errorf("failed to repair %s: %w", rel, err)
+			}
+			v.mu.Lock()
+			v.report.Repaired = append(v.report.Repaired, rel)
+			v.mu.Unlock()
+			return nil
+		})
+	}
+	err := worker.Run(ctx)
+	v.report.Checked += len(files)
+	return err
+}
+
+// verifyRuntime checks an installed Java runtime against its manifest and downloads corrupt files again.
+// Runtimes that are not installed are left alone.
+func (v *gameFileVerifier) verifyRuntime(ctx context.Context, component string) error {
+	if _, err := GetJavaExecutablePath(component, v.dataDir); err != nil {
+		return nil
+	}
+	v.observer.OnProgress(i18n.T("verify_runtime", component), 0, "", "main")
+	var worker DownloadWorker
+	if err := installJavaRuntime(component, v.dataDir, &worker); err != nil {
+		return fmt.Errorf("failed to verify java runtime %s: %w", component, err)
+	}
+	if n := worker.Remain(); n > 0 {
+		slog.Info("Downloading corrupt java runtime files again", "component", component, "files", n)
+		v.report.Repaired = append(v.report.Repaired, fmt.Sprintf("runtime/%s (%d files)", component, n))
+		if err := worker.Run(ctx); err != nil {
+			return fmt.Errorf("failed to repair java runtime %s: %w", component, err)
+		}
+	}
+	return nil
+}
+
+// sizeMatches reports whether path exists with the given size, or at all if size is 0. It is the cheap check
+// done on every launch; VerifyGameFiles checks the hashes.
+func sizeMatches(path string, size int64) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return size <= 0 || info.Size() == size
+}
+
+// writeVerified writes r to path through a temporary file and moves it into place only if its SHA-1 matches
+// sum. An empty sum skips the check. The written bytes are reported to progress as well.
+func writeVerified(r io.Reader, path, sum string, progress io.Writer) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return 0, err
+	}
+	tmpPath := path + partSuffix
+	out, err := os.Create(tmpPath)
+	if err != nil {
+		return 0, err
+	}
+	hasher := sha1.New()
+	written, err := io.Copy(io.MultiWriter(out, hasher, progress), r)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && sum != "" {
+		if got := hex.EncodeToString(hasher.Sum(nil)); !strings.EqualFold(got, sum) {
+			err = fmt.Errorf("checksum mismatch for %s: expected %s, got %s", filepath.Base(path), sum, got)
+		}
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return written, err
+	}
+	return written, os.Rename(tmpPath, path)
+}
+
+func readJSONFile(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// mavenSHA1 fetches the .sha1 file maven repositories publish next to an artifact. It returns an empty
+// string if the repository has none.
+func mavenSHA1(ctx context.Context, artifactURL string) (string, error) {
+	resp, err := MirrorGetContext(ctx, artifactURL+".sha1")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("bad status: %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return "", err
+	}
+	// Some repositories append the file name after the hash.
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 || len(fields[0]) != 40 {
+		return "", nil
+	}
+	return strings.ToLower(fields[0]), nil
+}
@@ -0,0 +1,123 @@
+package resource
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestVerifyGameFiles(t *testing.T) {
+	const (
+		clientJar = "client jar"
+		library   = "library"
+		asset     = "asset object"
+	)
+	assetHash := sha1Hex([]byte(asset))
+	index, _ := json.Marshal(Assets{Objects: map[string]Asset{"minecraft/lang/en_us.json": {Hash: assetHash, Size: len(asset)}}})
+
+	files := map[string]string{
+		"/client.jar": clientJar,
+		"/lib.jar":    library,
+		"/index.json": string(index),
+		"/objects/" + assetHash[:2] + "/" + assetHash: asset,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(data))
+	}))
+	defer srv.Close()
+	SetMirrorProfile(&MirrorProfile{Hosts: map[string][]string{
+		"https://resources.download.minecraft.net": {srv.URL + "/objects"},
+	}})
+	t.Cleanup(func() { SetMirrorProfile(nil) })
+
+	dataDir := t.TempDir()
+	manifest := ClientManifest{
+		ID:         "1.0",
+		AssetIndex: AssetIndex{ID: "1", Sha1: sha1Hex(index), Size: len(index), URL: srv.URL + "/index.json"},
+		Downloads:  Downloads{Client: Download{Sha1: sha1Hex([]byte(clientJar)), Size: len(clientJar), URL: srv.URL + "/client.jar"}},
+		Libraries: []Library{
+			{Name: "a:lib:1", Downloads: LibraryDownloads{Artifact: LibraryArtifact{Path: "a/lib.jar", Sha1: sha1Hex([]byte(library)), Size: len(library), URL: srv.URL + "/lib.jar"}}},
+			{Name: "a:generated:1", Downloads: LibraryDownloads{Artifact: LibraryArtifact{Path: "a/generated.jar", Sha1: sha1Hex([]byte("generated")), Size: len("generated")}}},
+		},
+	}
+	data, _ := json.Marshal(manifest)
+	write := func(rel, content string) {
+		path := filepath.Join(dataDir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("versions/1.0/1.0.json", string(data))
+	// The client jar was truncated, the generated library is corrupt and the asset object is missing.
+	write("versions/1.0/1.0.jar", "client")
+	write("libraries/a/lib.jar", library)
+	write("libraries/a/generated.jar", "corrupted")
+	write("assets/indexes/1.json", string(index))
+
+	report, err := VerifyGameFiles(t.Context(), dataDir, nil)
+	if err != nil {
+		t.Fatalf("VerifyGameFiles failed: %v", err)
+	}
+	if report.Checked != 5 {
+		t.Errorf("expected 5 checked files, got %d", report.Checked)
+	}
+	wantRepaired := []string{
+		filepath.Join("assets", "objects", assetHash[:2], assetHash),
+		filepath.Join("versions", "1.0", "1.0.jar"),
+	}
+	slices.Sort(report.Repaired)
+	if !slices.Equal(report.Repaired, wantRepaired) {
+		t.Errorf("Repaired = %v, want %v", report.Repaired, wantRepaired)
+	}
+	if want := []string{filepath.Join("libraries", "a", "generated.jar")}; !slices.Equal(report.Unrepairable, want) {
+		t.Errorf("Unrepairable = %v, want %v", report.Unrepairable, want)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dataDir, "versions", "1.0", "1.0.jar")); string(got) != clientJar {
+		t.Errorf("client jar was not repaired: %q", got)
+	}
+	if !fileSHA1Matches(filepath.Join(dataDir, "assets", "objects", assetHash[:2], assetHash), assetHash) {
+		t.Error("asset object was not downloaded")
+	}
+}
+
+func TestDownloadFileVerifiesChecksum(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/good.jar", "/bad.jar":
+			_, _ = w.Write([]byte("jar"))
+		case "/bad.jar.sha1":
+			_, _ = w.Write([]byte(sha1Hex([]byte("other")) + "  bad.jar\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.jar")
+	if err := downloadFile(t.Context(), srv.URL+"/good.jar", good, sha1Hex([]byte("jar")), io.Discard); err != nil {
+		t.Fatalf("expected the download to succeed: %v", err)
+	}
+	bad := filepath.Join(dir, "bad.jar")
+	if err := downloadFile(t.Context(), srv.URL+"/bad.jar", bad, "", io.Discard); err == nil {
+		t.Fatal("expected a checksum mismatch against the published .sha1")
+	}
+	for _, path := range []string{bad, bad + partSuffix} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed", filepath.Base(path))
+		}
+	}
+}
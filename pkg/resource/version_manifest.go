@@ -3,6 +3,7 @@ package resource
 import (
 	"encoding/json"
 	"errors"
+	"regexp"
 	"strings"
 
 	"github.com/ikafly144/sabalauncher/v2/pkg/osinfo"
@@ -24,6 +25,8 @@ type ClientManifest struct {
 	Time                   string      `json:"time"`
 	Type                   string      `json:"type"`
 	InheritsFrom           string      `json:"inheritsFrom,omitempty"`
+	// MinecraftArguments replaces Arguments in versions before 1.13.
+	MinecraftArguments string `json:"minecraftArguments,omitempty"`
 }
 
 func (c ClientManifest) InheritsMerge(other *ClientManifest) (*ClientManifest, error) {
@@ -55,6 +58,7 @@ func (c ClientManifest) InheritsMerge(other *ClientManifest) (*ClientManifest, e
 		Time:                   c.Time,
 		Type:                   c.Type,
 		InheritsFrom:           c.InheritsFrom,
+		MinecraftArguments:     c.MinecraftArguments,
 	}
 
 	// Merge the other manifest into this one
@@ -91,6 +95,11 @@ func (c ClientManifest) InheritsMerge(other *ClientManifest) (*ClientManifest, e
 	finalLibs = append(finalLibs, other.Libraries...)
 	n.Libraries = finalLibs
 
+	// Legacy child manifests repeat the whole argument string instead of adding to it.
+	if other.MinecraftArguments != "" {
+		n.MinecraftArguments = other.MinecraftArguments
+	}
+
 	n.MainClass = other.MainClass
 	n.ID = other.ID
 	n.Type = other.Type
@@ -452,6 +461,39 @@ type LibraryRule struct {
 	Os     JvmArgumentRuleTypeOS `json:"os"`
 }
 
+// matches reports whether the rule applies to the current OS. A rule without an OS applies everywhere.
+func (r LibraryRule) matches() bool {
+	if r.Os.Name != "" && r.Os.Name != osName() {
+		return false
+	}
+	if r.Os.Arch != "" && !isMatchArch(r.Os.Arch) {
+		return false
+	}
+	if r.Os.Version != "" {
+		// The version is a regular expression, such as "^10\\." for Windows 10.
+		re, err := regexp.Compile(r.Os.Version)
+		if err != nil || !re.MatchString(osinfo.GetOsVersion()) {
+			return false
+		}
+	}
+	return true
+}
+
+// Allowed reports whether the library is used on the current OS. As in the official launcher,
+// the last rule that applies decides, and a library with rules is only used if one allows it.
+func (l Library) Allowed() bool {
+	if len(l.Rules) == 0 {
+		return true
+	}
+	allowed := false
+	for _, rule := range l.Rules {
+		if rule.matches() {
+			allowed = rule.Action.Allowed()
+		}
+	}
+	return allowed
+}
+
 type Logging struct {
 	Client LoggingClient `json:"client"`
 }
@@ -14,12 +14,11 @@ import (
 	"runtime"
 	"slices"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/ikafly144/sabalauncher/v2/pkg/buildinfo"
 	"github.com/ikafly144/sabalauncher/v2/pkg/msa"
-	"github.com/ikafly144/sabalauncher/v2/pkg/osinfo"
+	"github.com/ikafly144/sabalauncher/v2/pkg/redact"
 	"github.com/ikafly144/sabalauncher/v2/pkg/runcmd"
 )
 
@@ -51,7 +50,7 @@ type Version struct {
 }
 
 func GetManifest() (*VersionManifest, error) {
-	resp, err := http.Get(MojangVersionManifestURL)
+	resp, err := MirrorGet(MojangVersionManifestURL)
 	if err != nil {
 		return nil, err
 	}
@@ -86,7 +85,7 @@ func GetClientManifest(version *Version) (*ClientManifest, error) {
 	if version == nil {
 		return nil, errors.New("version is nil")
 	}
-	resp, err := http.Get(version.URL)
+	resp, err := MirrorGet(version.URL)
 	if err != nil {
 		return nil, err
 	}
@@ -139,140 +138,64 @@ type Asset struct {
 
 type Assets struct {
 	Objects map[string]Asset `json:"objects"`
+	// Virtual and MapToResources are set by the indexes of versions before 1.7.3,
+	// which read assets by name instead of by hash.
+	Virtual        bool `json:"virtual,omitempty"`
+	MapToResources bool `json:"map_to_resources,omitempty"`
 }
 
-type DownloadWorker struct {
-	m      sync.Mutex
-	w      sync.WaitGroup
-	remain int
-	tasks  []func() error
-	err    error
-}
-
-func (w *DownloadWorker) addTask(task func() error) {
-	if task == nil {
-		return
-	}
-	w.m.Lock()
-	defer w.m.Unlock()
-	w.w.Add(1)
-	w.remain++
-	w.tasks = append(w.tasks, task)
+func DownloadAssets(clientManifest *ClientManifest, dataDir string) (*DownloadWorker, error) {
+	return downloadAssets(clientManifest, dataDir, false)
 }
 
-func (w *DownloadWorker) Run() (err error) {
-	const (
-		maxProcCount = 8
-	)
-	for range maxProcCount {
-		go func() {
-			if e := w.run(); e != nil {
-				w.err = e
-				slog.Error("Download worker encountered an error", "error", e)
-				if err != nil {
-					e = fmt.Errorf("%w: %w", err, e)
-				}
-				err = e
-			}
-		}()
+// loadAssetIndex returns the asset index of the manifest, using the copy on disk if its hash matches.
+// It reports whether the index was fetched and still has to be saved.
+func loadAssetIndex(clientManifest *ClientManifest, assetIndexPath string, offline bool) ([]byte, bool, error) {
+	if fileSHA1Matches(assetIndexPath, clientManifest.AssetIndex.Sha1) {
+		data, err := os.ReadFile(assetIndexPath)
+		return data, false, err
 	}
-	if err := w.Wait(); err != nil {
-		slog.Error("Download worker failed", "error", err)
-		w.err = err
+	if offline {
+		return nil, false, fmt.Errorf("asset index %s is %w", clientManifest.AssetIndex.ID, ErrNotAvailableOffline)
 	}
-	return err
-}
 
-func (w *DownloadWorker) run() error {
-	retry := 0
-	for {
-		w.m.Lock()
-		if len(w.tasks) == 0 {
-			w.m.Unlock()
-			break
-		}
-		task := w.tasks[0]
-		w.tasks = w.tasks[1:]
-		w.m.Unlock()
-		if err := task(); err != nil {
-			if retry < 5 {
-				w.m.Lock()
-				retry++
-				w.tasks = append(w.tasks, task)
-				slog.Error("Task failed, retrying", "error", err)
-				w.m.Unlock()
-				time.Sleep(5 * time.Second)
-				continue
-			}
-			return err
-		}
-		w.m.Lock()
-		w.remain--
-		w.w.Done()
-		if w.remain == 0 {
-			w.m.Unlock()
-			break
-		}
-		w.m.Unlock()
-		time.Sleep(10 * time.Millisecond)
+	resp, err := MirrorGet(clientManifest.AssetIndex.URL)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to fetch asset index: %w", err)
 	}
-	return nil
-}
-
-func (w *DownloadWorker) Wait() error {
-	wait := func() <-chan struct{} {
-		done := make(chan struct{})
-		go func() {
-			w.w.Wait()
-			close(done)
-		}()
-		return done
-	}()
-	for {
-		select {
-		case <-wait:
-			return nil
-		default:
-			if w.err != nil {
-				slog.Error("Download worker encountered an error", "error", w.err)
-				return w.err
-			}
-			time.Sleep(100 * time.Millisecond)
-		}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, errors.New("failed to fetch assets")
 	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to fetch asset index: %w", err)
+	}
+	return data, true, nil
 }
 
-func (w *DownloadWorker) Remain() int {
-	w.m.Lock()
-	defer w.m.Unlock()
-	return w.remain
-}
-
-func DownloadAssets(clientManifest *ClientManifest, dataDir string) (*DownloadWorker, error) {
+func downloadAssets(clientManifest *ClientManifest, dataDir string, offline bool) (*DownloadWorker, error) {
 	if clientManifest == nil {
 		return nil, errors.New("client manifest is nil")
 	}
 
-	resp, err := http.Get(clientManifest.AssetIndex.URL)
+	assetIndexPath := filepath.Join(dataDir, "assets", "indexes", clientManifest.AssetIndex.ID+".json")
+	data, fetched, err := loadAssetIndex(clientManifest, assetIndexPath, offline)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch asset index: %w", err)
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, errors.New("failed to fetch assets")
+		return nil, err
 	}
 	var assets Assets
-	if err := json.NewDecoder(resp.Body).Decode(&assets); err != nil {
+	if err := json.Unmarshal(data, &assets); err != nil {
 		return nil, err
 	}
 	var workers DownloadWorker
 	for _, asset := range assets.Objects {
 		path := filepath.Join(dataDir, "assets", "objects", asset.Hash[:2], asset.Hash)
-		if _, err := os.Stat(path); os.IsNotExist(err) {
-			workers.addTask(func() error {
+		if !sizeMatches(path, int64(asset.Size)) {
+			workers.addTask(int64(asset.Size), func(ctx context.Context, progress io.Writer) error {
 				start := time.Now()
 				slog.Info("Downloading asset", "path", path, "hash", asset.Hash)
-				if err := assetDownloadWorker(asset, path); err != nil {
+				if err := assetDownloadWorker(ctx, asset, path, progress); err != nil {
 					return err
 				}
 				slog.Info("Downloaded asset", "path", path, "duration", time.Since(start))
@@ -280,54 +203,94 @@ func DownloadAssets(clientManifest *ClientManifest, dataDir string) (*DownloadWo
 			})
 		}
 	}
-	// Save the asset index to disk
-	workers.addTask(func() error {
-		assetIndexPath := filepath.Join(dataDir, "assets", "indexes", clientManifest.AssetIndex.ID+".json")
-		_ = os.MkdirAll(filepath.Dir(assetIndexPath), os.ModePerm)
-		assetIndexFile, err := os.Create(assetIndexPath)
-		if err != nil {
-			return err
-		}
-		defer assetIndexFile.Close()
-		encoder := json.NewEncoder(assetIndexFile)
-		if err := encoder.Encode(assets); err != nil {
-			return err
-		}
-		slog.Info("Saved asset index", "path", assetIndexPath)
-		return nil
-	})
+	// Save the asset index to disk as fetched, so its hash can be verified later
+	if fetched {
+		workers.addTask(0, func(ctx context.Context, progress io.Writer) error {
+			_ = os.MkdirAll(filepath.Dir(assetIndexPath), os.ModePerm)
+			if err := os.WriteFile(assetIndexPath, data, 0644); err != nil {
+				return err
+			}
+			slog.Info("Saved asset index", "path", assetIndexPath)
+			return nil
+		})
+	}
 	// Save logging configuration to disk
-	workers.addTask(func() error {
-		if clientManifest.Logging.Client.File.URL == "" {
+	loggingFile := clientManifest.Logging.Client.File
+	loggingPath := filepath.Join(dataDir, "assets", "log_configs", loggingFile.ID)
+	if loggingFile.URL != "" && !fileSHA1Matches(loggingPath, loggingFile.Sha1) {
+		workers.addTask(int64(loggingFile.Size), func(ctx context.Context, progress io.Writer) error {
+			resp, err := MirrorGetContext(ctx, loggingFile.URL)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return errors.New("failed to download logging configuration")
+			}
+			_ = os.MkdirAll(filepath.Dir(loggingPath), os.ModePerm)
+			f, err := os.Create(loggingPath)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			_, err = io.Copy(io.MultiWriter(f, progress), resp.Body)
+			if err != nil {
+				return err
+			}
+			slog.Info("Saved logging configuration", "path", loggingPath)
 			return nil
+		})
+	}
+	return &workers, nil
+}
+
+// prepareGameAssets copies the assets of legacy indexes from the object store to the layout the game
+// expects: assets/virtual/<index> for virtual indexes, or the resources directory of the game directory
+// for map_to_resources. It returns the directory passed as game_assets.
+func prepareGameAssets(dataDir string, manifest *ClientManifest, gameDir string) (string, error) {
+	assetsRoot := filepath.Join(dataDir, "assets")
+	data, err := os.ReadFile(filepath.Join(assetsRoot, "indexes", manifest.AssetIndex.ID+".json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return assetsRoot, nil
 		}
-		resp, err := http.Get(clientManifest.Logging.Client.File.URL)
-		if err != nil {
-			return err
-		}
-		defer resp.Body.Close()
-		if resp.StatusCode != http.StatusOK {
-			return errors.New("failed to download logging configuration")
+		return "", fmt.Errorf("failed to read asset index: %w", err)
+	}
+	var assets Assets
+	if err := json.Unmarshal(data, &assets); err != nil {
+		return "", fmt.Errorf("failed to parse asset index: %w", err)
+	}
+
+	var target string
+	switch {
+	case assets.MapToResources:
+		target = filepath.Join(gameDir, "resources")
+	case assets.Virtual:
+		target = filepath.Join(assetsRoot, "virtual", manifest.AssetIndex.ID)
+	default:
+		return assetsRoot, nil
+	}
+	for name, asset := range assets.Objects {
+		if len(asset.Hash) < 2 {
+			continue
 		}
-		loggingPath := filepath.Join(dataDir, "assets", "log_configs", clientManifest.Logging.Client.File.ID)
-		_ = os.MkdirAll(filepath.Dir(loggingPath), os.ModePerm)
-		loggingFile, err := os.Create(loggingPath)
+		dest, err := SafeJoin(target, name)
 		if err != nil {
-			return err
+			return "", err
 		}
-		defer loggingFile.Close()
-		_, err = io.Copy(loggingFile, resp.Body)
-		if err != nil {
-			return err
+		if info, err := os.Stat(dest); err == nil && info.Size() == int64(asset.Size) {
+			continue
 		}
-		slog.Info("Saved logging configuration", "path", loggingPath)
-		return nil
-	})
-	return &workers, nil
+		src := filepath.Join(assetsRoot, "objects", asset.Hash[:2], asset.Hash)
+		if err := copyFileSync(src, dest); err != nil {
+			return "", fmt.Errorf("failed to copy asset %s: %w", name, err)
+		}
+	}
+	return target, nil
 }
 
-func assetDownloadWorker(asset Asset, path string) error {
-	resp, err := http.Get(MojangAssetResourceURL + asset.Hash[:2] + "/" + asset.Hash)
+func assetDownloadWorker(ctx context.Context, asset Asset, path string, progress io.Writer) error {
+	resp, err := MirrorGetContext(ctx, MojangAssetResourceURL+asset.Hash[:2]+"/"+asset.Hash)
 	if err != nil {
 		return err
 	}
@@ -336,13 +299,7 @@ func assetDownloadWorker(asset Asset, path string) error {
 		return errors.New("failed to download asset")
 	}
 	// Save the asset to disk
-	_ = os.MkdirAll(filepath.Dir(path), os.ModePerm)
-	file, err := os.Create(path)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-	written, err := io.Copy(file, resp.Body)
+	written, err := writeVerified(resp.Body, path, asset.Hash, progress)
 	if err != nil {
 		return err
 	}
@@ -388,9 +345,9 @@ func isMatchArch(arch string) bool {
 	case "x86":
 		return osArch == "x86_64" || osArch == "x86"
 	case "aarch64":
-		return osArch == "arm64"
+		return osArch == "aarch64"
 	case "armv7l":
-		return osArch == "arm" || osArch == "arm64"
+		return osArch == "armv7l" || osArch == "aarch64"
 	default:
 		return false
 	}
@@ -402,56 +359,40 @@ func DownloadLibraries(clientManifest *ClientManifest, dataDir string) (*Downloa
 	}
 	var workers DownloadWorker
 	for _, library := range clientManifest.Libraries {
-		if slices.ContainsFunc(library.Rules, func(rule LibraryRule) bool {
-			return rule.Action.Allowed() != (rule.Os.Name == osName() && (rule.Os.Arch == "" || isMatchArch(rule.Os.Arch)) && (rule.Os.Version == "" || rule.Os.Version == osinfo.GetOsVersion()))
-		}) {
+		if !library.Allowed() {
 			slog.Info("Skipping library", "name", library.Name, "rules", library.Rules, "library", library)
 			continue
 		}
-		path := filepath.Join(dataDir, "libraries", library.Downloads.Artifact.Path)
-		if _, err := os.Stat(path); os.IsNotExist(err) {
-			workers.addTask(func() error {
-				start := time.Now()
-				slog.Info("Downloading library", "path", path, "name", library.Name)
-				if err := libraryDownloadWorker(library, dataDir); err != nil {
-					return err
-				}
-				slog.Info("Downloaded library", "path", path, "duration", time.Since(start))
-				return nil
-			})
+		var artifacts []LibraryArtifact
+		if library.Downloads.Artifact.Path != "" {
+			artifacts = append(artifacts, library.Downloads.Artifact)
+		}
+		if native, ok := library.NativeArtifact(); ok {
+			artifacts = append(artifacts, native)
+		}
+		for _, artifact := range artifacts {
+			path := filepath.Join(dataDir, "libraries", filepath.FromSlash(artifact.Path))
+			if !sizeMatches(path, int64(artifact.Size)) {
+				workers.addTask(int64(artifact.Size), func(ctx context.Context, progress io.Writer) error {
+					start := time.Now()
+					slog.Info("Downloading library", "path", path, "name", library.Name)
+					if err := libraryDownloadWorker(ctx, artifact, path, progress); err != nil {
+						return fmt.Errorf("failed to download library %s: %w", library.Name, err)
+					}
+					slog.Info("Downloaded library", "path", path, "duration", time.Since(start))
+					return nil
+				})
+			}
 		}
 	}
 	return &workers, nil
 }
 
-func libraryDownloadWorker(library Library, dataDir string) error {
-	if library.Downloads.Artifact.URL == "" {
+func libraryDownloadWorker(ctx context.Context, artifact LibraryArtifact, path string, progress io.Writer) error {
+	if artifact.URL == "" {
 		return errors.New("library artifact URL is empty")
 	}
-	if classifiers, ok := library.Downloads.Classifiers[osName()]; ok {
-		resp, err := http.Get(classifiers.URL)
-		if err != nil {
-			return err
-		}
-		defer resp.Body.Close()
-		if resp.StatusCode != http.StatusOK {
-			return errors.New("failed to download library classifier")
-		}
-		// Save the classifier to disk
-		path := filepath.Join(dataDir, "libraries", classifiers.Path)
-		_ = os.MkdirAll(filepath.Dir(path), os.ModePerm)
-		_ = os.MkdirAll(filepath.Dir(path), os.ModePerm)
-		file, err := os.Create(path)
-		if err != nil {
-			return err
-		}
-		defer file.Close()
-		_, err = io.Copy(file, resp.Body)
-		if err != nil {
-			return err
-		}
-	}
-	resp, err := http.Get(library.Downloads.Artifact.URL)
+	resp, err := MirrorGetContext(ctx, artifact.URL)
 	if err != nil {
 		return err
 	}
@@ -460,18 +401,8 @@ func libraryDownloadWorker(library Library, dataDir string) error {
 		return errors.New("failed to download library")
 	}
 	// Save the library to disk
-	path := filepath.Join(dataDir, "libraries", library.Downloads.Artifact.Path)
-	_ = os.MkdirAll(filepath.Dir(path), os.ModePerm)
-	file, err := os.Create(path)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-	_, err = io.Copy(file, resp.Body)
-	if err != nil {
-		return err
-	}
-	return nil
+	_, err = writeVerified(resp.Body, path, artifact.Sha1, progress)
+	return err
 }
 
 func DownloadClientJar(clientManifest *ClientManifest, dataDir string) (*DownloadWorker, error) {
@@ -480,11 +411,11 @@ func DownloadClientJar(clientManifest *ClientManifest, dataDir string) (*Downloa
 	}
 	var workers DownloadWorker
 	path := filepath.Join(dataDir, "versions", clientManifest.ID, clientManifest.ID+".jar")
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		workers.addTask(func() error {
+	if !sizeMatches(path, int64(clientManifest.Downloads.Client.Size)) {
+		workers.addTask(int64(clientManifest.Downloads.Client.Size), func(ctx context.Context, progress io.Writer) error {
 			start := time.Now()
 			slog.Info("Downloading client jar", "path", path)
-			if err := clientJarDownloadWorker(clientManifest, dataDir); err != nil {
+			if err := clientJarDownloadWorker(ctx, clientManifest, dataDir, progress); err != nil {
 				return err
 			}
 			slog.Info("Downloaded client jar", "path", path, "duration", time.Since(start))
@@ -494,11 +425,11 @@ func DownloadClientJar(clientManifest *ClientManifest, dataDir string) (*Downloa
 	return &workers, nil
 }
 
-func clientJarDownloadWorker(clientManifest *ClientManifest, dataDir string) error {
+func clientJarDownloadWorker(ctx context.Context, clientManifest *ClientManifest, dataDir string, progress io.Writer) error {
 	if clientManifest.Downloads.Client.URL == "" {
 		return errors.New("client jar URL is empty")
 	}
-	resp, err := http.Get(clientManifest.Downloads.Client.URL)
+	resp, err := MirrorGetContext(ctx, clientManifest.Downloads.Client.URL)
 	if err != nil {
 		return err
 	}
@@ -508,14 +439,7 @@ func clientJarDownloadWorker(clientManifest *ClientManifest, dataDir string) err
 	}
 	// Save the client jar to disk
 	path := filepath.Join(dataDir, "versions", clientManifest.ID, clientManifest.ID+".jar")
-	_ = os.MkdirAll(filepath.Dir(path), os.ModePerm)
-	file, err := os.Create(path)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-	_, err = io.Copy(file, resp.Body)
-	if err != nil {
+	if _, err := writeVerified(resp.Body, path, clientManifest.Downloads.Client.Sha1, progress); err != nil {
 		return err
 	}
 
@@ -541,7 +465,7 @@ func DownloadJVM(clientManifest *ClientManifest, dataDir string) (*DownloadWorke
 	}
 	slog.Info("Downloading JVM", "version", clientManifest.JavaVersion.Component)
 	var workers DownloadWorker
-	if err := installJavaRuntime(clientManifest.JavaVersion.Component, "/", &workers); err != nil {
+	if err := installJavaRuntime(clientManifest.JavaVersion.Component, dataDir, &workers); err != nil {
 		return nil, fmt.Errorf("failed to install java runtime: %w", err)
 	}
 	return &workers, nil
@@ -559,11 +483,35 @@ var (
 )
 
 // BootGameFromConfig launches the game using the provided LaunchConfig.
-func BootGameFromConfig(ctx context.Context, javaPath string, config *LaunchConfig, clientManifest *ClientManifest, inst *Instance, mcProfile *msa.MinecraftProfile, accessToken string, stdout, stderr io.Writer) error {
+// Offline, accessToken may be empty; the game then starts as a legacy user that cannot join online-mode servers.
+func BootGameFromConfig(ctx context.Context, javaPath string, config *LaunchConfig, clientManifest *ClientManifest, inst *Instance, mcProfile *msa.MinecraftProfile, accessToken string, offline bool, stdout, stderr io.Writer) error {
 	slog.Info("Booting game from config", "mainClass", config.MainClass)
 
+	userType, session := "msa", fmt.Sprintf("token:%s:%s", accessToken, strings.ReplaceAll(mcProfile.UUID.String(), "-", ""))
+	if accessToken == "" {
+		if !offline {
+			return fmt.Errorf("no access token to launch the game with")
+		}
+		// The game requires the arguments to be present, but nothing validates them without a session
+		accessToken, userType, session = "-", "legacy", "-"
+	}
+
 	classpathSeparator := string(os.PathListSeparator)
 	joinedClasspath := strings.Join(config.Classpath, classpathSeparator)
+	settings := inst.LaunchSettings
+	if err := settings.Validate(); err != nil {
+		return fmt.Errorf("invalid launch settings: %w", err)
+	}
+	resolutionWidth, resolutionHeight := settings.resolution()
+
+	nativesDir, err := ExtractNatives(DataDir, clientManifest)
+	if err != nil {
+		return fmt.Errorf("failed to extract natives: %w", err)
+	}
+	gameAssets, err := prepareGameAssets(DataDir, clientManifest, inst.Path)
+	if err != nil {
+		return fmt.Errorf("failed to prepare assets: %w", err)
+	}
 
 	var placeholders = map[string]string{
 		"auth_player_name":      mcProfile.Username,
@@ -571,19 +519,22 @@ func BootGameFromConfig(ctx context.Context, javaPath string, config *LaunchConf
 		"game_directory":        inst.Path,
 		"assets_root":           filepath.Join(DataDir, "assets"),
 		"assets_index_name":     clientManifest.AssetIndex.ID,
+		"game_assets":           gameAssets,
 		"auth_uuid":             mcProfile.UUID.String(),
 		"auth_access_token":     accessToken,
+		"auth_session":          session,
+		"user_properties":       "{}",
 		"clientid":              buildinfo.LauncherName,
 		"auth_xuid":             mcProfile.UUID.String(),
-		"user_type":             "msa",
+		"user_type":             userType,
 		"version_type":          clientManifest.Type,
-		"resolution_width":      DefaultResolutionWidth,
-		"resolution_height":     DefaultResolutionHeight,
+		"resolution_width":      resolutionWidth,
+		"resolution_height":     resolutionHeight,
 		"quickPlayPath":         "",
 		"quickPlayMultiplayer":  "", // TODO: Address ServerAddress later
 		"quickPlayRealms":       "",
 		"quickPlaySingleplayer": "",
-		"natives_directory":     filepath.Join(DataDir, "bin", clientManifest.ID),
+		"natives_directory":     nativesDir,
 		"launcher_name":         buildinfo.LauncherName,
 		"launcher_version":      buildinfo.LauncherVersion,
 		"classpath":             joinedClasspath,
@@ -591,6 +542,10 @@ func BootGameFromConfig(ctx context.Context, javaPath string, config *LaunchConf
 		"classpath_separator":   classpathSeparator,
 	}
 
+	// The token ends up in the command line, which is logged, and some mod loaders echo it to the game output.
+	redact.Register(placeholders["auth_access_token"])
+	redact.Register(placeholders["auth_xuid"])
+
 	resolveArgs := func(args []string) []string {
 		var resolved []string
 		for _, arg := range args {
@@ -605,6 +560,12 @@ func BootGameFromConfig(ctx context.Context, javaPath string, config *LaunchConf
 
 	resolvedJvmArgs := resolveArgs(config.JVMArguments)
 	resolvedGameArgs := resolveArgs(config.GameArguments)
+	if settings != nil {
+		resolvedJvmArgs = append(resolvedJvmArgs, settings.JVMArgs...)
+		if settings.Fullscreen && !slices.Contains(resolvedGameArgs, "--fullscreen") {
+			resolvedGameArgs = append(resolvedGameArgs, "--fullscreen")
+		}
+	}
 
 	var cmds []string
 	cmds = append(cmds, javaPath)
@@ -626,17 +587,36 @@ func BootGameFromConfig(ctx context.Context, javaPath string, config *LaunchConf
 	cmds = append(cmds, resolvedGameArgs...)
 	slog.Info("Game command", "cmd", cmds)
 	_ = os.MkdirAll(inst.Path, os.ModePerm)
+	env := settings.environ()
 
+	if settings != nil && settings.PreLaunchCommand != "" {
+		if err := runHookCommand(ctx, settings.PreLaunchCommand, inst.Path, env); err != nil {
+			return fmt.Errorf("failed to run pre-launch command: %w", err)
+		}
+	}
+
+	stdoutTee := redact.NewWriter(io.MultiWriter(stdout, slog.NewLogLogger(slog.Default().Handler(), slog.LevelInfo).Writer()))
+	stderrTee := redact.NewWriter(io.MultiWriter(stderr, slog.NewLogLogger(slog.Default().Handler(), slog.LevelInfo).Writer()))
 	cmd := exec.CommandContext(ctx, cmds[0], cmds[1:]...)
-	cmd.Stdout = io.MultiWriter(stdout, slog.NewLogLogger(slog.Default().Handler(), slog.LevelInfo).Writer())
-	cmd.Stderr = io.MultiWriter(stderr, slog.NewLogLogger(slog.Default().Handler(), slog.LevelInfo).Writer())
+	cmd.Stdout = stdoutTee
+	cmd.Stderr = stderrTee
 	cmd.SysProcAttr = runcmd.GetSysProcAttr()
 	cmd.Dir = inst.Path
+	cmd.Env = env
 
-	if err := cmd.Run(); err != nil {
-		slog.Error("Failed to run game command", "error", err)
-		return err
+	runErr := cmd.Run()
+	_ = stdoutTee.Flush()
+	_ = stderrTee.Flush()
+	if runErr != nil {
+		slog.Error("Failed to run game command", "error", runErr)
 	}
 
-	return nil
+	if settings != nil && settings.PostExitCommand != "" {
+		// The game has already exited, so the command runs even if the launch was cancelled.
+		if err := runHookCommand(context.WithoutCancel(ctx), settings.PostExitCommand, inst.Path, env); err != nil {
+			slog.Error("Failed to run post-exit command", "error", err)
+		}
+	}
+
+	return runErr
 }
@@ -0,0 +1,136 @@
+package fyne
+
+import (
+	"context"
+	"image/color"
+	"log/slog"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ikafly144/sabalauncher/v2/pkg/core"
+	"github.com/ikafly144/sabalauncher/v2/pkg/i18n"
+	"github.com/ikafly144/sabalauncher/v2/pkg/msa"
+	"github.com/ikafly144/sabalauncher/v2/pkg/resource"
+)
+
+// showAddAccountView shows the login options to sign in with another account.
+func (ui *FyneUI) showAddAccountView() {
+	cancelBtn := widget.NewButton(i18n.T("cancel"), func() {
+		ui.showMainView()
+	})
+
+	rect := canvas.NewRectangle(color.Transparent)
+	rect.SetMinSize(fyne.NewSize(300, 0))
+	ui.window.SetContent(container.NewBorder(
+		createHeader(),
+		nil, nil, nil,
+		container.NewCenter(container.NewPadded(container.NewVBox(
+			ui.createLoggedOutView(),
+			cancelBtn,
+		))),
+	))
+}
+
+// logout signs out of the active account and shows the main view if another account took over.
+func (ui *FyneUI) logout() {
+	go func() {
+		if err := ui.auth.Logout(); err != nil {
+			slog.Error("Failed to logout", "error", err)
+		}
+		fyne.Do(func() {
+			if ui.auth.GetStatus() == core.AuthStatusLoggedIn {
+				ui.showMainView()
+			} else {
+				ui.showAuthView()
+			}
+		})
+	}()
+}
+
+// makeAccountSwitcher returns the controls to switch between, add and remove accounts.
+func (ui *FyneUI) makeAccountSwitcher() fyne.CanvasObject {
+	accounts := ui.auth.Accounts()
+	activeID := ui.auth.ActiveAccountID()
+
+	names := make([]string, len(accounts))
+	selected := ""
+	for i, a := range accounts {
+		names[i] = a.Username
+		if a.ID == activeID {
+			selected = a.Username
+		}
+	}
+
+	accountSelect := widget.NewSelect(names, nil)
+	accountSelect.SetSelected(selected)
+	accountSelect.OnChanged = func(name string) {
+		i := accountIndex(accounts, name)
+		if i < 0 || accounts[i].ID == ui.auth.ActiveAccountID() {
+			return
+		}
+		id := accounts[i].ID
+		go func() {
+			if err := ui.auth.SwitchAccount(context.Background(), id); err != nil {
+				slog.Error("Failed to switch account", "error", err)
+				fyne.Do(func() {
+					dialog.ShowError(err, ui.window)
+					ui.showMainView()
+				})
+				return
+			}
+			fyne.Do(func() {
+				ui.showMainView()
+			})
+		}()
+	}
+
+	addBtn := widget.NewButton(i18n.T("add_account_btn"), func() {
+		ui.showAddAccountView()
+	})
+
+	return container.NewBorder(nil, nil, widget.NewLabel(i18n.T("switch_account_label")), addBtn, accountSelect)
+}
+
+// showInstanceAccountDialog lets the user pick the account used to launch an instance.
+func (ui *FyneUI) showInstanceAccountDialog(inst *resource.Instance) {
+	accounts := ui.auth.Accounts()
+	activeLabel := i18n.T("instance_account_active")
+	options := []string{activeLabel}
+	selected := activeLabel
+	for _, a := range accounts {
+		options = append(options, a.Username)
+		if a.ID == inst.Account {
+			selected = a.Username
+		}
+	}
+
+	accountSelect := widget.NewSelect(options, nil)
+	accountSelect.SetSelected(selected)
+
+	dialog.ShowForm(i18n.T("instance_account_title"), i18n.T("save"), i18n.T("cancel"), []*widget.FormItem{
+		widget.NewFormItem(i18n.T("instance_account_label"), accountSelect),
+	}, func(ok bool) {
+		if !ok {
+			return
+		}
+		inst.Account = ""
+		if i := accountIndex(accounts, accountSelect.Selected); i >= 0 {
+			inst.Account = accounts[i].ID
+		}
+		if err := ui.instances.SaveInstance(inst); err != nil {
+			dialog.ShowError(err, ui.window)
+		}
+	}, ui.window)
+}
+
+func accountIndex(accounts []msa.StoredAccount, username string) int {
+	for i, a := range accounts {
+		if a.Username == username {
+			return i
+		}
+	}
+	return -1
+}
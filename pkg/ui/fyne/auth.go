@@ -127,8 +127,7 @@ func (ui *FyneUI) createLoggedInView() fyne.CanvasObject {
 	dashboardBtn.Importance = widget.HighImportance
 
 	logoutBtn := widget.NewButton(i18n.T("logout"), func() {
-		_ = ui.auth.Logout()
-		ui.showAuthView()
+		ui.logout()
 	})
 
 	content := container.NewVBox(
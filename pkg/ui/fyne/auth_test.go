@@ -58,6 +58,31 @@ func (m *mockInstanceManager) UpdateInstance(ctx context.Context, instanceID uui
 	return args.Error(0)
 }
 
+func (m *mockInstanceManager) UpdateInstanceTo(ctx context.Context, instanceID uuid.UUID, version string) error {
+	args := m.Called(ctx, instanceID, version)
+	return args.Error(0)
+}
+
+func (m *mockInstanceManager) ResolveUpdate(ctx context.Context, instanceID uuid.UUID, version string) ([]resource.SBRepoPatch, error) {
+	args := m.Called(ctx, instanceID, version)
+	return args.Get(0).([]resource.SBRepoPatch), args.Error(1)
+}
+
+func (m *mockInstanceManager) ListVersions(ctx context.Context, instanceID uuid.UUID) ([]resource.RemoteVersion, error) {
+	args := m.Called(ctx, instanceID)
+	return args.Get(0).([]resource.RemoteVersion), args.Error(1)
+}
+
+func (m *mockInstanceManager) SetPinned(instanceID uuid.UUID, pinned bool) error {
+	args := m.Called(instanceID, pinned)
+	return args.Error(0)
+}
+
+func (m *mockInstanceManager) SetChannel(instanceID uuid.UUID, channel string) error {
+	args := m.Called(instanceID, channel)
+	return args.Error(0)
+}
+
 func (m *mockInstanceManager) CheckUpdate(ctx context.Context, instanceID uuid.UUID) (bool, error) {
 	args := m.Called(ctx, instanceID)
 	return args.Bool(0), args.Error(1)
@@ -68,6 +93,14 @@ func (m *mockInstanceManager) RepairInstance(ctx context.Context, instanceID uui
 	return args.Error(0)
 }
 
+func (m *mockInstanceManager) VerifyGameFiles(ctx context.Context) (*resource.VerifyReport, error) {
+	args := m.Called(ctx)
+	if r, ok := args.Get(0).(*resource.VerifyReport); ok {
+		return r, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (m *mockInstanceManager) SaveInstance(inst *resource.Instance) error {
 	args := m.Called(inst)
 	return args.Error(0)
@@ -78,6 +111,10 @@ func (m *mockInstanceManager) SubscribeProgress() <-chan core.ProgressEvent {
 	return args.Get(0).(chan core.ProgressEvent)
 }
 
+func (m *mockInstanceManager) Wait() {
+	m.Called()
+}
+
 type mockAuthenticator struct {
 	mock.Mock
 }
@@ -137,6 +174,36 @@ func (m *mockAuthenticator) GetMinecraftAccount() (*msa.MinecraftAccount, error)
 	return args.Get(0).(*msa.MinecraftAccount), args.Error(1)
 }
 
+func (m *mockAuthenticator) GetMinecraftAccountFor(accountID string) (*msa.MinecraftAccount, error) {
+	args := m.Called(accountID)
+	return args.Get(0).(*msa.MinecraftAccount), args.Error(1)
+}
+
+func (m *mockAuthenticator) LaunchCredentials(accountID string, offline bool) (*core.LaunchCredentials, error) {
+	args := m.Called(accountID, offline)
+	return args.Get(0).(*core.LaunchCredentials), args.Error(1)
+}
+
+func (m *mockAuthenticator) Accounts() []msa.StoredAccount {
+	args := m.Called()
+	return args.Get(0).([]msa.StoredAccount)
+}
+
+func (m *mockAuthenticator) ActiveAccountID() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+func (m *mockAuthenticator) SwitchAccount(ctx context.Context, accountID string) error {
+	args := m.Called(ctx, accountID)
+	return args.Error(0)
+}
+
+func (m *mockAuthenticator) RemoveAccount(accountID string) error {
+	args := m.Called(accountID)
+	return args.Error(0)
+}
+
 type mockDiscordManager struct {
 	mock.Mock
 }
@@ -191,6 +258,8 @@ func TestShowAuthView_LoggedIn_Buttons(t *testing.T) {
 	m.On("GetStatus").Return(core.AuthStatusLoggedIn)
 	m.On("GetUserDisplay").Return("TestUser")
 	m.On("GetMinecraftProfile").Return(&msa.MinecraftProfile{Username: "TestUser", UUID: uuid.New()}, nil).Maybe()
+	m.On("Accounts").Return([]msa.StoredAccount{}).Maybe()
+	m.On("ActiveAccountID").Return("").Maybe()
 	m.On("Logout").Return(nil)
 
 	ui := &FyneUI{
@@ -307,6 +376,8 @@ func TestStartLogin(t *testing.T) {
 	m.On("WaitLogin", mock.Anything).Return(nil)
 	m.On("GetUserDisplay").Return("TestUser").Maybe()
 	m.On("GetMinecraftProfile").Return(&msa.MinecraftProfile{Username: "TestUser", UUID: uuid.New()}, nil).Maybe()
+	m.On("Accounts").Return([]msa.StoredAccount{}).Maybe()
+	m.On("ActiveAccountID").Return("").Maybe()
 
 	mp := new(mockInstanceManager)
 	mp.On("GetInstances").Return([]*resource.Instance{}, nil)
@@ -140,6 +140,12 @@ func (ui *FyneUI) makeDashboardView() fyne.CanvasObject {
 		if currentInstance.Upstream != nil && currentInstance.Upstream.Version != "" {
 			patchVer := currentInstance.Upstream.Version
 			versionStr = patchVer
+			if channel := currentInstance.Upstream.ChannelName(); channel != resource.ChannelStable {
+				versionStr = fmt.Sprintf("%s [%s]", versionStr, i18n.T("channel_"+channel))
+			}
+			if currentInstance.Upstream.Pinned {
+				versionStr = i18n.T("version_pinned", versionStr)
+			}
 		}
 		var sb strings.Builder
 		sb.WriteString("(")
@@ -190,9 +196,11 @@ func (ui *FyneUI) makeDashboardView() fyne.CanvasObject {
 			doLaunch := func() {
 				ctx, closeOverlay := ui.showLaunchOverlay()
 				go func() {
-					if isRemote {
-						// Force update before launch
-						if err := ui.instances.UpdateInstance(ctx, currentInstance.UID, ""); err != nil {
+					if isRemote && !opts.Offline {
+						// Force update before launch; without a connection, play the installed version
+						if err := ui.instances.UpdateInstance(ctx, currentInstance.UID, ""); resource.IsNetworkError(err) {
+							slog.Warn("Failed to check for updates before play, launching installed version", "error", err)
+						} else if err != nil {
 							fyne.Do(func() {
 								closeOverlay()
 								if !errors.Is(err, context.Canceled) {
@@ -231,7 +239,9 @@ func (ui *FyneUI) makeDashboardView() fyne.CanvasObject {
 		var playBtn fyne.CanvasObject
 		if isRemote && updateAvailable {
 			btn := widget.NewButton(i18n.T("update_btn"), func() {
-				ui.startUpdate(currentInstance.UID, "")
+				ui.confirmRemoteUpdate(currentInstance.UID, "", func() {
+					ui.startUpdate(currentInstance.UID, "")
+				})
 			})
 			btn.Importance = widget.HighImportance
 			playBtn = btn
@@ -291,11 +301,24 @@ func (ui *FyneUI) makeDashboardView() fyne.CanvasObject {
 
 		// Create Actions button with popup menu
 		menu := fyne.NewMenu("",
+			fyne.NewMenuItem(i18n.T("play_offline"), func() {
+				launchFunc(&core.LaunchOptions{Offline: true})
+			}),
 			fyne.NewMenuItem(i18n.T("repair_btn"), repairBtn.OnTapped),
+			fyne.NewMenuItem(i18n.T("instance_account_title"), func() {
+				ui.showInstanceAccountDialog(currentInstance)
+			}),
+			fyne.NewMenuItem(i18n.T("instance_settings_title"), func() {
+				ui.showInstanceSettingsDialog(currentInstance)
+			}),
 			fyne.NewMenuItem(i18n.T("delete_instance_btn"), deleteBtn.OnTapped),
 		)
 		if !isRemote {
 			menu.Items = append([]*fyne.MenuItem{fyne.NewMenuItem(i18n.T("update_btn"), updateBtn.OnTapped)}, menu.Items...)
+		} else {
+			menu.Items = append([]*fyne.MenuItem{fyne.NewMenuItem(i18n.T("select_version_btn"), func() {
+				ui.showSelectVersionDialog(currentInstance)
+			})}, menu.Items...)
 		}
 
 		actionsBtn := widget.NewButtonWithIcon("", theme.MenuIcon(), nil)
@@ -350,8 +373,7 @@ func (ui *FyneUI) makeSettingsView() fyne.CanvasObject {
 	}
 
 	logoutBtn := widget.NewButton(i18n.T("logout"), func() {
-		_ = ui.auth.Logout()
-		ui.showAuthView()
+		ui.logout()
 	})
 	logoutBtn.Importance = widget.DangerImportance
 
@@ -366,9 +388,14 @@ func (ui *FyneUI) makeSettingsView() fyne.CanvasObject {
 		}
 	}
 
+	verifyBtn := widget.NewButtonWithIcon(i18n.T("verify_game_files_btn"), theme.ViewRefreshIcon(), func() {
+		ui.showVerifyGameFilesDialog()
+	})
+
 	launcherSettings := container.NewVBox(
 		widget.NewLabel(i18n.T("max_memory_label")),
 		memoryEntry,
+		container.NewHBox(verifyBtn),
 	)
 
 	return container.NewVBox(
@@ -376,6 +403,7 @@ func (ui *FyneUI) makeSettingsView() fyne.CanvasObject {
 		widget.NewSeparator(),
 		widget.NewLabelWithStyle(i18n.T("account_section_title"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
 		container.NewPadded(accountInfo),
+		container.NewPadded(ui.makeAccountSwitcher()),
 		widget.NewSeparator(),
 		widget.NewLabelWithStyle(i18n.T("launcher_section_title"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
 		container.NewPadded(launcherSettings),
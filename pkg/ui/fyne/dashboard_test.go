@@ -65,6 +65,8 @@ func TestShowDashboardView(t *testing.T) {
 	ma.On("GetStatus").Return(core.AuthStatusLoggedIn)
 	ma.On("GetUserDisplay").Return("TestUser")
 	ma.On("GetMinecraftProfile").Return(&msa.MinecraftProfile{Username: "TestUser", UUID: uuid.New()}, nil).Maybe()
+	ma.On("Accounts").Return([]msa.StoredAccount{}).Maybe()
+	ma.On("ActiveAccountID").Return("").Maybe()
 
 	ui := NewFyneUI(a, ma, mp, mr, new(mockDiscordManager), core.DefaultConfig(), "0.1.0")
 
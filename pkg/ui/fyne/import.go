@@ -3,7 +3,12 @@ package fyne
 import (
 	"context"
 	"errors"
+	"fmt"
 	"image/color"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
@@ -13,12 +18,13 @@ import (
 	"github.com/google/uuid"
 	"github.com/ikafly144/sabalauncher/v2/pkg/browser"
 	"github.com/ikafly144/sabalauncher/v2/pkg/i18n"
+	"github.com/ikafly144/sabalauncher/v2/pkg/resource"
 )
 
 func (ui *FyneUI) showImportModpackDialog() {
 	// Attempt to get HWND. On Windows, Fyne uses GLFW.
 	// We pass 0 and let the browser package handle it if needed.
-	path, err := browser.SelectFile(0, "SBPack files (*.sbpack)|*.sbpack")
+	path, err := browser.SelectFile(0, "Modpack files (*.sbpack, *.mrpack, *.zip)|*.sbpack;*.mrpack;*.zip")
 	if err != nil {
 		dialog.ShowError(err, ui.window)
 		return
@@ -59,7 +65,13 @@ func (ui *FyneUI) showImportModpackDialog() {
 		err := ui.instances.ImportInstance(ctx, path)
 		done <- true
 		fyne.Do(progress.Hide)
-		if err != nil {
+		var manualErr *resource.ManualDownloadError
+		if errors.As(err, &manualErr) {
+			fyne.Do(func() {
+				ui.showMainView()
+				ui.showManualDownloadDialog(manualErr.Files)
+			})
+		} else if err != nil {
 			fyne.Do(func() {
 				if !errors.Is(err, context.Canceled) {
 					dialog.ShowError(err, ui.window)
@@ -73,6 +85,27 @@ func (ui *FyneUI) showImportModpackDialog() {
 	}()
 }
 
+// showManualDownloadDialog lists files that the user has to download from the CurseForge website.
+func (ui *FyneUI) showManualDownloadDialog(files []resource.ManualDownload) {
+	list := container.NewVBox()
+	for _, f := range files {
+		name := f.FileName
+		if link, err := url.Parse(f.URL); err == nil {
+			list.Add(widget.NewHyperlink(name, link))
+		} else {
+			list.Add(widget.NewLabel(name))
+		}
+		list.Add(widget.NewLabel("  → " + f.Path))
+	}
+
+	body := widget.NewLabel(i18n.T("manual_download_body", len(files)))
+	body.Wrapping = fyne.TextWrapWord
+	scroll := container.NewVScroll(list)
+	scroll.SetMinSize(fyne.NewSize(500, 250))
+
+	dialog.ShowCustom(i18n.T("manual_download_title"), i18n.T("close"), container.NewBorder(body, nil, nil, nil, scroll), ui.window)
+}
+
 func (ui *FyneUI) showRegisterRemoteModpackDialog() {
 	entry := widget.NewEntry()
 	entry.SetPlaceHolder("https://repository.example/repo/manifest.json")
@@ -180,6 +213,56 @@ func (ui *FyneUI) showRepairInstanceDialog(instanceID uuid.UUID) {
 	}()
 }
 
+func (ui *FyneUI) showVerifyGameFilesDialog() {
+	minWidth := canvas.NewRectangle(color.Transparent)
+	minWidth.SetMinSize(fyne.NewSize(400, 0))
+	multiProg := NewMultiProgress(i18n.T("verifying_progress"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	progress := dialog.NewCustom(i18n.T("verifying_progress"), i18n.T("cancel"), container.NewStack(minWidth, multiProg), ui.window)
+	progress.SetOnClosed(func() {
+		cancel()
+	})
+	progress.Show()
+
+	go func() {
+		pChan := ui.instances.SubscribeProgress()
+		done := make(chan bool)
+		go func() {
+			for {
+				select {
+				case p := <-pChan:
+					fyne.Do(func() {
+						multiProg.Update(p)
+					})
+				case <-done:
+					return
+				}
+			}
+		}()
+
+		report, err := ui.instances.VerifyGameFiles(ctx)
+		done <- true
+		fyne.Do(progress.Hide)
+		if err != nil {
+			fyne.Do(func() {
+				if !errors.Is(err, context.Canceled) {
+					dialog.ShowError(err, ui.window)
+				}
+			})
+			return
+		}
+		fyne.Do(func() {
+			msg := i18n.T("verify_game_files_result_body", report.Checked, len(report.Repaired), len(report.Unrepairable))
+			if len(report.Unrepairable) > 0 {
+				msg += "\n\n" + i18n.T("verify_game_files_unrepairable", strings.Join(report.Unrepairable, "\n"))
+			}
+			dialog.ShowInformation(i18n.T("verify_game_files_result_title"), msg, ui.window)
+		})
+	}()
+}
+
 func (ui *FyneUI) showUpdateInstanceDialog(instanceID uuid.UUID) {
 	path, err := browser.SelectFile(0, "Update files (*.sbpatch, *.sbpack)|*.sbpatch;*.sbpack")
 	if err != nil {
@@ -190,10 +273,80 @@ func (ui *FyneUI) showUpdateInstanceDialog(instanceID uuid.UUID) {
 		return // Canceled
 	}
 
+	if changelog, err := resource.ReadChangelog(path); err == nil && changelog.Text(i18n.GetLanguage()) != "" {
+		ui.showChangelogConfirm([]changelogNote{{title: filepath.Base(path), changelog: changelog}}, func() {
+			ui.startUpdate(instanceID, path)
+		})
+		return
+	}
 	ui.startUpdate(instanceID, path)
 }
 
+// changelogNote is the release notes of one step of an update.
+type changelogNote struct {
+	title     string
+	changelog resource.Changelog
+}
+
+// confirmRemoteUpdate shows the release notes of every step of the update of a remote instance to version, the
+// latest version of its channel if empty, and calls run once the user confirms.
+func (ui *FyneUI) confirmRemoteUpdate(instanceID uuid.UUID, version string, run func()) {
+	loading := dialog.NewCustomWithoutButtons(i18n.T("update_changelog_title"), widget.NewProgressBarInfinite(), ui.window)
+	loading.Show()
+
+	go func() {
+		steps, err := ui.instances.ResolveUpdate(context.Background(), instanceID, version)
+		fyne.Do(func() {
+			loading.Hide()
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("failed to resolve update: %w", err), ui.window)
+				return
+			}
+			if len(steps) == 0 {
+				run()
+				return
+			}
+			notes := make([]changelogNote, len(steps))
+			for i, step := range steps {
+				notes[i] = changelogNote{title: step.ID, changelog: step.Changelog}
+			}
+			ui.showChangelogConfirm(notes, run)
+		})
+	}()
+}
+
+func (ui *FyneUI) showChangelogConfirm(notes []changelogNote, run func()) {
+	var sb strings.Builder
+	for _, n := range notes {
+		fmt.Fprintf(&sb, "## %s\n\n", n.title)
+		if text := n.changelog.Text(i18n.GetLanguage()); text != "" {
+			sb.WriteString(text)
+		} else {
+			sb.WriteString(i18n.T("no_changelog"))
+		}
+		sb.WriteString("\n\n")
+	}
+	body := widget.NewRichTextFromMarkdown(sb.String())
+	body.Wrapping = fyne.TextWrapWord
+	scroll := container.NewVScroll(body)
+	scroll.SetMinSize(fyne.NewSize(500, 300))
+
+	d := dialog.NewCustomConfirm(i18n.T("update_changelog_title"), i18n.T("update_btn"), i18n.T("cancel"), scroll, func(ok bool) {
+		if ok {
+			run()
+		}
+	}, ui.window)
+	d.Show()
+}
+
 func (ui *FyneUI) startUpdate(instanceID uuid.UUID, path string) {
+	ui.runInstanceUpdate(instanceID, false, func(ctx context.Context) error {
+		return ui.instances.UpdateInstance(ctx, instanceID, path)
+	})
+}
+
+// runInstanceUpdate runs update with a progress dialog. updateAvailable is whether a newer version remains after it.
+func (ui *FyneUI) runInstanceUpdate(instanceID uuid.UUID, updateAvailable bool, update func(ctx context.Context) error) {
 	minWidth := canvas.NewRectangle(color.Transparent)
 	minWidth.SetMinSize(fyne.NewSize(400, 0))
 	multiProg := NewMultiProgress(i18n.T("updating_progress"))
@@ -222,7 +375,7 @@ func (ui *FyneUI) startUpdate(instanceID uuid.UUID, path string) {
 			}
 		}()
 
-		err := ui.instances.UpdateInstance(ctx, instanceID, path)
+		err := update(ctx)
 		done <- true
 		fyne.Do(progress.Hide)
 		if err != nil {
@@ -233,9 +386,141 @@ func (ui *FyneUI) startUpdate(instanceID uuid.UUID, path string) {
 			})
 		} else {
 			fyne.Do(func() {
-				ui.instanceUpdateAvailable[instanceID] = false
+				ui.instanceUpdateAvailable[instanceID] = updateAvailable
 				ui.showMainView()
 			})
 		}
 	}()
 }
+
+func (ui *FyneUI) showSelectVersionDialog(inst *resource.Instance) {
+	loading := dialog.NewCustomWithoutButtons(i18n.T("select_version_title"), widget.NewProgressBarInfinite(), ui.window)
+	loading.Show()
+
+	go func() {
+		versions, err := ui.instances.ListVersions(context.Background(), inst.UID)
+		fyne.Do(func() {
+			loading.Hide()
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("failed to fetch versions: %w", err), ui.window)
+				return
+			}
+			if len(versions) == 0 {
+				dialog.ShowInformation(i18n.T("select_version_title"), i18n.T("no_versions_available"), ui.window)
+				return
+			}
+			ui.showVersionPicker(inst, versions)
+		})
+	}()
+}
+
+func (ui *FyneUI) showVersionPicker(inst *resource.Instance, versions []resource.RemoteVersion) {
+	var byOption map[string]resource.RemoteVersion
+	var latest resource.RemoteVersion
+	sel := widget.NewSelect(nil, nil)
+
+	// listVersions fills sel with the versions of channel, newest first. The installed version is kept so the instance
+	// can stay where it is.
+	listVersions := func(channel string) {
+		byOption = make(map[string]resource.RemoteVersion, len(versions))
+		latest = resource.RemoteVersion{}
+		var options []string
+		installed := ""
+		for i := len(versions) - 1; i >= 0; i-- {
+			v := versions[i]
+			inChannel := resource.ChannelIncludes(channel, v.Channel)
+			if !inChannel && !v.Installed {
+				continue
+			}
+			label := v.ID
+			if v.Timestamp > 0 {
+				label = fmt.Sprintf("%s (%s)", label, time.Unix(v.Timestamp, 0).Format(time.DateTime))
+			}
+			if v.Channel != "" && v.Channel != resource.ChannelStable {
+				label = fmt.Sprintf("%s [%s]", label, i18n.T("channel_"+v.Channel))
+			}
+			if inChannel && latest.ID == "" {
+				latest = v
+				label = i18n.T("version_latest", label)
+			}
+			if v.Installed {
+				label = i18n.T("version_installed", label)
+				installed = label
+			}
+			options = append(options, label)
+			byOption[label] = v
+		}
+		sel.SetOptions(options)
+		switch {
+		case channel == inst.Upstream.ChannelName() && installed != "":
+			sel.SetSelected(installed)
+		case len(options) > 0:
+			sel.SetSelected(options[0])
+		}
+	}
+
+	channelOptions := make([]string, len(resource.Channels))
+	channelByOption := make(map[string]string, len(resource.Channels))
+	for i, c := range resource.Channels {
+		channelOptions[i] = i18n.T("channel_" + c)
+		channelByOption[channelOptions[i]] = c
+	}
+	channelSel := widget.NewSelect(channelOptions, func(option string) {
+		listVersions(channelByOption[option])
+	})
+	channelSel.SetSelected(i18n.T("channel_" + inst.Upstream.ChannelName()))
+
+	pin := widget.NewCheck(i18n.T("pin_version_check"), nil)
+	pin.SetChecked(inst.Upstream.Pinned)
+	hint := widget.NewLabel(i18n.T("pin_version_hint"))
+	hint.Wrapping = fyne.TextWrapWord
+
+	items := []*widget.FormItem{
+		widget.NewFormItem(i18n.T("channel_select_label"), channelSel),
+		widget.NewFormItem(i18n.T("version_select_label"), sel),
+		widget.NewFormItem("", pin),
+		widget.NewFormItem("", hint),
+	}
+
+	d := dialog.NewForm(i18n.T("select_version_title"), i18n.T("apply"), i18n.T("cancel"), items, func(ok bool) {
+		if !ok {
+			return
+		}
+		target, found := byOption[sel.Selected]
+		if !found {
+			return
+		}
+		channel, pinned := channelByOption[channelSel.Selected], pin.Checked
+		updateAvailable := !pinned && latest.ID != "" && target.ID != latest.ID
+		apply := func() {
+			if channel != inst.Upstream.ChannelName() {
+				if err := ui.instances.SetChannel(inst.UID, channel); err != nil {
+					dialog.ShowError(err, ui.window)
+					return
+				}
+			}
+			if pinned != inst.Upstream.Pinned {
+				if err := ui.instances.SetPinned(inst.UID, pinned); err != nil {
+					dialog.ShowError(err, ui.window)
+					return
+				}
+			}
+			if target.Installed {
+				ui.instanceUpdateAvailable[inst.UID] = updateAvailable
+				ui.showMainView()
+				return
+			}
+			ui.runInstanceUpdate(inst.UID, updateAvailable, func(ctx context.Context) error {
+				return ui.instances.UpdateInstanceTo(ctx, inst.UID, target.ID)
+			})
+		}
+		if target.Installed {
+			apply()
+			return
+		}
+		ui.confirmRemoteUpdate(inst.UID, target.ID, apply)
+	}, ui.window)
+
+	d.Resize(fyne.NewSize(500, 300))
+	d.Show()
+}
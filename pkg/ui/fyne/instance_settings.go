@@ -0,0 +1,177 @@
+package fyne
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ikafly144/sabalauncher/v2/pkg/i18n"
+	"github.com/ikafly144/sabalauncher/v2/pkg/resource"
+)
+
+// showInstanceSettingsDialog lets the user edit the launch settings of an instance.
+func (ui *FyneUI) showInstanceSettingsDialog(inst *resource.Instance) {
+	current := resource.LaunchSettings{}
+	if inst.LaunchSettings != nil {
+		current = *inst.LaunchSettings
+	}
+
+	jvmArgsEntry := widget.NewEntry()
+	jvmArgsEntry.SetPlaceHolder("-XX:+AlwaysPreTouch")
+	jvmArgsEntry.SetText(strings.Join(current.JVMArgs, " "))
+
+	gcOptions := make([]string, len(resource.GCPresets))
+	for i, p := range resource.GCPresets {
+		gcOptions[i] = gcPresetLabel(p)
+	}
+	gcSelect := widget.NewSelect(gcOptions, nil)
+	gcSelect.SetSelected(gcPresetLabel(current.GCPreset))
+
+	javaEntry := widget.NewEntry()
+	javaEntry.SetPlaceHolder(i18n.T("instance_settings_java_auto"))
+	javaEntry.SetText(current.JavaPath)
+
+	widthEntry := widget.NewEntry()
+	widthEntry.SetPlaceHolder(resource.DefaultResolutionWidth)
+	heightEntry := widget.NewEntry()
+	heightEntry.SetPlaceHolder(resource.DefaultResolutionHeight)
+	if current.Width > 0 {
+		widthEntry.SetText(strconv.Itoa(current.Width))
+	}
+	if current.Height > 0 {
+		heightEntry.SetText(strconv.Itoa(current.Height))
+	}
+	fullscreenCheck := widget.NewCheck(i18n.T("instance_settings_fullscreen"), nil)
+	fullscreenCheck.SetChecked(current.Fullscreen)
+
+	envEntry := widget.NewMultiLineEntry()
+	envEntry.SetPlaceHolder("KEY=VALUE")
+	envEntry.SetText(formatEnvLines(current.Env))
+
+	preLaunchEntry := widget.NewEntry()
+	preLaunchEntry.SetText(current.PreLaunchCommand)
+	postExitEntry := widget.NewEntry()
+	postExitEntry.SetText(current.PostExitCommand)
+
+	d := dialog.NewForm(i18n.T("instance_settings_title"), i18n.T("save"), i18n.T("cancel"), []*widget.FormItem{
+		widget.NewFormItem(i18n.T("instance_settings_jvm_args"), jvmArgsEntry),
+		widget.NewFormItem(i18n.T("instance_settings_gc"), gcSelect),
+		widget.NewFormItem(i18n.T("instance_settings_java"), javaEntry),
+		widget.NewFormItem(i18n.T("instance_settings_width"), widthEntry),
+		widget.NewFormItem(i18n.T("instance_settings_height"), heightEntry),
+		widget.NewFormItem("", fullscreenCheck),
+		widget.NewFormItem(i18n.T("instance_settings_env"), envEntry),
+		widget.NewFormItem(i18n.T("instance_settings_pre_launch"), preLaunchEntry),
+		widget.NewFormItem(i18n.T("instance_settings_post_exit"), postExitEntry),
+	}, func(ok bool) {
+		if !ok {
+			return
+		}
+		settings := resource.LaunchSettings{
+			JVMArgs:          strings.Fields(jvmArgsEntry.Text),
+			JavaPath:         strings.TrimSpace(javaEntry.Text),
+			Fullscreen:       fullscreenCheck.Checked,
+			PreLaunchCommand: strings.TrimSpace(preLaunchEntry.Text),
+			PostExitCommand:  strings.TrimSpace(postExitEntry.Text),
+		}
+		if i := slices.Index(gcOptions, gcSelect.Selected); i >= 0 {
+			settings.GCPreset = resource.GCPresets[i]
+		}
+		var err error
+		if settings.Width, err = parseDimension(widthEntry.Text); err != nil {
+			dialog.ShowError(err, ui.window)
+			return
+		}
+		if settings.Height, err = parseDimension(heightEntry.Text); err != nil {
+			dialog.ShowError(err, ui.window)
+			return
+		}
+		if settings.Env, err = parseEnvLines(envEntry.Text); err != nil {
+			dialog.ShowError(err, ui.window)
+			return
+		}
+		if err := settings.Validate(); err != nil {
+			dialog.ShowError(err, ui.window)
+			return
+		}
+
+		if settings.IsZero() {
+			inst.LaunchSettings = nil
+		} else {
+			inst.LaunchSettings = &settings
+		}
+		if err := ui.instances.SaveInstance(inst); err != nil {
+			dialog.ShowError(err, ui.window)
+		}
+	}, ui.window)
+	d.Resize(d.MinSize().AddWidthHeight(200, 0))
+	d.Show()
+}
+
+func gcPresetLabel(p resource.GCPreset) string {
+	switch p {
+	case resource.GCPresetDefault:
+		return i18n.T("instance_settings_gc_default")
+	case resource.GCPresetNone:
+		return i18n.T("instance_settings_gc_none")
+	case resource.GCPresetG1:
+		return "G1"
+	case resource.GCPresetZGC:
+		return "ZGC"
+	case resource.GCPresetShenandoah:
+		return "Shenandoah"
+	case resource.GCPresetParallel:
+		return "Parallel"
+	default:
+		return string(p)
+	}
+}
+
+// parseDimension parses a window width or height. An empty string means the default size.
+func parseDimension(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("invalid window size: %s", s)
+	}
+	return v, nil
+}
+
+// parseEnvLines parses KEY=VALUE lines. Empty lines are ignored.
+func parseEnvLines(s string) (map[string]string, error) {
+	var env map[string]string
+	for line := range strings.Lines(s) {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(line, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			return nil, fmt.Errorf("invalid environment variable: %s", line)
+		}
+		if env == nil {
+			env = make(map[string]string)
+		}
+		env[strings.TrimSpace(k)] = v
+	}
+	return env, nil
+}
+
+func formatEnvLines(env map[string]string) string {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k + "=" + env[k] + "\n")
+	}
+	return b.String()
+}